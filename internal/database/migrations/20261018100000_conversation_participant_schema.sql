@@ -0,0 +1,80 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE CONVERSATION_TYPE AS ENUM ('DIRECT', 'GROUP');
+
+CREATE TABLE "public"."conversations" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "type" CONVERSATION_TYPE NOT NULL,
+    "name" VARCHAR,
+    -- Sorted pair of personnel ids, keeps a single direct conversation per pair
+    "direct_key" VARCHAR UNIQUE,
+    "created_by" UUID REFERENCES "public"."personnel"(id) ON DELETE SET NULL,
+    "created_at" TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    "deleted_at" TIMESTAMPTZ
+);
+
+CREATE TABLE "public"."conversation_participants" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "conversation_id" UUID NOT NULL REFERENCES "public"."conversations"(id) ON DELETE CASCADE,
+    "personnel_id" UUID NOT NULL REFERENCES "public"."personnel"(id) ON DELETE CASCADE,
+    -- Messages created after this marker are unread for the participant
+    "last_read_at" TIMESTAMPTZ,
+    "created_at" TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    "deleted_at" TIMESTAMPTZ,
+    UNIQUE ("conversation_id", "personnel_id")
+);
+
+CREATE INDEX "conversation_participants_personnel_id_idx" ON "public"."conversation_participants" ("personnel_id");
+
+ALTER TABLE "public"."messages" ADD COLUMN "conversation_id" UUID REFERENCES "public"."conversations"(id) ON DELETE CASCADE;
+
+-- Move the existing sender/receiver pairs into direct conversations
+INSERT INTO "public"."conversations" ("type", "direct_key")
+SELECT DISTINCT 'DIRECT', LEAST(sender_id, receiver_id)::text || ':' || GREATEST(sender_id, receiver_id)::text
+FROM "public"."messages"
+WHERE sender_id IS NOT NULL AND receiver_id IS NOT NULL;
+
+INSERT INTO "public"."conversation_participants" ("conversation_id", "personnel_id")
+SELECT id, split_part(direct_key, ':', 1)::uuid FROM "public"."conversations" WHERE direct_key IS NOT NULL
+UNION
+SELECT id, split_part(direct_key, ':', 2)::uuid FROM "public"."conversations" WHERE direct_key IS NOT NULL;
+
+UPDATE "public"."messages" m SET conversation_id = c.id
+FROM "public"."conversations" c
+WHERE c.direct_key = LEAST(m.sender_id, m.receiver_id)::text || ':' || GREATEST(m.sender_id, m.receiver_id)::text;
+
+-- Messages without a receiver cannot be attached to any conversation
+DELETE FROM "public"."messages" WHERE conversation_id IS NULL;
+
+ALTER TABLE "public"."messages" ALTER COLUMN "conversation_id" SET NOT NULL;
+
+ALTER TABLE "public"."messages" DROP COLUMN "receiver_id";
+
+CREATE INDEX "messages_conversation_id_created_at_idx" ON "public"."messages" ("conversation_id", "created_at");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "public"."messages" ADD COLUMN "receiver_id" UUID REFERENCES "public"."personnel"(id) ON DELETE CASCADE;
+
+-- Only direct conversations map back to a single receiver
+UPDATE "public"."messages" m SET receiver_id = p.personnel_id
+FROM "public"."conversation_participants" p
+JOIN "public"."conversations" c ON c.id = p.conversation_id AND c.type = 'DIRECT'
+WHERE p.conversation_id = m.conversation_id AND p.personnel_id <> m.sender_id;
+
+DELETE FROM "public"."messages" WHERE receiver_id IS NULL;
+
+DROP INDEX IF EXISTS "public"."messages_conversation_id_created_at_idx";
+
+ALTER TABLE "public"."messages" DROP COLUMN "conversation_id";
+
+DROP TABLE IF EXISTS "public"."conversation_participants";
+
+DROP TABLE IF EXISTS "public"."conversations";
+
+DROP TYPE IF EXISTS CONVERSATION_TYPE;
+
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE PARTICIPANT_ROLE AS ENUM ('OWNER', 'ADMIN', 'MEMBER');
+
+ALTER TABLE "public"."conversation_participants" ADD COLUMN "role" PARTICIPANT_ROLE NOT NULL DEFAULT 'MEMBER';
+-- A muted participant cannot send messages until this time
+ALTER TABLE "public"."conversation_participants" ADD COLUMN "muted_until" TIMESTAMPTZ;
+
+-- The creators of the existing group conversations own them
+UPDATE "public"."conversation_participants" p SET role = 'OWNER'
+FROM "public"."conversations" c
+WHERE c.id = p.conversation_id AND c.type = 'GROUP' AND c.created_by = p.personnel_id;
+
+CREATE TABLE "public"."conversation_invites" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "conversation_id" UUID NOT NULL REFERENCES "public"."conversations"(id) ON DELETE CASCADE,
+    "token" VARCHAR NOT NULL UNIQUE,
+    "created_by" UUID NOT NULL REFERENCES "public"."personnel"(id) ON DELETE CASCADE,
+    "expires_at" TIMESTAMPTZ NOT NULL,
+    -- Unlimited when NULL
+    "max_uses" INTEGER,
+    "uses" INTEGER NOT NULL DEFAULT 0,
+    "created_at" TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    "deleted_at" TIMESTAMPTZ
+);
+
+CREATE INDEX "conversation_invites_conversation_id_idx" ON "public"."conversation_invites" ("conversation_id");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."conversation_invites";
+
+ALTER TABLE "public"."conversation_participants" DROP COLUMN "muted_until";
+
+ALTER TABLE "public"."conversation_participants" DROP COLUMN "role";
+
+DROP TYPE IF EXISTS PARTICIPANT_ROLE;
+
+-- +goose StatementEnd
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "public"."message_status" ADD COLUMN "recipient_id" UUID REFERENCES "public"."personnel"(id) ON DELETE CASCADE;
+
+-- Expand the single status of each message into one per recipient, what they already read is marked as Read
+INSERT INTO "public"."message_status" ("message_id", "recipient_id", "status", "updated_at")
+SELECT m.id, p.personnel_id,
+    CASE WHEN p.last_read_at >= m.created_at THEN 'Read'::status ELSE COALESCE(s.status, 'Sent') END,
+    COALESCE(s.updated_at, m.created_at)
+FROM "public"."messages" m
+JOIN "public"."conversation_participants" p ON p.conversation_id = m.conversation_id AND p.personnel_id <> m.sender_id AND p.deleted_at IS NULL
+LEFT JOIN LATERAL (SELECT status, updated_at FROM "public"."message_status" WHERE message_id = m.id AND recipient_id IS NULL LIMIT 1) s ON TRUE;
+
+DELETE FROM "public"."message_status" WHERE recipient_id IS NULL OR message_id IS NULL;
+
+ALTER TABLE "public"."message_status" ALTER COLUMN "message_id" SET NOT NULL;
+
+ALTER TABLE "public"."message_status" ALTER COLUMN "recipient_id" SET NOT NULL;
+
+ALTER TABLE "public"."message_status" ALTER COLUMN "status" SET NOT NULL;
+
+ALTER TABLE "public"."message_status" ADD CONSTRAINT "message_status_message_id_recipient_id_key" UNIQUE ("message_id", "recipient_id");
+
+CREATE INDEX "message_status_recipient_id_status_idx" ON "public"."message_status" ("recipient_id", "status");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS "public"."message_status_recipient_id_status_idx";
+
+ALTER TABLE "public"."message_status" DROP CONSTRAINT IF EXISTS "message_status_message_id_recipient_id_key";
+
+-- Keep the least advanced status of each message
+DELETE FROM "public"."message_status" a
+USING "public"."message_status" b
+WHERE a.message_id = b.message_id AND (a.status > b.status OR (a.status = b.status AND a.id > b.id));
+
+ALTER TABLE "public"."message_status" ALTER COLUMN "status" DROP NOT NULL;
+
+ALTER TABLE "public"."message_status" ALTER COLUMN "message_id" DROP NOT NULL;
+
+ALTER TABLE "public"."message_status" DROP COLUMN "recipient_id";
+
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Keyset pagination walks (created_at, id), the id breaks ties between messages created in the same instant
+DROP INDEX IF EXISTS "public"."messages_conversation_id_created_at_idx";
+
+CREATE INDEX "messages_conversation_id_created_at_id_idx" ON "public"."messages" ("conversation_id", "created_at", "id");
+
+CREATE INDEX "messages_sender_id_created_at_id_idx" ON "public"."messages" ("sender_id", "created_at", "id");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS "public"."messages_sender_id_created_at_id_idx";
+
+DROP INDEX IF EXISTS "public"."messages_conversation_id_created_at_id_idx";
+
+CREATE INDEX "messages_conversation_id_created_at_idx" ON "public"."messages" ("conversation_id", "created_at");
+
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "public"."messages" ADD COLUMN "edited_at" TIMESTAMPTZ;
+
+CREATE TABLE "public"."message_edits" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "message_id" UUID NOT NULL REFERENCES "public"."messages"(id) ON DELETE CASCADE,
+    -- Content of the message before the edit
+    "content" TEXT NOT NULL,
+    "edited_by" UUID NOT NULL REFERENCES "public"."personnel"(id) ON DELETE CASCADE,
+    "created_at" TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "message_edits_message_id_created_at_idx" ON "public"."message_edits" ("message_id", "created_at");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."message_edits";
+
+ALTER TABLE "public"."messages" DROP COLUMN "edited_at";
+
+-- +goose StatementEnd
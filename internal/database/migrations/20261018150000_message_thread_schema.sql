@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The quoted message, and the first message of the thread the reply belongs to
+ALTER TABLE "public"."messages" ADD COLUMN "reply_to_id" UUID REFERENCES "public"."messages"(id) ON DELETE SET NULL;
+
+ALTER TABLE "public"."messages" ADD COLUMN "thread_root_id" UUID REFERENCES "public"."messages"(id) ON DELETE SET NULL;
+
+-- Thread summary kept on the root message
+ALTER TABLE "public"."messages" ADD COLUMN "reply_count" INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE "public"."messages" ADD COLUMN "last_reply_at" TIMESTAMPTZ;
+
+CREATE INDEX "messages_thread_root_id_created_at_id_idx" ON "public"."messages" ("thread_root_id", "created_at", "id") WHERE thread_root_id IS NOT NULL;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS "public"."messages_thread_root_id_created_at_id_idx";
+
+ALTER TABLE "public"."messages" DROP COLUMN "last_reply_at";
+
+ALTER TABLE "public"."messages" DROP COLUMN "reply_count";
+
+ALTER TABLE "public"."messages" DROP COLUMN "thread_root_id";
+
+ALTER TABLE "public"."messages" DROP COLUMN "reply_to_id";
+
+-- +goose StatementEnd
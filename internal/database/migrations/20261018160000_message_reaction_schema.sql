@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE "public"."message_reactions" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "message_id" UUID NOT NULL REFERENCES "public"."messages"(id) ON DELETE CASCADE,
+    "personnel_id" UUID NOT NULL REFERENCES "public"."personnel"(id) ON DELETE CASCADE,
+    "emoji" VARCHAR(32) NOT NULL,
+    "created_at" TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE ("message_id", "personnel_id", "emoji")
+);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."message_reactions";
+
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE "public"."attachments" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "conversation_id" UUID NOT NULL REFERENCES "public"."conversations"(id) ON DELETE CASCADE,
+    -- Unset until the attachment is sent with a message
+    "message_id" UUID REFERENCES "public"."messages"(id) ON DELETE CASCADE,
+    "uploader_id" UUID NOT NULL REFERENCES "public"."personnel"(id) ON DELETE CASCADE,
+    "file_name" VARCHAR NOT NULL,
+    "content_type" VARCHAR NOT NULL,
+    "size" BIGINT NOT NULL,
+    -- Key of the content in the blob store
+    "storage_key" VARCHAR NOT NULL UNIQUE,
+    "created_at" TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    "deleted_at" TIMESTAMPTZ
+);
+
+CREATE INDEX "attachments_message_id_idx" ON "public"."attachments" ("message_id");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."attachments";
+
+-- +goose StatementEnd
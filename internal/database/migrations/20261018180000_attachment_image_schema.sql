@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Set once an image attachment has been processed in the background
+ALTER TABLE "public"."attachments" ADD COLUMN "width" INTEGER;
+ALTER TABLE "public"."attachments" ADD COLUMN "height" INTEGER;
+ALTER TABLE "public"."attachments" ADD COLUMN "placeholder" VARCHAR;
+ALTER TABLE "public"."attachments" ADD COLUMN "thumbnails" JSONB NOT NULL DEFAULT '[]';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "public"."attachments" DROP COLUMN "thumbnails";
+ALTER TABLE "public"."attachments" DROP COLUMN "placeholder";
+ALTER TABLE "public"."attachments" DROP COLUMN "height";
+ALTER TABLE "public"."attachments" DROP COLUMN "width";
+
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Prefix of the keys the sizes of the avatar are stored under in the blob store. Avatars set before uploads keep their
+-- external URL without a key until they are replaced or removed.
+ALTER TABLE "public"."personnel" ADD COLUMN "avatar_key" VARCHAR;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "public"."personnel" DROP COLUMN "avatar_key";
+
+-- +goose StatementEnd
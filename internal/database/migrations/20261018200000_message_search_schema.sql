@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Kept up to date by Postgres, english stemming lets "deciding" find "decided"
+ALTER TABLE "public"."messages" ADD COLUMN "search_vector" TSVECTOR GENERATED ALWAYS AS (to_tsvector('english', content)) STORED;
+
+CREATE INDEX "messages_search_vector_idx" ON "public"."messages" USING GIN ("search_vector");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS "messages_search_vector_idx";
+
+ALTER TABLE "public"."messages" DROP COLUMN "search_vector";
+
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "public"."personnel" ADD COLUMN "last_seen_at" TIMESTAMPTZ;
+
+CREATE TYPE PRESENCE_STATUS AS ENUM ('ONLINE', 'AWAY');
+
+-- One row per live realtime connection, whatever instance holds it. The rows are short lived so the table skips the
+-- write ahead log, it is emptied after a crash and clients reconnect anyway.
+CREATE UNLOGGED TABLE "public"."presence_connections" (
+    "id" UUID PRIMARY KEY,
+    "personnel_id" UUID NOT NULL REFERENCES "public"."personnel"(id) ON DELETE CASCADE,
+    "status" PRESENCE_STATUS NOT NULL DEFAULT 'ONLINE',
+    "connected_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- Connections left without a heartbeat belong to an instance that went away
+    "heartbeat_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "presence_connections_personnel_id_idx" ON "public"."presence_connections" ("personnel_id");
+CREATE INDEX "presence_connections_heartbeat_at_idx" ON "public"."presence_connections" ("heartbeat_at");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."presence_connections";
+
+DROP TYPE IF EXISTS PRESENCE_STATUS;
+
+ALTER TABLE "public"."personnel" DROP COLUMN "last_seen_at";
+
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE "public"."refresh_tokens" (
+    "id" UUID NOT NULL DEFAULT gen_random_uuid(),
+    "user_id" UUID NOT NULL REFERENCES "public"."users"(id) ON DELETE CASCADE,
+    -- Tokens rotated from the same login share a family, which is revoked as a whole when a used token comes back
+    "family_id" UUID NOT NULL,
+    "token_hash" VARCHAR NOT NULL UNIQUE,
+    "access_token_id" UUID NOT NULL,
+    "access_expires_at" TIMESTAMPTZ NOT NULL,
+    "expires_at" TIMESTAMPTZ NOT NULL,
+    "used_at" TIMESTAMPTZ,
+    "revoked_at" TIMESTAMPTZ,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "refresh_tokens_family_id_idx" ON "public"."refresh_tokens" ("family_id");
+CREATE INDEX "refresh_tokens_access_token_id_idx" ON "public"."refresh_tokens" ("access_token_id");
+CREATE INDEX "refresh_tokens_expires_at_idx" ON "public"."refresh_tokens" ("expires_at");
+
+-- Access tokens revoked before they expire, looked up by their jti on every authenticated request
+CREATE TABLE "public"."revoked_tokens" (
+    "id" UUID NOT NULL,
+    "expires_at" TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "revoked_tokens_expires_at_idx" ON "public"."revoked_tokens" ("expires_at");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."revoked_tokens";
+
+DROP TABLE IF EXISTS "public"."refresh_tokens";
+
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE "public"."sessions" (
+    "id" UUID NOT NULL DEFAULT gen_random_uuid(),
+    "user_id" UUID NOT NULL REFERENCES "public"."users"(id) ON DELETE CASCADE,
+    "user_agent" VARCHAR NOT NULL DEFAULT '',
+    "ip_address" VARCHAR NOT NULL DEFAULT '',
+    "device_name" VARCHAR NOT NULL DEFAULT '',
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "last_used_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "expires_at" TIMESTAMPTZ NOT NULL,
+    "revoked_at" TIMESTAMPTZ,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "sessions_user_id_idx" ON "public"."sessions" ("user_id");
+CREATE INDEX "sessions_expires_at_idx" ON "public"."sessions" ("expires_at");
+
+-- Every family of refresh tokens becomes the session of the login that started it
+INSERT INTO "public"."sessions" ("id", "user_id", "created_at", "last_used_at", "expires_at", "revoked_at")
+SELECT "family_id", "user_id", MIN("created_at"), MAX("created_at"), MAX("expires_at"),
+    CASE WHEN BOOL_AND("revoked_at" IS NOT NULL) THEN MAX("revoked_at") END
+FROM "public"."refresh_tokens"
+GROUP BY "family_id", "user_id";
+
+ALTER TABLE "public"."refresh_tokens" RENAME COLUMN "family_id" TO "session_id";
+ALTER INDEX "public"."refresh_tokens_family_id_idx" RENAME TO "refresh_tokens_session_id_idx";
+ALTER TABLE "public"."refresh_tokens" ADD CONSTRAINT "refresh_tokens_session_id_fkey"
+    FOREIGN KEY ("session_id") REFERENCES "public"."sessions"(id) ON DELETE CASCADE;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "public"."refresh_tokens" DROP CONSTRAINT "refresh_tokens_session_id_fkey";
+ALTER INDEX "public"."refresh_tokens_session_id_idx" RENAME TO "refresh_tokens_family_id_idx";
+ALTER TABLE "public"."refresh_tokens" RENAME COLUMN "session_id" TO "family_id";
+
+DROP TABLE IF EXISTS "public"."sessions";
+
+-- +goose StatementEnd
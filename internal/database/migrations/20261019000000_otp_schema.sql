@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "public"."users" ADD COLUMN "mobile_verified_at" TIMESTAMPTZ;
+
+-- At most one code per number, the user may not exist so that requests do not tell registered numbers apart
+CREATE TABLE "public"."otp_codes" (
+    "id" UUID NOT NULL DEFAULT gen_random_uuid(),
+    "user_name" VARCHAR NOT NULL UNIQUE,
+    "code_hash" VARCHAR NOT NULL,
+    "attempts" INTEGER NOT NULL DEFAULT 0,
+    "expires_at" TIMESTAMPTZ NOT NULL,
+    "sent_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "otp_codes_expires_at_idx" ON "public"."otp_codes" ("expires_at");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."otp_codes";
+
+ALTER TABLE "public"."users" DROP COLUMN "mobile_verified_at";
+
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- At most one token per user, only its hash is stored
+CREATE TABLE "public"."password_reset_tokens" (
+    "id" UUID NOT NULL DEFAULT gen_random_uuid(),
+    "user_id" UUID NOT NULL UNIQUE REFERENCES "public"."users" ("id") ON DELETE CASCADE,
+    "token_hash" VARCHAR NOT NULL UNIQUE,
+    "expires_at" TIMESTAMPTZ NOT NULL,
+    "used_at" TIMESTAMPTZ,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "password_reset_tokens_expires_at_idx" ON "public"."password_reset_tokens" ("expires_at");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."password_reset_tokens";
+
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The latest passwords of every user, only as many as new passwords are checked against are kept
+CREATE TABLE "public"."password_history" (
+    "id" UUID NOT NULL DEFAULT gen_random_uuid(),
+    "user_id" UUID NOT NULL REFERENCES "public"."users" ("id") ON DELETE CASCADE,
+    "password_hash" VARCHAR NOT NULL,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "password_history_user_id_created_at_idx" ON "public"."password_history" ("user_id", "created_at" DESC);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."password_history";
+
+-- +goose StatementEnd
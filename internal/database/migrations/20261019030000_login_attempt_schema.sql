@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Failed logins of user names, whether or not they exist, and of IP addresses
+CREATE TABLE "public"."login_attempts" (
+    "scope" VARCHAR NOT NULL,
+    "subject" VARCHAR NOT NULL,
+    "failures" INTEGER NOT NULL DEFAULT 0,
+    "last_failed_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "locked_until" TIMESTAMPTZ,
+    PRIMARY KEY ("scope", "subject")
+);
+
+CREATE INDEX "login_attempts_last_failed_at_idx" ON "public"."login_attempts" ("last_failed_at");
+
+CREATE TABLE "public"."audit_events" (
+    "id" UUID NOT NULL DEFAULT gen_random_uuid(),
+    "type" VARCHAR NOT NULL,
+    "user_id" UUID REFERENCES "public"."users"(id) ON DELETE SET NULL,
+    "user_name" VARCHAR NOT NULL DEFAULT '',
+    "ip_address" VARCHAR NOT NULL DEFAULT '',
+    "details" JSONB NOT NULL DEFAULT '{}',
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "audit_events_type_created_at_idx" ON "public"."audit_events" ("type", "created_at");
+CREATE INDEX "audit_events_user_id_idx" ON "public"."audit_events" ("user_id");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."audit_events";
+
+DROP TABLE IF EXISTS "public"."login_attempts";
+
+-- +goose StatementEnd
@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE "public"."totp_authenticators" (
+    "user_id" UUID NOT NULL REFERENCES "public"."users"(id) ON DELETE CASCADE,
+    "secret" VARCHAR NOT NULL,
+    "confirmed_at" TIMESTAMPTZ,
+    "last_used_step" BIGINT NOT NULL DEFAULT 0,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("user_id")
+);
+
+CREATE TABLE "public"."recovery_codes" (
+    "id" UUID NOT NULL DEFAULT gen_random_uuid(),
+    "user_id" UUID NOT NULL REFERENCES "public"."users"(id) ON DELETE CASCADE,
+    "code_hash" VARCHAR NOT NULL,
+    "used_at" TIMESTAMPTZ,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "recovery_codes_user_id_idx" ON "public"."recovery_codes" ("user_id");
+
+CREATE TABLE "public"."mfa_challenges" (
+    "id" UUID NOT NULL DEFAULT gen_random_uuid(),
+    "user_id" UUID NOT NULL REFERENCES "public"."users"(id) ON DELETE CASCADE,
+    "token_hash" VARCHAR NOT NULL UNIQUE,
+    "device_name" VARCHAR NOT NULL DEFAULT '',
+    "user_agent" VARCHAR NOT NULL DEFAULT '',
+    "ip_address" VARCHAR NOT NULL DEFAULT '',
+    "attempts" INTEGER NOT NULL DEFAULT 0,
+    "expires_at" TIMESTAMPTZ NOT NULL,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "mfa_challenges_expires_at_idx" ON "public"."mfa_challenges" ("expires_at");
+
+-- Whether the login of a session gave a second factor, tokens of the session carry it to refreshes
+ALTER TABLE "public"."sessions" ADD COLUMN "mfa" BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "public"."sessions" DROP COLUMN "mfa";
+
+DROP TABLE IF EXISTS "public"."mfa_challenges";
+
+DROP TABLE IF EXISTS "public"."recovery_codes";
+
+DROP TABLE IF EXISTS "public"."totp_authenticators";
+
+-- +goose StatementEnd
@@ -7,11 +7,19 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/chatApp/internal/database"
+	"github.com/chatApp/internal/domain"
 	"github.com/chatApp/internal/http/api"
 	"github.com/chatApp/internal/http/controller"
+	"github.com/chatApp/internal/pkg/blobstore"
 	"github.com/chatApp/internal/pkg/config"
+	"github.com/chatApp/internal/pkg/notifier"
+	"github.com/chatApp/internal/pkg/pubsub"
+	"github.com/chatApp/internal/pkg/realtime"
 	"github.com/chatApp/internal/pkg/security"
+	"github.com/chatApp/internal/pkg/sms"
+	"github.com/chatApp/internal/pkg/totp"
 	"github.com/chatApp/internal/pkg/util"
+	"github.com/chatApp/internal/pkg/worker"
 	"github.com/chatApp/internal/repository"
 	"github.com/chatApp/internal/service"
 )
@@ -34,17 +42,51 @@ func NewChatAppApi(cfg config.ChatApiConfig, db *pgxpool.Pool) (*api.ChatApi, er
 	wire.Build(
 		util.NewAppUtil,
 		security.NewJwtSecurityManager,
+		security.NewPasswordPolicy,
+		totp.NewAuthenticator,
+
+		pubsub.NewPubSub,
+		blobstore.NewBlobStore,
+		sms.NewSMSSender,
+		notifier.NewNotifier,
+		worker.NewPool,
+		realtime.NewHub,
+		wire.Bind(new(domain.EventPublisher), new(*realtime.Hub)),
+		wire.Bind(new(domain.SessionCloser), new(*realtime.Hub)),
 
 		repository.NewTransactioner,
 
 		repository.NewUserRepository,
 		repository.NewPersonnelRepository,
+		repository.NewPgxMessageRepository,
+		repository.NewConversationRepository,
+		repository.NewAttachmentRepository,
+		repository.NewPresenceRepository,
+		repository.NewTokenRepository,
+		repository.NewSessionRepository,
+		repository.NewOTPRepository,
+		repository.NewPasswordResetRepository,
+		repository.NewPasswordHistoryRepository,
+		repository.NewLoginAttemptRepository,
+		repository.NewAuditRepository,
+		repository.NewMFARepository,
 
 		service.NewUserService,
 		service.NewPersonnelService,
+		service.NewMessageService,
+		service.NewConversationService,
+		service.NewAttachmentService,
+		service.NewPresenceService,
+		service.NewTypingService,
 
 		controller.NewUserController,
 		controller.NewPersonnelController,
+		controller.NewMessageController,
+		controller.NewConversationController,
+		controller.NewRealtimeController,
+		controller.NewAttachmentController,
+		controller.NewPresenceController,
+		controller.NewKeyController,
 
 		api.NewChatApi,
 	)
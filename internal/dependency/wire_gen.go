@@ -10,9 +10,16 @@ import (
 	"github.com/chatApp/internal/database"
 	"github.com/chatApp/internal/http/api"
 	"github.com/chatApp/internal/http/controller"
+	"github.com/chatApp/internal/pkg/blobstore"
 	"github.com/chatApp/internal/pkg/config"
+	"github.com/chatApp/internal/pkg/notifier"
+	"github.com/chatApp/internal/pkg/pubsub"
+	"github.com/chatApp/internal/pkg/realtime"
 	"github.com/chatApp/internal/pkg/security"
+	"github.com/chatApp/internal/pkg/sms"
+	"github.com/chatApp/internal/pkg/totp"
 	"github.com/chatApp/internal/pkg/util"
+	"github.com/chatApp/internal/pkg/worker"
 	"github.com/chatApp/internal/repository"
 	"github.com/chatApp/internal/service"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -34,15 +41,61 @@ func NewDatabaseConfig(cfg config.ChatApiConfig) (*pgxpool.Pool, error) {
 }
 
 func NewChatAppApi(cfg config.ChatApiConfig, db *pgxpool.Pool) (*api.ChatApi, error) {
+	pubSub := pubsub.NewPubSub(cfg, db)
+	hub := realtime.NewHub(pubSub)
+	blobStore, err := blobstore.NewBlobStore(cfg)
+	if err != nil {
+		return nil, err
+	}
 	personnelRepository := repository.NewPersonnelRepository(db)
-	personnelService := service.NewPersonnelService(personnelRepository)
+	personnelService := service.NewPersonnelService(blobStore, cfg, personnelRepository)
 	personnelController := controller.NewPersonnelController(personnelService)
-	appUtil := util.NewAppUtil()
-	manager := security.NewJwtSecurityManager(cfg)
+	auditRepository := repository.NewAuditRepository(db)
+	appUtil := util.NewAppUtil(cfg)
+	loginAttemptRepository := repository.NewLoginAttemptRepository(db)
+	mfaRepository := repository.NewMFARepository(db)
+	smsSender, err := sms.NewSMSSender(cfg)
+	if err != nil {
+		return nil, err
+	}
+	notifierNotifier, err := notifier.NewNotifier(cfg, smsSender)
+	if err != nil {
+		return nil, err
+	}
+	otpRepository := repository.NewOTPRepository(db)
+	passwordHistoryRepository := repository.NewPasswordHistoryRepository(db)
+	passwordPolicy, err := security.NewPasswordPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+	passwordResetRepository := repository.NewPasswordResetRepository(db)
+	sessionRepository := repository.NewSessionRepository(db)
+	manager, err := security.NewJwtSecurityManager(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tokenRepository := repository.NewTokenRepository(db)
+	authenticator := totp.NewAuthenticator(cfg)
 	transactioner := repository.NewTransactioner(db)
 	userRepository := repository.NewUserRepository(db)
-	userService := service.NewUserService(appUtil, cfg, personnelRepository, manager, transactioner, userRepository)
+	userService := service.NewUserService(auditRepository, appUtil, cfg, loginAttemptRepository, mfaRepository, notifierNotifier, otpRepository, passwordHistoryRepository, passwordPolicy, personnelRepository, passwordResetRepository, hub, sessionRepository, manager, smsSender, tokenRepository, authenticator, transactioner, userRepository)
 	userController := controller.NewUserController(userService)
-	chatApi := api.NewChatApi(cfg, personnelController, userController)
+	attachmentRepository := repository.NewAttachmentRepository(db)
+	conversationRepository := repository.NewConversationRepository(db)
+	messageRepository := repository.NewPgxMessageRepository(db)
+	messageService := service.NewMessageService(attachmentRepository, cfg, conversationRepository, hub, messageRepository, transactioner)
+	messageController := controller.NewMessageController(messageService, personnelService)
+	conversationService := service.NewConversationService(appUtil, cfg, conversationRepository, hub, personnelRepository, transactioner)
+	conversationController := controller.NewConversationController(conversationService, personnelService)
+	presenceRepository := repository.NewPresenceRepository(db)
+	presenceService := service.NewPresenceService(conversationRepository, hub, presenceRepository)
+	typingService := service.NewTypingService(conversationRepository, hub)
+	realtimeController := controller.NewRealtimeController(hub, presenceService, personnelService, typingService)
+	pool := worker.NewPool(cfg)
+	attachmentService := service.NewAttachmentService(attachmentRepository, blobStore, cfg, conversationRepository, pool)
+	attachmentController := controller.NewAttachmentController(attachmentService, personnelService)
+	presenceController := controller.NewPresenceController(presenceService)
+	keyController := controller.NewKeyController(manager)
+	chatApi := api.NewChatApi(cfg, hub, personnelController, userController, messageController, conversationController, realtimeController, attachmentController, presenceController, keyController, manager, userService, pool)
 	return chatApi, nil
 }
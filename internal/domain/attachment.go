@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"context"
+	"io"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// Attachment defines the model for a file shared in a conversation
+	Attachment struct {
+		Base
+		ConversationID uuid.UUID `db:"conversation_id" json:"conversation_id" example:"12345678-1234-1234-1234-123456789012"`
+		// MessageID is set once the attachment is sent with a message
+		MessageID   *uuid.UUID `db:"message_id" json:"message_id,omitempty" example:"12345678-1234-1234-1234-123456789012"`
+		UploaderID  uuid.UUID  `db:"uploader_id" json:"uploader_id" example:"12345678-1234-1234-1234-123456789012"`
+		FileName    string     `db:"file_name" json:"file_name" example:"screenshot.png"`
+		ContentType string     `db:"content_type" json:"content_type" example:"image/png"`
+		Size        int64      `db:"size" json:"size" example:"48213"`
+		StorageKey  string     `db:"storage_key" json:"-"`
+		// Width, Height and Placeholder are set once an image has been processed, Width and Height are as displayed
+		Width  *int `db:"width" json:"width,omitempty" example:"1920"`
+		Height *int `db:"height" json:"height,omitempty" example:"1080"`
+		// Placeholder is a BlurHash of the image for clients to show while the thumbnail loads
+		Placeholder *string               `db:"placeholder" json:"placeholder,omitempty" example:"LEHV6nWB2yk8pyo0adR*.7kCMdnj"`
+		Thumbnails  []AttachmentThumbnail `db:"thumbnails" json:"thumbnails"`
+		BaseAudit
+	} // @name Attachment
+
+	// AttachmentThumbnail defines the model for a scaled down copy of an image attachment, it is downloaded from
+	// /attachments/{id}/thumbnails/{size}
+	AttachmentThumbnail struct {
+		// Size is the box in pixels the thumbnail fits in
+		Size        int    `json:"size" example:"480"`
+		Width       int    `json:"width" example:"480"`
+		Height      int    `json:"height" example:"270"`
+		ContentType string `json:"content_type" example:"image/jpeg"`
+	} // @name AttachmentThumbnail
+)
+
+type (
+	// UploadAttachmentInput defines the input for UploadAttachmentInput, it is read from a multipart form
+	UploadAttachmentInput struct {
+		ConversationID uuid.UUID
+		FileName       string
+		Size           int64
+		Content        io.Reader
+	}
+)
+
+type (
+	// AttachmentRepository defines the methods that any attachment repository should implement
+	AttachmentRepository interface {
+		// FindByID returns an attachment by its ID
+		FindByID(ctx context.Context, id uuid.UUID) (result Attachment, err error)
+		// FindByMessageIDs returns the attachments of the messages
+		FindByMessageIDs(ctx context.Context, messageIDs []uuid.UUID) (result []Attachment, err error)
+		// Create creates a new attachment
+		Create(ctx context.Context, entity *Attachment) (err error)
+		// LinkToMessage links the unsent attachments of the uploader in the conversation of the message to it and
+		// returns the number of attachments linked
+		LinkToMessage(ctx context.Context, message *Message, ids []uuid.UUID) (linked int64, err error)
+		// UpdateImage records the dimensions, placeholder and thumbnails of a processed image
+		UpdateImage(ctx context.Context, entity *Attachment) (err error)
+	} // @name AttachmentRepository
+
+	// AttachmentService defines the methods that any attachment service should implement
+	AttachmentService interface {
+		// Upload stores a file in a conversation the personnel participates in, it is sent by listing it in a message
+		Upload(personnelID uuid.UUID, in UploadAttachmentInput) (result Attachment, err error)
+		// Download opens an attachment of a conversation the personnel participates in
+		Download(personnelID, id uuid.UUID) (result Attachment, content io.ReadCloser, err error)
+		// DownloadThumbnail opens a thumbnail of an image attachment of a conversation the personnel participates in
+		DownloadThumbnail(personnelID, id uuid.UUID, size int) (result AttachmentThumbnail, content io.ReadCloser, err error)
+	} // @name AttachmentService
+)
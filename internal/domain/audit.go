@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// AuditEventType defines the model for audit_events.type
+	AuditEventType string // @name AuditEventType
+)
+
+type (
+	// AuditEvent defines the model for a security relevant event, kept for review
+	AuditEvent struct {
+		Base
+		Type AuditEventType `db:"type" json:"type" example:"ACCOUNT_LOCKED"`
+		// UserID is the user the event is about, if any
+		UserID    *uuid.UUID `db:"user_id" json:"user_id,omitempty" example:"12345678-1234-1234-1234-123456789012"`
+		UserName  string     `db:"user_name" json:"user_name,omitempty" example:"+919984778491"`
+		IPAddress string     `db:"ip_address" json:"ip_address,omitempty" example:"203.0.113.7"`
+		Details   JSONB      `db:"details" json:"details,omitempty"`
+		CreatedAt time.Time  `db:"created_at" json:"created_at" example:"2022-02-16 15:35:10.535606+05:30"`
+	} // @name AuditEvent
+)
+
+type (
+	// AuditRepository defines the methods that any audit event repository should implement
+	AuditRepository interface {
+		// Create records an audit event
+		Create(ctx context.Context, entity *AuditEvent) (err error)
+	} // @name AuditRepository
+)
+
+const (
+	// AuditEventTypeAccountLocked is recorded when too many failed logins lock a user name out
+	AuditEventTypeAccountLocked AuditEventType = "ACCOUNT_LOCKED"
+	// AuditEventTypeIPAddressLocked is recorded when too many failed logins lock an IP address out
+	AuditEventTypeIPAddressLocked AuditEventType = "IP_ADDRESS_LOCKED"
+)
@@ -50,6 +50,39 @@ type (
 		Size  int64       `json:"size" example:"10"`
 		Page  int64       `json:"page" example:"1"`
 	} // @name PaginationResponse
+
+	// CursorPaginationResponse defines keyset pagination response fields, the cursors are opaque to clients.
+	CursorPaginationResponse struct {
+		Data interface{} `json:"data"`
+		// NextCursor fetches the newer page with ?after=, empty when there is none
+		NextCursor string `json:"next_cursor,omitempty" example:"MjAyNC0xMC0xNlQxNjowNzo1My4xMjM0NTZa..."`
+		// PrevCursor fetches the older page with ?before=, empty when there is none
+		PrevCursor string `json:"prev_cursor,omitempty" example:"MjAyNC0xMC0xNlQxNjowNzo1My4xMjM0NTZa..."`
+		Size       int64  `json:"size" example:"50"`
+	} // @name CursorPaginationResponse
+)
+
+type (
+	// Cursor identifies a row in a listing ordered by (created_at, id)
+	Cursor struct {
+		CreatedAt time.Time
+		ID        uuid.UUID
+	}
+
+	// CursorQuery defines a keyset page, at most one of Before and After is set
+	CursorQuery struct {
+		// Before returns the rows older than the cursor
+		Before *Cursor
+		// After returns the rows newer than the cursor
+		After *Cursor
+		Limit int64
+	}
+
+	// CursorPage defines the cursors around a page of rows, a nil cursor means there is nothing further that way
+	CursorPage struct {
+		Next *Cursor
+		Prev *Cursor
+	}
 )
 
 type (
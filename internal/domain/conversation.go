@@ -0,0 +1,199 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// ConversationType defines the model for conversation.type
+	ConversationType string // @name ConversationType
+	// ParticipantRole defines the model for conversation_participants.role
+	ParticipantRole string // @name ParticipantRole
+)
+
+type (
+	// Conversation defines the model for Conversation
+	Conversation struct {
+		Base
+		Type      ConversationType `db:"type" json:"type,omitempty" example:"GROUP"`
+		Name      *string          `db:"name" json:"name,omitempty" example:"Backend team"`
+		DirectKey *string          `db:"direct_key" json:"-"`
+		CreatedBy *uuid.UUID       `db:"created_by" json:"created_by,omitempty" example:"12345678-1234-1234-1234-123456789012"`
+		BaseAudit
+	} // @name Conversation
+
+	// ConversationParticipant defines the model for ConversationParticipant
+	ConversationParticipant struct {
+		Base
+		ConversationID uuid.UUID       `db:"conversation_id" json:"conversation_id" example:"12345678-1234-1234-1234-123456789012"`
+		PersonnelID    uuid.UUID       `db:"personnel_id" json:"personnel_id" example:"12345678-1234-1234-1234-123456789012"`
+		Role           ParticipantRole `db:"role" json:"role,omitempty" example:"MEMBER"`
+		MutedUntil     *time.Time      `db:"muted_until" json:"muted_until,omitempty" example:"2022-02-16 15:35:10.535606+05:30"`
+		LastReadAt     *time.Time      `db:"last_read_at" json:"last_read_at,omitempty" example:"2022-02-16 15:35:10.535606+05:30"`
+		BaseAudit
+	} // @name ConversationParticipant
+
+	// ConversationInvite defines the model for an invite link to a group conversation
+	ConversationInvite struct {
+		Base
+		ConversationID uuid.UUID `db:"conversation_id" json:"conversation_id" example:"12345678-1234-1234-1234-123456789012"`
+		Token          string    `db:"token" json:"token,omitempty" example:"3f1c8a52-6f0e-4c1b-9d7e-2b8f1f2d9a10"`
+		CreatedBy      uuid.UUID `db:"created_by" json:"created_by" example:"12345678-1234-1234-1234-123456789012"`
+		ExpiresAt      time.Time `db:"expires_at" json:"expires_at" example:"2022-02-16 15:35:10.535606+05:30"`
+		MaxUses        *int      `db:"max_uses" json:"max_uses,omitempty" example:"10"`
+		Uses           int       `db:"uses" json:"uses" example:"2"`
+		BaseAudit
+	} // @name ConversationInvite
+
+	// ConversationSummary defines the model for a conversation in the list of conversations of a personnel
+	ConversationSummary struct {
+		Conversation
+		LatestMessage *Message `db:"latest_message" json:"latest_message,omitempty"`
+		UnreadCount   int64    `db:"unread_count" json:"unread_count" example:"3"`
+	} // @name ConversationSummary
+)
+
+type (
+	// CreateDirectConversationInput defines the input for CreateDirectConversationInput
+	CreateDirectConversationInput struct {
+		PersonnelID uuid.UUID `json:"personnel_id" validate:"required" example:"12345678-1234-1234-1234-123456789012"`
+	} // @name CreateDirectConversationInput
+
+	// CreateGroupConversationInput defines the input for CreateGroupConversationInput
+	CreateGroupConversationInput struct {
+		Name           string      `json:"name" validate:"required,trim,max=100" example:"Backend team"`
+		ParticipantIDs []uuid.UUID `json:"participant_ids"`
+	} // @name CreateGroupConversationInput
+
+	// AddParticipantsInput defines the input for AddParticipantsInput
+	AddParticipantsInput struct {
+		ParticipantIDs []uuid.UUID `json:"participant_ids" validate:"required"`
+	} // @name AddParticipantsInput
+
+	// UpdateParticipantRoleInput defines the input for UpdateParticipantRoleInput
+	UpdateParticipantRoleInput struct {
+		Role ParticipantRole `json:"role" validate:"required,oneof=ADMIN MEMBER" example:"ADMIN"`
+	} // @name UpdateParticipantRoleInput
+
+	// MuteParticipantInput defines the input for MuteParticipantInput
+	MuteParticipantInput struct {
+		// Duration of the mute in hours, 0 lifts the mute
+		Duration int `json:"duration" validate:"min=0" example:"24"`
+	} // @name MuteParticipantInput
+
+	// CreateInviteInput defines the input for CreateInviteInput
+	CreateInviteInput struct {
+		// ExpiresIn is the validity of the invite in hours, the configured default is used when omitted
+		ExpiresIn int  `json:"expires_in" validate:"min=0" example:"24"`
+		MaxUses   *int `json:"max_uses,omitempty" validate:"omitempty,min=1" example:"10"`
+	} // @name CreateInviteInput
+
+	// JoinConversationInput defines the input for JoinConversationInput
+	JoinConversationInput struct {
+		Token string `json:"token" validate:"required" example:"3f1c8a52-6f0e-4c1b-9d7e-2b8f1f2d9a10"`
+	} // @name JoinConversationInput
+)
+
+type (
+	// ConversationRepository defines the methods that any conversation repository should implement
+	ConversationRepository interface {
+		// FindByID returns a conversation by its ID
+		FindByID(ctx context.Context, id uuid.UUID) (result Conversation, err error)
+		// FindByDirectKey returns the direct conversation of a pair of personnel
+		FindByDirectKey(ctx context.Context, directKey string) (result Conversation, err error)
+		// FindByParticipant returns the conversations of a personnel with their latest message and unread count
+		FindByParticipant(ctx context.Context, personnelID uuid.UUID) (result []ConversationSummary, err error)
+		// Create creates a new conversation
+		Create(ctx context.Context, entity *Conversation) (err error)
+		// FindParticipants returns the active participants of a conversation
+		FindParticipants(ctx context.Context, conversationID uuid.UUID) (result []ConversationParticipant, err error)
+		// FindContactIDs returns the personnel sharing at least one conversation with a personnel
+		FindContactIDs(ctx context.Context, personnelID uuid.UUID) (result []uuid.UUID, err error)
+		// FindParticipant returns the active participant of a conversation
+		FindParticipant(ctx context.Context, conversationID, personnelID uuid.UUID) (result ConversationParticipant, err error)
+		// AddParticipants adds participants to a conversation, re-activating the ones that left. Participants that are
+		// active already keep their role and mute, the entities are set to them.
+		AddParticipants(ctx context.Context, entities []*ConversationParticipant) (err error)
+		// RemoveParticipant removes a participant from a conversation
+		RemoveParticipant(ctx context.Context, conversationID, personnelID uuid.UUID) (err error)
+		// UpdateParticipant updates the role and mute of a participant
+		UpdateParticipant(ctx context.Context, entity *ConversationParticipant) (err error)
+		// UpdateLastReadAt moves the read marker of a participant forward
+		UpdateLastReadAt(ctx context.Context, conversationID, personnelID uuid.UUID, readAt time.Time) (err error)
+		// CreateInvite creates a new invite
+		CreateInvite(ctx context.Context, entity *ConversationInvite) (err error)
+		// FindInviteByID returns an invite by its ID
+		FindInviteByID(ctx context.Context, id uuid.UUID) (result ConversationInvite, err error)
+		// FindInviteByToken returns an invite by its token
+		FindInviteByToken(ctx context.Context, token string) (result ConversationInvite, err error)
+		// FindInvitesByConversationID returns the invites of a conversation that have not expired
+		FindInvitesByConversationID(ctx context.Context, conversationID uuid.UUID) (result []ConversationInvite, err error)
+		// UseInvite counts a use of the invite, it fails with pgx.ErrNoRows once the invite is used up
+		UseInvite(ctx context.Context, id uuid.UUID) (err error)
+		// DeleteInvite revokes an invite
+		DeleteInvite(ctx context.Context, id uuid.UUID) (err error)
+	} // @name ConversationRepository
+
+	// ConversationService defines the methods that any conversation service should implement
+	ConversationService interface {
+		// FindByID returns a conversation the personnel participates in
+		FindByID(personnelID, id uuid.UUID) (result Conversation, err error)
+		// FindByParticipant returns the conversations of a personnel with their latest message and unread count
+		FindByParticipant(personnelID uuid.UUID) (result []ConversationSummary, err error)
+		// CreateDirect returns the direct conversation between two personnel, creating it if needed
+		CreateDirect(personnelID uuid.UUID, in CreateDirectConversationInput) (result Conversation, err error)
+		// CreateGroup creates a named group conversation
+		CreateGroup(personnelID uuid.UUID, in CreateGroupConversationInput) (result Conversation, err error)
+		// FindParticipants returns the participants of a conversation the personnel participates in
+		FindParticipants(personnelID, id uuid.UUID) (result []ConversationParticipant, err error)
+		// AddParticipants adds participants to a group conversation
+		AddParticipants(personnelID, id uuid.UUID, in AddParticipantsInput) (result []ConversationParticipant, err error)
+		// RemoveParticipant lets a participant leave a group conversation, or an admin kick another participant
+		RemoveParticipant(personnelID, id, participantID uuid.UUID) (err error)
+		// UpdateParticipantRole promotes or demotes a participant of a group conversation
+		UpdateParticipantRole(personnelID, id, participantID uuid.UUID, in UpdateParticipantRoleInput) (result ConversationParticipant, err error)
+		// MuteParticipant stops a participant of a group conversation from sending messages for a while
+		MuteParticipant(personnelID, id, participantID uuid.UUID, in MuteParticipantInput) (result ConversationParticipant, err error)
+		// CreateInvite creates an invite link to a group conversation
+		CreateInvite(personnelID, id uuid.UUID, in CreateInviteInput) (result ConversationInvite, err error)
+		// FindInvites returns the active invites of a group conversation
+		FindInvites(personnelID, id uuid.UUID) (result []ConversationInvite, err error)
+		// RevokeInvite revokes an invite of a group conversation
+		RevokeInvite(personnelID, id, inviteID uuid.UUID) (err error)
+		// Join adds the personnel to the group conversation of the invite
+		Join(personnelID uuid.UUID, in JoinConversationInput) (result Conversation, err error)
+	} // @name ConversationService
+)
+
+const (
+	ConversationTypeDIRECT ConversationType = "DIRECT"
+	ConversationTypeGROUP  ConversationType = "GROUP"
+)
+
+const (
+	ParticipantRoleOWNER  ParticipantRole = "OWNER"
+	ParticipantRoleADMIN  ParticipantRole = "ADMIN"
+	ParticipantRoleMEMBER ParticipantRole = "MEMBER"
+)
+
+// Rank orders the participant roles, a participant may only moderate participants of a lower rank
+func (r ParticipantRole) Rank() int {
+	switch r {
+	case ParticipantRoleOWNER:
+		return 3
+	case ParticipantRoleADMIN:
+		return 2
+	case ParticipantRoleMEMBER:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// CanModerate reports whether the role may invite, kick, mute and promote participants
+func (r ParticipantRole) CanModerate() bool {
+	return r.Rank() >= ParticipantRoleADMIN.Rank()
+}
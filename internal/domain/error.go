@@ -90,12 +90,48 @@ const (
 	ErrorCodePERSONNEL_NAME_EXISTS  = "PERSONNEL_NAME_EXISTS"
 	ErrorCodeCHECK_IN_OUT_OVERLAP   = "CHECK_IN_OUT_OVERLAP"
 	ErrorCodeWORK_ITEM_ALREADY_PAID = "WORK_ITEM_ALREADY_PAID"
+	ErrorCodeINVALID_CONVERSATION   = "INVALID_CONVERSATION"
+	ErrorCodeINVALID_INVITE         = "INVALID_INVITE"
+	ErrorCodeEDIT_WINDOW_EXPIRED    = "EDIT_WINDOW_EXPIRED"
+	ErrorCodeINVALID_REPLY          = "INVALID_REPLY"
+	ErrorCodeINVALID_ATTACHMENT     = "INVALID_ATTACHMENT"
+	ErrorCodeINVALID_AVATAR         = "INVALID_AVATAR"
+	ErrorCodeINVALID_OTP            = "INVALID_OTP"
+	ErrorCodeOTP_COOLDOWN           = "OTP_COOLDOWN"
+	ErrorCodeINVALID_PASSWORD       = "INVALID_PASSWORD"
+	ErrorCodeINVALID_RESET_TOKEN    = "INVALID_RESET_TOKEN"
+	ErrorCodeINVALID_TOTP           = "INVALID_TOTP"
+	ErrorCodeTOTP_ENABLED           = "TOTP_ENABLED"
+	ErrorCodeTOTP_NOT_ENROLLED      = "TOTP_NOT_ENROLLED"
 )
 
 const (
 	MessageVALIDATIONFAILED    = "Validation failed for some or all of the fields in the request"
 	MessageMOBILENUMBEREXISTS  = "User with this mobile number already exists"
 	MessagePERSONNELNAMEEXISTS = "User with this name is already registered in the system"
+	MessageCONVERSATIONSELF    = "You cannot start a direct conversation with yourself"
+	MessageCONVERSATIONDIRECT  = "Participants of a direct conversation cannot be changed"
+	MessageCONVERSATIONOWNER   = "The owner cannot leave the conversation"
+	MessageINVITEINVALID       = "The invite is invalid or has expired"
+	MessageCURSORINVALID       = "The cursor is invalid"
+	MessageEDITWINDOWEXPIRED   = "The message can no longer be edited"
+	MessageREPLYCONVERSATION   = "The message replied to is not part of this conversation"
+	MessageATTACHMENTTOOLARGE  = "The attachment exceeds the maximum size"
+	MessageATTACHMENTTYPE      = "The attachment type is not allowed"
+	MessageATTACHMENTUNSENT    = "Only unsent attachments you uploaded to this conversation can be sent"
+	MessageAVATARTOOLARGE      = "The avatar exceeds the maximum size"
+	MessageAVATARTYPE          = "The avatar must be a JPEG, PNG, GIF, WebP or BMP image"
+	MessageSEARCHQUERY         = "The search query must have between 1 and 256 characters"
+	MessagePRESENCESTATUS      = "The presence status must be ONLINE or AWAY"
+	MessageSEARCHFILTER        = "The search filters are invalid, ids must be UUIDs and dates RFC 3339 timestamps"
+	MessageOTPINVALID          = "The code is invalid or has expired"
+	MessageOTPATTEMPTS         = "Too many wrong codes, request a new one"
+	MessageOTPCOOLDOWN         = "A code was sent recently, wait before requesting another one"
+	MessagePASSWORDMISMATCH    = "The current password is wrong"
+	MessageRESETTOKENINVALID   = "The reset token is invalid, used or has expired"
+	MessageTOTPINVALID         = "The authenticator code is wrong or was used already"
+	MessageTOTPENABLED         = "Two-factor authentication is enabled already, disable it before enrolling again"
+	MessageTOTPNOTENROLLED     = "Enroll an authenticator before confirming it"
 
 	MessageUNAUTHORIZEDACCESS = "You are not authorized to access this resource"
 	MessageFORBIDDENACCESS    = "You are forbidden from accessing this resource"
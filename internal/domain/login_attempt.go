@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// LoginAttemptScope defines the model for login_attempts.scope
+	LoginAttemptScope string // @name LoginAttemptScope
+)
+
+type (
+	// LoginAttempt defines the model for the failed logins of a user name or an IP address. Every failure delays the
+	// next attempt longer, until too many lock it out for a while.
+	LoginAttempt struct {
+		Scope        LoginAttemptScope `db:"scope" json:"scope"`
+		Subject      string            `db:"subject" json:"subject"`
+		Failures     int               `db:"failures" json:"failures"`
+		LastFailedAt time.Time         `db:"last_failed_at" json:"last_failed_at"`
+		// LockedUntil is when the next attempt is allowed
+		LockedUntil *time.Time `db:"locked_until" json:"locked_until"`
+	} // @name LoginAttempt
+)
+
+type (
+	// LoginAttemptRepository defines the methods that any login attempt repository should implement
+	LoginAttemptRepository interface {
+		// Find returns the failed logins of a user name or an IP address
+		Find(ctx context.Context, scope LoginAttemptScope, subject string) (result LoginAttempt, err error)
+		// RecordFailure counts a failed login and returns the failures, the count starts over when the previous
+		// failure happened before resetBefore
+		RecordFailure(ctx context.Context, scope LoginAttemptScope, subject string, resetBefore time.Time) (result LoginAttempt, err error)
+		// Lock refuses logins of a user name or an IP address until the time
+		Lock(ctx context.Context, scope LoginAttemptScope, subject string, until time.Time) (err error)
+		// Delete forgets the failed logins of a user name or an IP address
+		Delete(ctx context.Context, scope LoginAttemptScope, subject string) (err error)
+		// DeleteExpired removes the failed logins that happened before and are no longer locked
+		DeleteExpired(ctx context.Context, before time.Time) (err error)
+	} // @name LoginAttemptRepository
+)
+
+const (
+	LoginAttemptScopeUSER_NAME  LoginAttemptScope = "USER_NAME"
+	LoginAttemptScopeIP_ADDRESS LoginAttemptScope = "IP_ADDRESS"
+)
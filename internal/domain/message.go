@@ -15,26 +15,81 @@ type (
 	// Message defines the model for Message
 	Message struct {
 		Base
-		SenderID   uuid.UUID `db:"sender_id" json:"sender_id"  validate:"required" example:""`
-		ReceiverID uuid.UUID `db:"receiver_id" json:"receiver_id"  validate:"required" example:""`
-		Content    string    `db:"content" json:"content,omitempty" example:"hi how are you"`
+		SenderID       uuid.UUID `db:"sender_id" json:"sender_id"  validate:"required" example:""`
+		ConversationID uuid.UUID `db:"conversation_id" json:"conversation_id"  validate:"required" example:""`
+		Content        string    `db:"content" json:"content,omitempty" example:"hi how are you"`
+		// EditedAt is set once the content has been edited
+		EditedAt *time.Time `db:"edited_at" json:"edited_at,omitempty" example:"2022-02-16 15:35:10.535606+05:30"`
+		// ReplyToID is the message quoted by this one
+		ReplyToID *uuid.UUID `db:"reply_to_id" json:"reply_to_id,omitempty"`
+		// ThreadRootID is the first message of the thread this one replies in
+		ThreadRootID *uuid.UUID `db:"thread_root_id" json:"thread_root_id,omitempty"`
+		// ReplyCount and LastReplyAt summarize the thread of a root message
+		ReplyCount  int        `db:"reply_count" json:"reply_count" example:"3"`
+		LastReplyAt *time.Time `db:"last_reply_at" json:"last_reply_at,omitempty" example:"2022-02-16 15:35:10.535606+05:30"`
+		// Reactions are the reactions aggregated per emoji, filled in when messages are listed
+		Reactions []ReactionSummary `db:"-" json:"reactions,omitempty"`
+		// Attachments are the files sent with the message, filled in when messages are listed
+		Attachments []Attachment `db:"-" json:"attachments,omitempty"`
 		BaseAudit
 	} // @name  Message
-	// MessageStatus defines the model for MessageStatus
+	// MessageReaction defines the model for the reaction of a personnel to a message
+	MessageReaction struct {
+		Base
+		MessageID   uuid.UUID `db:"message_id" json:"message_id"`
+		PersonnelID uuid.UUID `db:"personnel_id" json:"personnel_id"`
+		Emoji       string    `db:"emoji" json:"emoji" example:"👍"`
+		CreatedAt   time.Time `db:"created_at" json:"created_at" example:"2022-02-16 15:35:10.535606+05:30"`
+	} // @name  MessageReaction
+	// ReactionSummary defines the model for the reactions to a message with the same emoji
+	ReactionSummary struct {
+		MessageID    uuid.UUID   `db:"message_id" json:"-"`
+		Emoji        string      `db:"emoji" json:"emoji" example:"👍"`
+		Count        int64       `db:"count" json:"count" example:"3"`
+		ReactedByMe  bool        `db:"reacted_by_me" json:"reacted_by_me" example:"true"`
+		PersonnelIDs []uuid.UUID `db:"personnel_ids" json:"personnel_ids"`
+	} // @name  ReactionSummary
+	// MessageEdit defines the model for a prior version of the content of a message
+	MessageEdit struct {
+		Base
+		MessageID uuid.UUID `db:"message_id" json:"message_id"`
+		Content   string    `db:"content" json:"content" example:"hi how are you"`
+		EditedBy  uuid.UUID `db:"edited_by" json:"edited_by"`
+		// CreatedAt is when the content was replaced
+		CreatedAt time.Time `db:"created_at" json:"created_at" example:"2022-02-16 15:35:10.535606+05:30"`
+	} // @name  MessageEdit
+	// MessageStatus defines the model for MessageStatus, there is one per recipient of a message
 	MessageStatus struct {
 		Base
-		MessageID uuid.UUID `db:"message_id" json:"message_id"  validate:"required"`
-		Mstatus   Mstatus   `db:"status" json:"m_status"  validate:"required oneof=Sent Delivered, Read"`
-		UpdatedAt time.Time `db:"updated_at" json:"updated_at" example:""`
+		MessageID   uuid.UUID `db:"message_id" json:"message_id"  validate:"required"`
+		RecipientID uuid.UUID `db:"recipient_id" json:"recipient_id"  validate:"required"`
+		Mstatus     Mstatus   `db:"status" json:"m_status"  validate:"required,oneof=Sent Delivered Read"`
+		UpdatedAt   time.Time `db:"updated_at" json:"updated_at" example:""`
+		// SenderID is the sender of the message, only loaded to route the receipt to them
+		SenderID uuid.UUID `db:"sender_id" json:"-"`
 	} // @name  MessageStatus
+	// MessageSearchResult defines the model for a message matching a search
+	MessageSearchResult struct {
+		Message
+		// Rank is the relevance of the message to the search, results are ordered by it
+		Rank float32 `db:"rank" json:"rank" example:"0.0759909"`
+		// Snippet is an HTML escaped excerpt of the content with the matching words wrapped in <mark> tags
+		Snippet string `db:"snippet" json:"snippet" example:"we <mark>decided</mark> to ship on friday"`
+	} // @name  MessageSearchResult
 )
 
 type (
 	// CreateMessageInput  defines the input for CreateMessageInput
 	CreateMessageInput struct {
-		SenderID   uuid.UUID `json:"sender_id" validate:"required"`
-		ReceiverID uuid.UUID `json:"receiver_id" validate:"required"`
-		Content    string    `json:"content" validate:"required"`
+		// SenderID is resolved from the auth token and never read from the request body
+		SenderID       uuid.UUID `json:"-"`
+		ConversationID uuid.UUID `json:"conversation_id" validate:"required"`
+		// Content may be left out when the message carries attachments
+		Content string `json:"content" validate:"required_without=AttachmentIDs"`
+		// AttachmentIDs are uploaded attachments of the conversation sent with the message
+		AttachmentIDs []uuid.UUID `json:"attachment_ids,omitempty" validate:"max=10"`
+		// ReplyToID optionally quotes a message of the same conversation, the new message joins its thread
+		ReplyToID *uuid.UUID `json:"reply_to_id,omitempty"`
 	} // @name  CreateMessageInput
 
 	// UpdateMessageInput  defines the input for UpdateMessageInput
@@ -44,10 +99,45 @@ type (
 
 	// UpdateMessageStatusInput defines the model for  UpdateMessageStatusInput
 	UpdateMessageStatusInput struct {
-		MessageID uuid.UUID `json:"message_id" validate:"required"`
-		Mstatus   Mstatus   `json:"m_status" validate:"required oneof=Sent Delivered Read"`
+		// MessageID is taken from the request path
+		MessageID uuid.UUID `json:"-"`
+		Mstatus   Mstatus   `json:"m_status" validate:"required,oneof=Sent Delivered Read"`
 	} // @name  UpdateMessageStatusInput
 
+	// AddReactionInput defines the model for  AddReactionInput
+	AddReactionInput struct {
+		Emoji string `json:"emoji" validate:"required,trim,max=32" example:"👍"`
+	} // @name  AddReactionInput
+
+	// MarkConversationReadInput defines the model for  MarkConversationReadInput
+	MarkConversationReadInput struct {
+		// ConversationID is taken from the request path
+		ConversationID uuid.UUID `json:"-"`
+		// MessageID is the latest message read, every message up to it is marked as read
+		MessageID uuid.UUID `json:"message_id" validate:"required"`
+	} // @name  MarkConversationReadInput
+
+	// SearchMessagesInput defines the input for SearchMessagesInput, it is read from the query params
+	SearchMessagesInput struct {
+		// Query is a web search style query: words, "quoted phrases", or and -excluded words
+		Query          string
+		SenderID       *uuid.UUID
+		ConversationID *uuid.UUID
+		// From and To bound the time the messages were sent at, both inclusive
+		From *time.Time
+		To   *time.Time
+		// After returns the results ranked below the cursor
+		After *SearchCursor
+		Limit int64
+	}
+)
+
+type (
+	// SearchCursor identifies a result in a search ordered by (rank, id)
+	SearchCursor struct {
+		Rank float32
+		ID   uuid.UUID
+	}
 )
 
 const (
@@ -63,43 +153,82 @@ type (
 		FindByID(ctx context.Context, id uuid.UUID) (result Message, err error)
 		// FindAll returns all messages
 		FindAll(ctx context.Context) (result []Message, err error)
-		// FindBySenderID returns all messages sent by a
-		FindBySenderID(ctx context.Context, senderID uuid.UUID) (result []Message, err error)
-		// FindByReceiverID returns all messages received by a user
-		FindByReceiverID(ctx context.Context, receiverID uuid.UUID) (result []Message, err error)
+		// FindBySenderID returns a page of the messages sent by a personnel, oldest first
+		FindBySenderID(ctx context.Context, senderID uuid.UUID, page CursorQuery) (result []Message, err error)
+		// FindByConversationID returns a page of the messages of a conversation, oldest first
+		FindByConversationID(ctx context.Context, conversationID uuid.UUID, page CursorQuery) (result []Message, err error)
+		// FindByThreadRootID returns a page of the replies in the thread of a message, oldest first
+		FindByThreadRootID(ctx context.Context, threadRootID uuid.UUID, page CursorQuery) (result []Message, err error)
 		// Create creates a new message
 		Create(ctx context.Context, entity *Message) (err error)
+		// RefreshThreadSummary recounts the replies of a thread root and its last reply time
+		RefreshThreadSummary(ctx context.Context, threadRootID uuid.UUID) (err error)
 		// CreateMultiple creates multiple messages
 		CreateMultiple(ctx context.Context, entities []*Message) (err error)
 		// UpdateMessage updates a message
 		Update(ctx context.Context, entity *Message) (err error)
+		// AddReaction adds the reaction of a personnel, it fails with pgx.ErrNoRows when the reaction already exists
+		AddReaction(ctx context.Context, entity *MessageReaction) (err error)
+		// RemoveReaction removes the reaction of a personnel and returns it, it fails with pgx.ErrNoRows when there is none
+		RemoveReaction(ctx context.Context, messageID, personnelID uuid.UUID, emoji string) (result MessageReaction, err error)
+		// FindReactionSummaries returns the reactions to the messages aggregated per message and emoji, as seen by a personnel
+		FindReactionSummaries(ctx context.Context, messageIDs []uuid.UUID, personnelID uuid.UUID) (result []ReactionSummary, err error)
+		// CreateMessageEdit stores a prior version of the content of a message
+		CreateMessageEdit(ctx context.Context, entity *MessageEdit) (err error)
+		// FindEditsByMessageID returns the prior versions of the content of a message, oldest first
+		FindEditsByMessageID(ctx context.Context, messageID uuid.UUID) (result []MessageEdit, err error)
+		// Search returns a page of the messages of the conversations of a personnel matching the search, most relevant first
+		Search(ctx context.Context, personnelID uuid.UUID, in SearchMessagesInput) (result []MessageSearchResult, err error)
 		// UpdateMultiple updates multiple messages
 		UpdateMultiple(ctx context.Context, entities []*Message) (err error)
 		// CreateMessageStatus creates a new message status
 		CreateMessageStatus(ctx context.Context, entity *MessageStatus) (err error)
-		// UpdateMessageStatus updates the status of a message
+		// CreateMessageStatusForParticipants creates a Sent status for every participant of the conversation except the sender
+		CreateMessageStatusForParticipants(ctx context.Context, message *Message) (err error)
+		// FindMessageStatusByMessageID returns the statuses of a message for each of its recipients
+		FindMessageStatusByMessageID(ctx context.Context, messageID uuid.UUID) (result []MessageStatus, err error)
+		// UpdateMessageStatus moves the status of a message for a recipient forward, it fails with pgx.ErrNoRows when there is nothing to change
 		UpdateMessageStatus(ctx context.Context, entity *MessageStatus) (err error)
+		// MarkConversationRead marks the messages of a conversation up to a time as read for a recipient and returns the changed statuses
+		MarkConversationRead(ctx context.Context, conversationID, recipientID uuid.UUID, upTo time.Time) (result []MessageStatus, err error)
 		// Delete Message deletes a message
 		Delete(ctx context.Context, id uuid.UUID) (err error)
 	} // @name  MessageRepository
 
 	//  MessageService defines the methods that any message Service should implement
 	MessageService interface {
-		// FindByID  returns a message by its ID
-		FindByID(id uuid.UUID) (result Message, err error)
+		// FindByID  returns a message by its ID if the personnel participates in its conversation
+		FindByID(personnelID, id uuid.UUID) (result Message, err error)
 		// FindAll returns all messages
 		FindAll() (result []Message, err error)
-		// FindBySenderID returns all messages sent by a
-		FindBySenderID(senderID uuid.UUID) (result []Message, err error)
-		// FindByReceiverID returns all messages received by a user
-		FindByReceiverID(receiverID uuid.UUID) (result []Message, err error)
+		// FindBySenderID returns a page of the messages sent by a personnel
+		FindBySenderID(senderID uuid.UUID, q CursorQuery) (result []Message, page CursorPage, err error)
+		// FindByConversationID returns a page of the messages of a conversation the personnel participates in
+		FindByConversationID(personnelID, conversationID uuid.UUID, q CursorQuery) (result []Message, page CursorPage, err error)
+		// FindReplies returns a page of the replies in the thread of a message the personnel can see
+		FindReplies(personnelID, id uuid.UUID, q CursorQuery) (result []Message, page CursorPage, err error)
 		// Create creates a new message
 		Create(in CreateMessageInput) (result Message, err error)
-		// Update Message updates a message
-		Update(id uuid.UUID, in UpdateMessageInput) (result Message, err error)
-		// UpdateMessageStatus  updates the status of a message
-		UpdateMessageStatus(in UpdateMessageStatusInput) (err error)
-		// Delete Message deletes a message
-		Delete(id uuid.UUID) (err error)
+		// Update Message updates a message, only its sender may update it and only within the edit window
+		Update(personnelID, id uuid.UUID, in UpdateMessageInput) (result Message, err error)
+		// AddReaction reacts to a message with an emoji and returns the reactions to it
+		AddReaction(personnelID, id uuid.UUID, in AddReactionInput) (result []ReactionSummary, err error)
+		// RemoveReaction takes a reaction back and returns the reactions to the message
+		RemoveReaction(personnelID, id uuid.UUID, emoji string) (result []ReactionSummary, err error)
+		// FindReactions returns the reactions to a message aggregated per emoji
+		FindReactions(personnelID, id uuid.UUID) (result []ReactionSummary, err error)
+		// FindEdits returns the revision history of a message of a conversation the personnel participates in
+		FindEdits(personnelID, id uuid.UUID) (result []MessageEdit, err error)
+		// Search returns a page of the messages of the conversations the personnel participates in matching the search,
+		// most relevant first, next is nil on the last page
+		Search(personnelID uuid.UUID, in SearchMessagesInput) (result []MessageSearchResult, next *SearchCursor, err error)
+		// UpdateMessageStatus  updates the status of a message, only the other participants may update it
+		UpdateMessageStatus(personnelID uuid.UUID, in UpdateMessageStatusInput) (err error)
+		// MarkConversationRead marks every message of a conversation up to the given one as read by the personnel
+		MarkConversationRead(personnelID uuid.UUID, in MarkConversationReadInput) (err error)
+		// FindReceipts returns the delivery and read status of a message for each of its recipients
+		FindReceipts(personnelID, id uuid.UUID) (result []MessageStatus, err error)
+		// Delete Message deletes a message, only its sender may delete it
+		Delete(personnelID, id uuid.UUID) (err error)
 	}
 )
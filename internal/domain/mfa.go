@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// TOTPAuthenticator defines the model for the RFC 6238 authenticator of a user, it takes part in logins once
+	// confirmed
+	TOTPAuthenticator struct {
+		UserID uuid.UUID `db:"user_id" json:"user_id"`
+		// Secret is encrypted
+		Secret      string     `db:"secret" json:"-"`
+		ConfirmedAt *time.Time `db:"confirmed_at" json:"confirmed_at"`
+		// LastUsedStep is the time step of the last accepted code, codes of it and earlier steps are refused
+		LastUsedStep int64     `db:"last_used_step" json:"-"`
+		CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	} // @name TOTPAuthenticator
+
+	// RecoveryCode defines the model for a code that stands in for the authenticator once, only its hash is stored
+	RecoveryCode struct {
+		Base
+		UserID    uuid.UUID  `db:"user_id" json:"user_id"`
+		CodeHash  string     `db:"code_hash" json:"-"`
+		UsedAt    *time.Time `db:"used_at" json:"used_at"`
+		CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	} // @name RecoveryCode
+
+	// MFAChallenge defines the model for a login that checked the password and waits for the code of the
+	// authenticator, only the hash of its token is stored
+	MFAChallenge struct {
+		Base
+		UserID     uuid.UUID `db:"user_id" json:"user_id"`
+		TokenHash  string    `db:"token_hash" json:"-"`
+		DeviceName string    `db:"device_name" json:"device_name"`
+		UserAgent  string    `db:"user_agent" json:"user_agent"`
+		IPAddress  string    `db:"ip_address" json:"ip_address"`
+		Attempts   int       `db:"attempts" json:"attempts"`
+		ExpiresAt  time.Time `db:"expires_at" json:"expires_at"`
+		CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	} // @name MFAChallenge
+)
+
+type (
+	// EnrollTOTPOutput defines the model for EnrollTOTPOutput
+	EnrollTOTPOutput struct {
+		// Secret is entered in authenticator apps that cannot scan the provisioning URI
+		Secret          string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+		ProvisioningURI string `json:"provisioning_uri" example:"otpauth://totp/ChatApp:+919984778491?algorithm=SHA1&digits=6&issuer=ChatApp&period=30&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	} // @name EnrollTOTPOutput
+
+	// TOTPCodeInput defines the model for TOTPCodeInput
+	TOTPCodeInput struct {
+		// Code is the current code of the authenticator, or a recovery code where accepted
+		Code string `json:"code" validate:"required,max=32" example:"123456"`
+	} // @name TOTPCodeInput
+
+	// RecoveryCodesOutput defines the model for RecoveryCodesOutput
+	RecoveryCodesOutput struct {
+		// RecoveryCodes each stand in for the authenticator once, they are shown only this time
+		RecoveryCodes []string `json:"recovery_codes" example:"dp4bm-tgcs3,nlgqr-te4hw"`
+	} // @name RecoveryCodesOutput
+
+	// VerifyMFAInput defines the model for VerifyMFAInput
+	VerifyMFAInput struct {
+		ChallengeToken string `json:"challenge_token" validate:"required,max=128"`
+		// Code is the current code of the authenticator or an unused recovery code
+		Code      string `json:"code" validate:"required,max=32" example:"123456"`
+		UserAgent string `json:"-"`
+		IPAddress string `json:"-"`
+	} // @name VerifyMFAInput
+)
+
+type (
+	// MFARepository defines the methods that any two-factor authentication repository should implement
+	MFARepository interface {
+		// SaveTOTP creates the authenticator of a user, replacing one that is not confirmed
+		SaveTOTP(ctx context.Context, entity *TOTPAuthenticator) (err error)
+		// FindTOTP returns the authenticator of a user
+		FindTOTP(ctx context.Context, userID uuid.UUID) (result TOTPAuthenticator, err error)
+		// ConfirmTOTP lets the authenticator of a user take part in logins, step is the time step of the code that
+		// confirmed it
+		ConfirmTOTP(ctx context.Context, userID uuid.UUID, step int64) (err error)
+		// UseTOTPStep records the time step of an accepted code, it fails with pgx.ErrNoRows when a code of the
+		// step or a later one was accepted already
+		UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (err error)
+		// DeleteTOTP removes the authenticator of a user along with the recovery codes
+		DeleteTOTP(ctx context.Context, userID uuid.UUID) (err error)
+		// ReplaceRecoveryCodes replaces the recovery codes of a user with the ones of the hashes
+		ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes []string) (err error)
+		// UseRecoveryCode marks the recovery code of the hash used, it fails with pgx.ErrNoRows when the user has no
+		// such unused code
+		UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash string) (err error)
+		// CreateChallenge creates a login challenge
+		CreateChallenge(ctx context.Context, entity *MFAChallenge) (err error)
+		// CountChallengeAttempt counts a code given to the login challenge with the hash and returns it, it fails
+		// with pgx.ErrNoRows when there is none unexpired with fewer than maxAttempts codes
+		CountChallengeAttempt(ctx context.Context, hash string, maxAttempts int) (result MFAChallenge, err error)
+		// DeleteChallenge removes a login challenge
+		DeleteChallenge(ctx context.Context, id uuid.UUID) (err error)
+		// DeleteExpiredChallenges removes the login challenges that expired before
+		DeleteExpiredChallenges(ctx context.Context, before time.Time) (err error)
+	} // @name MFARepository
+)
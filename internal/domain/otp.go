@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// OTP defines the model for a one time password sent to a mobile number, only its hash is stored.
+	// A number has at most one, requesting another replaces it.
+	OTP struct {
+		Base
+		UserName  string    `db:"user_name" json:"user_name"`
+		CodeHash  string    `db:"code_hash" json:"-"`
+		Attempts  int       `db:"attempts" json:"attempts"`
+		ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
+		SentAt    time.Time `db:"sent_at" json:"sent_at"`
+	} // @name OTP
+)
+
+type (
+	// RequestOTPInput defines the model for RequestOTPInput
+	RequestOTPInput struct {
+		UserName string `json:"user_name" validate:"required,e164" example:"+919984778491"`
+	} // @name RequestOTPInput
+
+	// RequestOTPOutput defines the model for RequestOTPOutput
+	RequestOTPOutput struct {
+		// ExpiresIn is the lifetime of the code in seconds
+		ExpiresIn int64 `json:"expires_in" example:"300"`
+		// ResendIn is the number of seconds before another code can be requested
+		ResendIn int64 `json:"resend_in" example:"60"`
+	} // @name RequestOTPOutput
+
+	// VerifyOTPInput defines the model for VerifyOTPInput
+	VerifyOTPInput struct {
+		UserName string `json:"user_name" validate:"required,e164" example:"+919984778491"`
+		Code     string `json:"code" validate:"required,max=12" example:"123456"`
+		// DeviceName names the session of the login, it is made up from the user agent when left empty
+		DeviceName string `json:"device_name,omitempty" validate:"max=100" example:"Rizwan's phone"`
+		UserAgent  string `json:"-"`
+		IPAddress  string `json:"-"`
+	} // @name VerifyOTPInput
+)
+
+type (
+	// OTPRepository defines the methods that any one time password repository should implement
+	OTPRepository interface {
+		// Save creates the one time password of a number, replacing the previous one
+		Save(ctx context.Context, entity *OTP) (err error)
+		// FindByUserName returns the one time password of a number
+		FindByUserName(ctx context.Context, userName string) (result OTP, err error)
+		// CountAttempt counts a guess of the one time password of a number and returns it, it fails with
+		// pgx.ErrNoRows when there is none left with fewer than maxAttempts guesses
+		CountAttempt(ctx context.Context, userName string, maxAttempts int) (result OTP, err error)
+		// Delete removes a one time password
+		Delete(ctx context.Context, id uuid.UUID) (err error)
+		// DeleteExpired removes the one time passwords that expired before
+		DeleteExpired(ctx context.Context, before time.Time) (err error)
+	} // @name OTPRepository
+)
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// PasswordResetToken defines the model for a token that resets the password of a user once, only its hash
+	// is stored. A user has at most one, requesting another replaces it.
+	PasswordResetToken struct {
+		Base
+		UserID    uuid.UUID  `db:"user_id" json:"user_id"`
+		TokenHash string     `db:"token_hash" json:"-"`
+		ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+		UsedAt    *time.Time `db:"used_at" json:"used_at"`
+		CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	} // @name PasswordResetToken
+
+	// PasswordHistory defines the model for a password a user had, only its hash is stored
+	PasswordHistory struct {
+		Base
+		UserID       uuid.UUID `db:"user_id" json:"user_id"`
+		PasswordHash string    `db:"password_hash" json:"-"`
+		CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	} // @name PasswordHistory
+)
+
+type (
+	// ChangePasswordInput defines the model for ChangePasswordInput
+	ChangePasswordInput struct {
+		OldPassword string `json:"old_password" validate:"required" example:"password123"`
+		NewPassword string `json:"new_password" validate:"required" example:"correct horse battery staple"`
+		IPAddress   string `json:"-"`
+	} // @name ChangePasswordInput
+
+	// ForgotPasswordInput defines the model for ForgotPasswordInput
+	ForgotPasswordInput struct {
+		UserName string `json:"user_name" validate:"required,e164" example:"+919984778491"`
+	} // @name ForgotPasswordInput
+
+	// ResetPasswordInput defines the model for ResetPasswordInput
+	ResetPasswordInput struct {
+		Token    string `json:"token" validate:"required,max=128"`
+		Password string `json:"password" validate:"required" example:"correct horse battery staple"`
+	} // @name ResetPasswordInput
+)
+
+type (
+	// PasswordResetRepository defines the methods that any password reset token repository should implement
+	PasswordResetRepository interface {
+		// Save creates the reset token of a user, replacing the previous one
+		Save(ctx context.Context, entity *PasswordResetToken) (err error)
+		// Consume marks the reset token with the hash used and returns it, it fails with pgx.ErrNoRows when
+		// there is none that is unused and unexpired
+		Consume(ctx context.Context, hash string) (result PasswordResetToken, err error)
+		// DeleteExpired removes the reset tokens that expired before
+		DeleteExpired(ctx context.Context, before time.Time) (err error)
+	} // @name PasswordResetRepository
+
+	// PasswordHistoryRepository defines the methods that any password history repository should implement
+	PasswordHistoryRepository interface {
+		// Create records a password of a user and forgets the ones before the latest keep
+		Create(ctx context.Context, entity *PasswordHistory, keep int) (err error)
+		// FindLatest returns the latest limit passwords of a user, latest first
+		FindLatest(ctx context.Context, userID uuid.UUID, limit int) (result []PasswordHistory, err error)
+	} // @name PasswordHistoryRepository
+)
@@ -0,0 +1,57 @@
+package domain
+
+type (
+	// Permission represents an action a role may take on a kind of resource
+	Permission string
+)
+
+const (
+	PermissionUserRead        Permission = "user:read"
+	PermissionPersonnelRead   Permission = "personnel:read"
+	PermissionPersonnelCreate Permission = "personnel:create"
+	// PermissionPersonnelUpdate allows updating any personnel, everyone may update their own
+	PermissionPersonnelUpdate Permission = "personnel:update"
+	PermissionPersonnelDelete Permission = "personnel:delete"
+	// PermissionPersonnelManage allows changing the role and activation status of personnel, including one's own
+	PermissionPersonnelManage Permission = "personnel:manage"
+)
+
+// RolePermissions is the policy granting permissions to roles, a role missing from it has none
+var RolePermissions = map[UserRole][]Permission{
+	UserRoleAmin: {
+		PermissionUserRead,
+		PermissionPersonnelRead,
+		PermissionPersonnelCreate,
+		PermissionPersonnelUpdate,
+		PermissionPersonnelDelete,
+		PermissionPersonnelManage,
+	},
+	UserRoleUser: {
+		PermissionUserRead,
+		PermissionPersonnelRead,
+	},
+}
+
+// MFARequiredRoles are the roles whose permissions are granted only to logins that gave the code of an
+// authenticator, their users hold the USER permissions otherwise
+var MFARequiredRoles = []UserRole{UserRoleAmin}
+
+// RequiresMFA tells whether the permissions of the role need a login with two-factor authentication
+func (r UserRole) RequiresMFA() bool {
+	for _, role := range MFARequiredRoles {
+		if role == r {
+			return true
+		}
+	}
+	return false
+}
+
+// Can tells whether the policy grants the permission to the role
+func (r UserRole) Can(p Permission) bool {
+	for _, granted := range RolePermissions[r] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
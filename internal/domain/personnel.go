@@ -2,6 +2,8 @@ package domain
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/gofrs/uuid/v5"
 )
@@ -23,7 +25,9 @@ type (
 		Mobile           string           `db:"mobile" json:"mobile,omitempty" example:"+919984778492"`
 		Address          Address          `db:"address" sql:"jsonb" json:"address,omitempty"`
 		Role             UserRole         `db:"role" json:"role,omitempty" example:"ADMIN"`
-		Avatar           *string          `db:"avatar" json:"avatar,omitempty" example:"https://example.com/avatar.jpg"`
+		Avatar           *string          `db:"avatar" json:"avatar,omitempty" example:"https://example.com/api/v1/avatars/12345678-1234-1234-1234-123456789012/87654321-4321-4321-4321-210987654321"`
+		AvatarKey        *string          `db:"avatar_key" json:"-"`
+		LastSeenAt       *time.Time       `db:"last_seen_at" json:"last_seen_at,omitempty" example:"2022-02-16 15:35:10.535606+05:30"`
 		UserID           uuid.UUID        `db:"user_id" json:"user_id"  example:"12345678-1234-1234-1234-123456789012"`
 		ActivationStatus ActivationStatus `db:"activation_status" json:"activation_status,omitempty" example:"ACTIVATE"`
 		BaseAudit
@@ -39,7 +43,6 @@ type (
 		Mobile           string           `json:"mobile,omitempty" example:"+919984778492"`
 		Address          Address          `json:"address,omitempty"`
 		Role             UserRole         `json:"role,omitempty" example:"ADMIN"`
-		Avatar           string           `json:"avatar,omitempty" example:"https://example.com/avatar.jpg"`
 		UserID           uuid.UUID        `json:"user_id" example:"12345678-1234-1234"`
 		ActivationStatus ActivationStatus `json:"activation_status,omitempty" example:"ACTIVATE"`
 	} // @name  CreatePersonnelInput
@@ -52,10 +55,14 @@ type (
 		Address          Address          `json:"address,omitempty"`
 		Email            string           `json:"email,omitempty" example:"expertkhan@gmail.com"`
 		Role             UserRole         `json:"role,omitempty" example:"ADMIN"`
-		Avatar           string           `json:"avatar,omitempty" example:"https://example.com/avatar.jpg"`
 		ActivationStatus ActivationStatus `json:"activation_status,omitempty" example:"ACTIVATE"`
 	} // @name  UpdatePersonnelInput
 
+	// UploadAvatarInput defines the input for UploadAvatarInput, it is read from a multipart form
+	UploadAvatarInput struct {
+		Size    int64
+		Content io.Reader
+	}
 )
 
 type (
@@ -75,6 +82,8 @@ type (
 		Update(ctx context.Context, entity *Personnel) (err error)
 		// Delete deletes a personnel.
 		Delete(ctx context.Context, id uuid.UUID) (err error)
+		// UpdateAvatar sets the avatar of a personnel.
+		UpdateAvatar(ctx context.Context, entity *Personnel) (err error)
 	}
 	// PersonnelService defines the methods that personnel  service should implement
 	PersonnelService interface {
@@ -92,6 +101,13 @@ type (
 		Update(id uuid.UUID, in UpdatePersonnelInput) (result Personnel, err error)
 		// Delete deletes a personnel.
 		Delete(id uuid.UUID) (err error)
+		// UpdateAvatar replaces the avatar of a personnel with the uploaded image
+		UpdateAvatar(id uuid.UUID, in UploadAvatarInput) (result Personnel, err error)
+		// DeleteAvatar removes the avatar of a personnel
+		DeleteAvatar(id uuid.UUID) (result Personnel, err error)
+		// FindAvatar opens the avatar of a personnel in the smallest size at least as large as the one asked for, a size
+		// of 0 asks for the largest
+		FindAvatar(id uuid.UUID, version string, size int) (content io.ReadCloser, contentType string, err error)
 	}
 )
 
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// PresenceStatus defines the model for presence.status
+	PresenceStatus string // @name PresenceStatus
+)
+
+type (
+	// Presence defines the model for the presence of a personnel, aggregated over their connections
+	Presence struct {
+		PersonnelID uuid.UUID      `db:"personnel_id" json:"personnel_id" example:"12345678-1234-1234-1234-123456789012"`
+		UserID      uuid.UUID      `db:"user_id" json:"user_id" example:"12345678-1234-1234-1234-123456789012"`
+		Status      PresenceStatus `db:"status" json:"status" example:"ONLINE"`
+		// LastSeenAt is when the personnel was last connected
+		LastSeenAt *time.Time `db:"last_seen_at" json:"last_seen_at,omitempty" example:"2022-02-16 15:35:10.535606+05:30"`
+	} // @name Presence
+
+	// PresenceConnection defines the model for a live realtime connection of a personnel
+	PresenceConnection struct {
+		Base
+		PersonnelID uuid.UUID      `db:"personnel_id" json:"personnel_id"`
+		Status      PresenceStatus `db:"status" json:"status"`
+		ConnectedAt time.Time      `db:"connected_at" json:"connected_at"`
+		HeartbeatAt time.Time      `db:"heartbeat_at" json:"heartbeat_at"`
+	} // @name PresenceConnection
+)
+
+type (
+	// FindPresenceInput defines the model for FindPresenceInput
+	FindPresenceInput struct {
+		UserIDs []uuid.UUID `json:"user_ids" validate:"required,min=1,max=500"`
+	} // @name FindPresenceInput
+
+	// SetPresenceInput defines the payload of the presence.set event sent by clients
+	SetPresenceInput struct {
+		Status PresenceStatus `json:"status" example:"AWAY"`
+	} // @name SetPresenceInput
+)
+
+type (
+	// PresenceRepository defines the methods that any presence repository should implement
+	PresenceRepository interface {
+		// FindByPersonnelIDs returns the presence of the personnel, connections without a heartbeat since freshAfter are ignored
+		FindByPersonnelIDs(ctx context.Context, personnelIDs []uuid.UUID, freshAfter time.Time) (result []Presence, err error)
+		// FindByUserIDs returns the presence of the personnel of the users, connections without a heartbeat since freshAfter are ignored
+		FindByUserIDs(ctx context.Context, userIDs []uuid.UUID, freshAfter time.Time) (result []Presence, err error)
+		// SaveConnection creates or refreshes a connection with the status
+		SaveConnection(ctx context.Context, entity *PresenceConnection) (err error)
+		// TouchConnection refreshes the heartbeat of a connection, it fails with pgx.ErrNoRows when the connection is gone
+		TouchConnection(ctx context.Context, id uuid.UUID) (err error)
+		// DeleteConnection removes a connection, it fails with pgx.ErrNoRows when the connection is gone
+		DeleteConnection(ctx context.Context, id uuid.UUID) (result PresenceConnection, err error)
+		// DeleteStaleConnections removes the connections without a heartbeat since before and returns them
+		DeleteStaleConnections(ctx context.Context, before time.Time) (result []PresenceConnection, err error)
+		// UpdateLastSeen moves the last seen time of a personnel forward
+		UpdateLastSeen(ctx context.Context, personnelID uuid.UUID, at time.Time) (err error)
+	} // @name PresenceRepository
+
+	// PresenceService defines the methods that any presence service should implement
+	PresenceService interface {
+		// Connect records a new realtime connection of a personnel
+		Connect(personnelID, connectionID uuid.UUID) (err error)
+		// Heartbeat keeps a connection alive
+		Heartbeat(personnelID, connectionID uuid.UUID, status PresenceStatus) (err error)
+		// SetStatus changes the status of a connection, ONLINE or AWAY
+		SetStatus(personnelID, connectionID uuid.UUID, status PresenceStatus) (err error)
+		// Disconnect records the end of a connection
+		Disconnect(personnelID, connectionID uuid.UUID) (err error)
+		// FindByUserID returns the presence of a user
+		FindByUserID(userID uuid.UUID) (result Presence, err error)
+		// FindByUserIDs returns the presence of the users that exist
+		FindByUserIDs(in FindPresenceInput) (result []Presence, err error)
+	} // @name PresenceService
+)
+
+const (
+	PresenceStatusONLINE  PresenceStatus = "ONLINE"
+	PresenceStatusAWAY    PresenceStatus = "AWAY"
+	PresenceStatusOFFLINE PresenceStatus = "OFFLINE"
+)
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// EventType defines the type of realtime event
+	EventType string // @name EventType
+)
+
+type (
+	// Event defines the model for an event exchanged over the realtime channel
+	Event struct {
+		Type    EventType   `json:"type" example:"message.created"`
+		Payload interface{} `json:"payload,omitempty"`
+	} // @name Event
+)
+
+type (
+	// EventPublisher defines the methods that any realtime event publisher should implement
+	EventPublisher interface {
+		// Publish delivers the event to every live connection of the recipient
+		Publish(ctx context.Context, recipientID uuid.UUID, event Event) (err error)
+	}
+)
+
+const (
+	EventTypePing            EventType = "ping"
+	EventTypePong            EventType = "pong"
+	EventTypeMessageCreated  EventType = "message.created"
+	EventTypeMessageUpdated  EventType = "message.updated"
+	EventTypeReactionAdded   EventType = "reaction.added"
+	EventTypeReactionRemoved EventType = "reaction.removed"
+	// EventTypeMessageStatusUpdated carries the statuses that changed on messages the recipient sent
+	EventTypeMessageStatusUpdated EventType = "message.status_updated"
+
+	EventTypeConversationCreated EventType = "conversation.created"
+
+	// EventTypePresenceSet is sent by clients to switch their connection between ONLINE and AWAY
+	EventTypePresenceSet EventType = "presence.set"
+	// EventTypePresenceUpdated carries the presence of a personnel sharing a conversation with the recipient
+	EventTypePresenceUpdated EventType = "presence.updated"
+
+	// EventTypeTypingStart and EventTypeTypingStop are sent by clients with the conversation they type in,
+	// EventTypeTypingStart is repeated every few seconds while typing goes on
+	EventTypeTypingStart EventType = "typing.start"
+	EventTypeTypingStop  EventType = "typing.stop"
+	// EventTypeTypingStarted and EventTypeTypingStopped tell the other participants who is typing
+	EventTypeTypingStarted EventType = "typing.started"
+	EventTypeTypingStopped EventType = "typing.stopped"
+)
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// Session defines the model for a login of a user on a device, its refresh tokens and access tokens
+	// are revoked along with it
+	Session struct {
+		Base
+		UserID     uuid.UUID `db:"user_id" json:"user_id" example:"12345678-1234-1234-1234-123456789012"`
+		UserAgent  string    `db:"user_agent" json:"user_agent" example:"Mozilla/5.0 (Windows NT 10.0; Win64; x64) ..."`
+		IPAddress  string    `db:"ip_address" json:"ip_address" example:"203.0.113.7"`
+		DeviceName string    `db:"device_name" json:"device_name" example:"Chrome on Windows"`
+		// MFA tells whether the login gave the code of an authenticator, which roles such as ADMIN need
+		MFA bool `db:"mfa" json:"mfa" example:"true"`
+		// Current tells whether the session is the one of the auth token of the request
+		Current   bool      `db:"-" json:"current" example:"true"`
+		CreatedAt time.Time `db:"created_at" json:"created_at" example:"2022-02-16 15:35:10.535606+05:30"`
+		// LastUsedAt is when the session last logged in or refreshed its tokens
+		LastUsedAt time.Time  `db:"last_used_at" json:"last_used_at" example:"2022-02-16 15:35:10.535606+05:30"`
+		ExpiresAt  time.Time  `db:"expires_at" json:"expires_at" example:"2022-02-20 09:35:10.535606+05:30"`
+		RevokedAt  *time.Time `db:"revoked_at" json:"-"`
+	} // @name Session
+)
+
+type (
+	// SessionRepository defines the methods that any session repository should implement
+	SessionRepository interface {
+		// Create creates a session
+		Create(ctx context.Context, entity *Session) (err error)
+		// FindByID returns a session
+		FindByID(ctx context.Context, id uuid.UUID) (result Session, err error)
+		// FindActiveByUserID returns the sessions of a user that are neither revoked nor expired, latest used first
+		FindActiveByUserID(ctx context.Context, userID uuid.UUID) (result []Session, err error)
+		// Touch records the use of a session from the address and user agent and extends it until expiresAt
+		Touch(ctx context.Context, id uuid.UUID, ipAddress, userAgent string, expiresAt time.Time) (err error)
+		// Revoke ends a session
+		Revoke(ctx context.Context, id uuid.UUID) (err error)
+		// DeleteExpired removes the sessions that expired before, along with their refresh tokens
+		DeleteExpired(ctx context.Context, before time.Time) (err error)
+	} // @name SessionRepository
+
+	// SessionCloser defines the methods that any holder of live connections should implement
+	SessionCloser interface {
+		// CloseSession disconnects the live connections opened with tokens of the session, on every instance
+		CloseSession(ctx context.Context, sessionID uuid.UUID) (err error)
+	}
+)
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// RefreshToken defines the model for a refresh token, only its hash is stored.
+	// Every refresh replaces the token with a new one of the same session.
+	RefreshToken struct {
+		Base
+		UserID    uuid.UUID `db:"user_id" json:"user_id"`
+		SessionID uuid.UUID `db:"session_id" json:"session_id"`
+		TokenHash string    `db:"token_hash" json:"-"`
+		// AccessTokenID is the jti of the access token issued along with the refresh token
+		AccessTokenID   uuid.UUID  `db:"access_token_id" json:"access_token_id"`
+		AccessExpiresAt time.Time  `db:"access_expires_at" json:"access_expires_at"`
+		ExpiresAt       time.Time  `db:"expires_at" json:"expires_at"`
+		UsedAt          *time.Time `db:"used_at" json:"used_at"`
+		RevokedAt       *time.Time `db:"revoked_at" json:"revoked_at"`
+		CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+	} // @name RefreshToken
+)
+
+type (
+	// RefreshTokenInput defines the model for RefreshTokenInput
+	RefreshTokenInput struct {
+		RefreshToken string `json:"refresh_token" validate:"required"`
+		UserAgent    string `json:"-"`
+		IPAddress    string `json:"-"`
+	} // @name RefreshTokenInput
+)
+
+type (
+	// TokenRepository defines the methods that any token repository should implement
+	TokenRepository interface {
+		// CreateRefreshToken creates a refresh token
+		CreateRefreshToken(ctx context.Context, entity *RefreshToken) (err error)
+		// FindRefreshTokenByHash returns the refresh token with the hash
+		FindRefreshTokenByHash(ctx context.Context, hash string) (result RefreshToken, err error)
+		// FindRefreshTokenByAccessTokenID returns the refresh token issued along with an access token
+		FindRefreshTokenByAccessTokenID(ctx context.Context, accessTokenID uuid.UUID) (result RefreshToken, err error)
+		// MarkRefreshTokenUsed records the rotation of a refresh token, it fails with pgx.ErrNoRows when the
+		// token was already used or revoked
+		MarkRefreshTokenUsed(ctx context.Context, id uuid.UUID) (err error)
+		// RevokeSessionTokens revokes the refresh tokens of a session and the access tokens issued with them
+		RevokeSessionTokens(ctx context.Context, sessionID uuid.UUID) (err error)
+		// RevokeAccessToken adds the jti of an access token to the denylist until it expires
+		RevokeAccessToken(ctx context.Context, id uuid.UUID, expiresAt time.Time) (err error)
+		// IsAccessTokenRevoked tells whether the jti of an access token is in the denylist
+		IsAccessTokenRevoked(ctx context.Context, id uuid.UUID) (result bool, err error)
+		// DeleteExpired removes the refresh tokens and denylist entries that expired before
+		DeleteExpired(ctx context.Context, before time.Time) (err error)
+	} // @name TokenRepository
+)
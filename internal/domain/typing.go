@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// Typing defines the payload of the typing events, both the ones sent by clients and the ones relayed to them
+	Typing struct {
+		ConversationID uuid.UUID `json:"conversation_id" example:"12345678-1234-1234-1234-123456789012"`
+		// PersonnelID is the personnel typing, it is set by the server
+		PersonnelID uuid.UUID `json:"personnel_id,omitempty" example:"12345678-1234-1234-1234-123456789012"`
+	} // @name Typing
+)
+
+type (
+	// TypingService defines the methods that any typing service should implement.
+	// Typing is never persisted, it only lives in the memory of the instance holding the connection of the typist.
+	TypingService interface {
+		// Start tells the other participants of the conversation the personnel is typing, repeated calls refresh it.
+		// Typing stops by itself when it is not refreshed.
+		Start(personnelID, conversationID uuid.UUID) (err error)
+		// Stop tells the other participants of the conversation the personnel stopped typing
+		Stop(personnelID, conversationID uuid.UUID) (err error)
+	}
+)
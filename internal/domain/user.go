@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/gofrs/uuid/v5"
 )
@@ -17,6 +18,8 @@ type (
 		UserName string  `db:"user_name" json:"user_name,omitempty" example:"+919984778491"`
 		Password *string `db:"password" json:"-"`
 		Role     string  `db:"role" json:"role,omitempty"  example:"ADMIN"`
+		// MobileVerifiedAt is when a one time password sent to the user name was first verified
+		MobileVerifiedAt *time.Time `db:"mobile_verified_at" json:"mobile_verified_at,omitempty" example:"2022-02-16 15:35:10.535606+05:30"`
 		BaseAudit
 	} // @name User
 )
@@ -27,8 +30,8 @@ type (
 		FirstName string   `json:"first_name" example:"Mohammad"`
 		LastName  string   `json:"last_name" example:"Rizwan"`
 		UserName  string   `json:"user_name" example:"+919984778491"`
-		Role      UserRole `json:"role" example:"ADMIN"`
-		Password  string   `json:"password" example:"password123"`
+		Role      UserRole `json:"role,omitempty" example:"USER"`
+		Password  string   `json:"password" validate:"required" example:"correct horse battery staple"`
 	} // @name CreateUserInput
 	// UpdateUserInput define the module for the UpdateUserInput
 	UpdateUserInput struct {
@@ -38,11 +41,26 @@ type (
 	LoginInput struct {
 		UserName string `json:"user_name" example:"+919984778491 or example"`
 		Password string `json:"password"`
+		// DeviceName names the session of the login, it is made up from the user agent when left empty
+		DeviceName string `json:"device_name,omitempty" validate:"max=100" example:"Rizwan's phone"`
+		UserAgent  string `json:"-"`
+		IPAddress  string `json:"-"`
 	} // @name LoginInput
 	// LoginOutput define the module for the LoginOutput
 	LoginOutput struct {
-		Token     string `json:"token"`
-		ExpiresIn int64  `json:"expires_in"`
+		Token string `json:"token"`
+		// ExpiresIn is the lifetime of the token in seconds
+		ExpiresIn int64 `json:"expires_in" example:"900"`
+		// RefreshToken is exchanged for a new pair of tokens once the token expires, it can be used only once
+		RefreshToken string `json:"refresh_token"`
+		// RefreshExpiresIn is the lifetime of the refresh token in seconds
+		RefreshExpiresIn int64 `json:"refresh_expires_in" example:"324000"`
+		// MFARequired tells that the user has two-factor authentication, the tokens are then left empty and the
+		// challenge token is exchanged for them with the code of the authenticator
+		MFARequired    bool   `json:"mfa_required,omitempty" example:"false"`
+		ChallengeToken string `json:"challenge_token,omitempty"`
+		// ChallengeExpiresIn is the lifetime of the challenge token in seconds
+		ChallengeExpiresIn int64 `json:"challenge_expires_in,omitempty" example:"300"`
 	} // @name LoginOutput
 )
 
@@ -63,6 +81,10 @@ type (
 		UpdateUser(ctx context.Context, entity *User) (err error)
 		// DeleteUser deletes the user
 		DeleteUser(ctx context.Context, id uuid.UUID) (err error)
+		// UpdatePassword replaces the password hash of the user
+		UpdatePassword(ctx context.Context, id uuid.UUID, hash string) (err error)
+		// MarkMobileVerified records that the user proved to own the mobile number of their user name
+		MarkMobileVerified(ctx context.Context, id uuid.UUID) (err error)
 	} // @name UserRepository
 
 	// UserService defines the methods that any use service should implements
@@ -75,8 +97,41 @@ type (
 		FindByUserName(username string) (result User, err error)
 		// Login return the user by username and password
 		Login(in LoginInput) (result LoginOutput, err error)
-		// UpdateUser updates the user
+		// RefreshToken exchanges a refresh token for a new pair of tokens, presenting a used refresh token
+		// ends its session
+		RefreshToken(in RefreshTokenInput) (result LoginOutput, err error)
+		// Logout revokes the access token and ends its session
+		Logout(tokenID uuid.UUID, expiresAt time.Time) (err error)
+		// IsTokenRevoked tells whether an access token was revoked
+		IsTokenRevoked(tokenID uuid.UUID) (result bool, err error)
+		// RequestOTP sends a one time password to a mobile number, numbers without a user get none but the same answer
+		RequestOTP(in RequestOTPInput) (result RequestOTPOutput, err error)
+		// VerifyOTP logs the user of a mobile number in with a one time password sent to it, which also verifies the number
+		VerifyOTP(in VerifyOTPInput) (result LoginOutput, err error)
+		// VerifyMFA exchanges the challenge token of a login and the code of the authenticator of the user, or a
+		// recovery code, for a new pair of tokens
+		VerifyMFA(in VerifyMFAInput) (result LoginOutput, err error)
+		// EnrollTOTP starts setting up an authenticator for the user, replacing one that is not confirmed yet
+		EnrollTOTP(userID uuid.UUID) (result EnrollTOTPOutput, err error)
+		// ConfirmTOTP turns the authenticator of the user on with one of its codes and returns new recovery codes
+		ConfirmTOTP(userID uuid.UUID, in TOTPCodeInput) (result RecoveryCodesOutput, err error)
+		// DisableTOTP turns the authenticator of the user off with one of its codes or a recovery code
+		DisableTOTP(userID uuid.UUID, in TOTPCodeInput) (err error)
+		// FindSessions returns the active sessions of a user, marking the one of the access token as current
+		FindSessions(userID, tokenID uuid.UUID) (result []Session, err error)
+		// DeleteSession ends a session of the user, revoking its tokens at once
+		DeleteSession(userID, id uuid.UUID) (err error)
+		// UpdateUser updates the user, a new password ends every session of the user
 		UpdateUser(id uuid.UUID, in UpdateUserInput) (result User, err error)
+		// ChangePassword replaces the password of a user once the old one is checked, and ends the sessions of the
+		// user but the one of the access token
+		ChangePassword(userID, tokenID uuid.UUID, in ChangePasswordInput) (err error)
+		// ForgotPassword sends a password reset token to a user through the notifier, user names without a user get
+		// none but the same answer
+		ForgotPassword(in ForgotPasswordInput) (err error)
+		// ResetPassword replaces the password of a user with a reset token, which then stops working, and ends every
+		// session of the user
+		ResetPassword(in ResetPasswordInput) (err error)
 		// DeleteUser deletes the user
 		DeleteUser(id uuid.UUID) (err error)
 	} // @name UserService
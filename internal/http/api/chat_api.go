@@ -1,17 +1,35 @@
 package api
 
 import (
+	"fmt"
+
 	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
 
+	"github.com/chatApp/internal/domain"
 	"github.com/chatApp/internal/http/controller"
 	"github.com/chatApp/internal/pkg/config"
+	"github.com/chatApp/internal/pkg/realtime"
+	"github.com/chatApp/internal/pkg/security"
+	"github.com/chatApp/internal/pkg/worker"
+	"github.com/chatApp/internal/service"
 )
 
 type ChatApi struct {
-	cfg                 config.ChatApiConfig
-	UserController      controller.UserController
-	PersonnelController controller.PersonnelController
+	cfg                    config.ChatApiConfig
+	hub                    *realtime.Hub
+	scm                    security.Manager
+	us                     domain.UserService
+	wp                     *worker.Pool
+	UserController         controller.UserController
+	PersonnelController    controller.PersonnelController
+	MessageController      controller.MessageController
+	ConversationController controller.ConversationController
+	RealtimeController     controller.RealtimeController
+	AttachmentController   controller.AttachmentController
+	PresenceController     controller.PresenceController
+	KeyController          controller.KeyController
 }
 
 // NewChatApi creates a new ChatApi instance
@@ -29,32 +47,122 @@ type ChatApi struct {
 //	@securityDefinitions.apiKey	JWT
 //	@in							header
 //	@name						Authorization
-func NewChatApi(cfg config.ChatApiConfig, pr controller.PersonnelController, uc controller.UserController) *ChatApi {
+func NewChatApi(cfg config.ChatApiConfig, hub *realtime.Hub, pr controller.PersonnelController, uc controller.UserController, mc controller.MessageController, cc controller.ConversationController, rc controller.RealtimeController, ac controller.AttachmentController, prc controller.PresenceController, kc controller.KeyController, scm security.Manager, us domain.UserService, wp *worker.Pool) *ChatApi {
 	return &ChatApi{
-		cfg:                 cfg,
-		UserController:      uc,
-		PersonnelController: pr,
+		cfg:                    cfg,
+		hub:                    hub,
+		scm:                    scm,
+		us:                     us,
+		wp:                     wp,
+		UserController:         uc,
+		PersonnelController:    pr,
+		MessageController:      mc,
+		ConversationController: cc,
+		RealtimeController:     rc,
+		AttachmentController:   ac,
+		PresenceController:     prc,
+		KeyController:          kc,
 	}
 }
 
 func (b ChatApi) SetupRoutes(e *echo.Echo) {
 	apiV1 := e.Group("/api/v1")
 
-	auth := echojwt.JWT([]byte(b.cfg.AuthSecret))
+	// Other services verify tokens with the published keys rather than a shared secret
+	e.GET("/.well-known/jwks.json", b.KeyController.FindJWKS)
+
+	auth := b.requireAuth(echojwt.Config{KeyFunc: b.scm.VerificationKey})
 
 	userApi := apiV1.Group("/users")
 	userApi.POST("", b.UserController.RegisterUser)
 	userApi.POST("/login", b.UserController.Login)
+	userApi.POST("/login/mfa", b.UserController.VerifyMFA)
+	userApi.POST("/token/refresh", b.UserController.RefreshToken)
+	userApi.POST("/otp", b.UserController.RequestOTP)
+	userApi.POST("/otp/verify", b.UserController.VerifyOTP)
+	userApi.POST("/password/forgot", b.UserController.ForgotPassword)
+	userApi.POST("/password/reset", b.UserController.ResetPassword)
 	secureUserApi := apiV1.Group("/users")
 	secureUserApi.Use(auth)
-	secureUserApi.GET("/:id", b.UserController.FindByID)
-	secureUserApi.GET("/:username", b.UserController.FindByUserName)
+	secureUserApi.POST("/logout", b.UserController.Logout)
+	secureUserApi.PUT("/me/password", b.UserController.ChangePassword)
+	secureUserApi.GET("/me/sessions", b.UserController.FindSessions)
+	secureUserApi.DELETE("/me/sessions/:id", b.UserController.DeleteSession)
+	secureUserApi.POST("/me/totp", b.UserController.EnrollTOTP)
+	secureUserApi.POST("/me/totp/confirm", b.UserController.ConfirmTOTP)
+	secureUserApi.DELETE("/me/totp", b.UserController.DisableTOTP)
+	secureUserApi.GET("/:id", b.UserController.FindByID, security.RequirePermission(domain.PermissionUserRead))
+	secureUserApi.GET("/:username", b.UserController.FindByUserName, security.RequirePermission(domain.PermissionUserRead))
+	secureUserApi.GET("/:id/presence", b.PresenceController.FindPresence)
+	secureUserApi.POST("/presence", b.PresenceController.FindPresences)
 
 	personnelApi := apiV1.Group("/personnel")
 	personnelApi.Use(auth)
-	personnelApi.GET("/:id", b.PersonnelController.FindPersonnelByID)
-	personnelApi.POST("/filter", b.PersonnelController.Filter)
-	personnelApi.POST("", b.PersonnelController.CreatePersonnel)
-	personnelApi.PUT("/:id", b.PersonnelController.UpdatePersonnel)
-	personnelApi.DELETE("/:id", b.PersonnelController.DeletePersonnel)
+	// Personnel may change themselves, the permissions of their role allow the rest
+	self := func(p domain.Permission) echo.MiddlewareFunc {
+		return security.RequireSelfOrPermission(p, b.PersonnelController.PersonnelOwner)
+	}
+	personnelApi.GET("/:id", b.PersonnelController.FindPersonnelByID, security.RequirePermission(domain.PermissionPersonnelRead))
+	personnelApi.POST("/filter", b.PersonnelController.Filter, security.RequirePermission(domain.PermissionPersonnelRead))
+	personnelApi.POST("", b.PersonnelController.CreatePersonnel, security.RequirePermission(domain.PermissionPersonnelCreate))
+	personnelApi.PUT("/:id", b.PersonnelController.UpdatePersonnel, self(domain.PermissionPersonnelUpdate))
+	personnelApi.DELETE("/:id", b.PersonnelController.DeletePersonnel, security.RequirePermission(domain.PermissionPersonnelDelete))
+	personnelApi.PUT("/:id/avatar", b.PersonnelController.UpdateAvatar, self(domain.PermissionPersonnelUpdate))
+	personnelApi.DELETE("/:id/avatar", b.PersonnelController.DeleteAvatar, self(domain.PermissionPersonnelUpdate))
+
+	// Avatars are loaded by image tags that cannot send the token, their URLs hold an unguessable version instead
+	apiV1.GET("/avatars/:id/:version", b.PersonnelController.FindAvatar)
+
+	messageApi := apiV1.Group("/messages")
+	messageApi.Use(auth)
+	messageApi.POST("", b.MessageController.SendMessage)
+	messageApi.GET("/sent", b.MessageController.FindSentMessages)
+	messageApi.GET("/search", b.MessageController.SearchMessages)
+	messageApi.GET("/:id", b.MessageController.FindMessageByID)
+	messageApi.PUT("/:id", b.MessageController.UpdateMessage)
+	messageApi.PUT("/:id/status", b.MessageController.UpdateMessageStatus)
+	messageApi.GET("/:id/receipts", b.MessageController.FindMessageReceipts)
+	messageApi.GET("/:id/edits", b.MessageController.FindMessageEdits)
+	messageApi.GET("/:id/replies", b.MessageController.FindMessageReplies)
+	messageApi.GET("/:id/reactions", b.MessageController.FindMessageReactions)
+	messageApi.POST("/:id/reactions", b.MessageController.AddReaction)
+	messageApi.DELETE("/:id/reactions/:emoji", b.MessageController.RemoveReaction)
+	messageApi.DELETE("/:id", b.MessageController.DeleteMessage)
+
+	conversationApi := apiV1.Group("/conversations")
+	conversationApi.Use(auth)
+	conversationApi.GET("", b.ConversationController.FindMyConversations)
+	conversationApi.POST("/direct", b.ConversationController.CreateDirectConversation)
+	conversationApi.POST("/group", b.ConversationController.CreateGroupConversation)
+	conversationApi.POST("/join", b.ConversationController.JoinConversation)
+	// attachments bypass the global body limit for their own, leaving room for the multipart envelope
+	attachmentLimit := echomiddleware.BodyLimit(fmt.Sprintf("%dK", (service.AttachmentMaxSize(b.cfg)>>10)+1024))
+	conversationApi.POST("/:id/attachments", b.AttachmentController.UploadAttachment, attachmentLimit)
+	conversationApi.GET("/:id", b.ConversationController.FindConversationByID)
+	conversationApi.GET("/:id/messages", b.MessageController.FindConversationMessages)
+	conversationApi.POST("/:id/read", b.MessageController.MarkConversationRead)
+	conversationApi.GET("/:id/participants", b.ConversationController.FindParticipants)
+	conversationApi.POST("/:id/participants", b.ConversationController.AddParticipants)
+	conversationApi.DELETE("/:id/participants/:personnelId", b.ConversationController.RemoveParticipant)
+	conversationApi.PUT("/:id/participants/:personnelId/role", b.ConversationController.UpdateParticipantRole)
+	conversationApi.PUT("/:id/participants/:personnelId/mute", b.ConversationController.MuteParticipant)
+	conversationApi.GET("/:id/invites", b.ConversationController.FindInvites)
+	conversationApi.POST("/:id/invites", b.ConversationController.CreateInvite)
+	conversationApi.DELETE("/:id/invites/:inviteId", b.ConversationController.RevokeInvite)
+
+	attachmentApi := apiV1.Group("/attachments")
+	attachmentApi.Use(auth)
+	attachmentApi.GET("/:id", b.AttachmentController.DownloadAttachment)
+	attachmentApi.GET("/:id/thumbnails/:size", b.AttachmentController.DownloadAttachmentThumbnail)
+
+	// Browsers cannot set headers on websocket requests, so the token may also come from the query
+	wsAuth := b.requireAuth(echojwt.Config{
+		KeyFunc:     b.scm.VerificationKey,
+		TokenLookup: "header:Authorization:Bearer ,query:token",
+	})
+	apiV1.GET("/ws", b.RealtimeController.Connect, wsAuth)
+	// Hijacked websocket connections are not closed by e.Shutdown, so close them with the server
+	e.Server.RegisterOnShutdown(b.hub.Close)
+	// Let background tasks such as thumbnails stop before the process exits
+	e.Server.RegisterOnShutdown(b.wp.Close)
 }
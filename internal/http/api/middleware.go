@@ -2,19 +2,47 @@ package api
 
 import (
 	"fmt"
+	"log"
+	"net"
 	"net/http"
 	"strings"
 
 	"github.com/go-playground/validator"
 	"github.com/jackc/pgx/v5/pgconn"
+	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
 	echomiddleware "github.com/labstack/echo/v4/middleware"
 
 	"github.com/chatApp/internal/domain"
 	"github.com/chatApp/internal/http/swagger"
 	"github.com/chatApp/internal/http/transport"
+	"github.com/chatApp/internal/pkg/security"
 )
 
+// ipExtractor returns the echo.IPExtractor of the client address, it reads X-Forwarded-For only behind the proxies
+func ipExtractor(proxies []string) echo.IPExtractor {
+	if len(proxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			log.Fatalf("invalid TRUSTED_PROXIES entry %q: %v", proxy, err)
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
 // SetupMiddleware sets up middleware for the echo server
 func (b ChatApi) SetupMiddleware(e *echo.Echo) {
 	// Set up the validator middleware
@@ -25,8 +53,16 @@ func (b ChatApi) SetupMiddleware(e *echo.Echo) {
 	e.Validator = &transport.CustomValidator{Validator: vv10}
 	// Set up the error handler middleware
 	e.HTTPErrorHandler = errorMiddleware
-	// Set the request body limit to 10M
-	e.Use(echomiddleware.BodyLimit("10M"))
+	// Client addresses count towards the login limits and are recorded on sessions, so forwarding headers are only
+	// believed when a trusted proxy sets them
+	e.IPExtractor = ipExtractor(b.cfg.TrustedProxies)
+	// Set the request body limit to 10M, attachment uploads have their own limit set on their route
+	e.Use(echomiddleware.BodyLimitWithConfig(echomiddleware.BodyLimitConfig{
+		Limit: "10M",
+		Skipper: func(c echo.Context) bool {
+			return c.Request().Method == http.MethodPost && strings.HasSuffix(c.Path(), "/attachments")
+		},
+	}))
 	// Recovery middleware recovers from panics anywhere in the chain,
 	e.Use(echomiddleware.Recover())
 	// Add request ID middleware
@@ -45,6 +81,27 @@ func (b ChatApi) SetupMiddleware(e *echo.Echo) {
 	)
 }
 
+// requireAuth verifies the auth token as configured and rejects tokens without a jti or revoked before they expire
+func (b ChatApi) requireAuth(config echojwt.Config) echo.MiddlewareFunc {
+	verify := echojwt.WithConfig(config)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return verify(func(c echo.Context) error {
+			tokenID, _, err := security.GetTokenIDForContext(c)
+			if err != nil {
+				return err
+			}
+			revoked, err := b.us.IsTokenRevoked(tokenID)
+			if err != nil {
+				return err
+			}
+			if revoked {
+				return domain.UnauthorizedError{}
+			}
+			return next(c)
+		})
+	}
+}
+
 // errorMiddleware absorbs and processes all errors
 func errorMiddleware(err error, c echo.Context) {
 	switch err.(type) {
@@ -75,7 +132,7 @@ func errorMiddleware(err error, c echo.Context) {
 		errs := err.(validator.ValidationErrors)
 
 		for _, e := range errs {
-			if e.Tag() == "required" {
+			if e.Tag() == "required" || e.Tag() == "required_without" {
 
 				fields = append(fields, fmt.Sprintf("%s is required", e.Field()))
 				continue
@@ -96,6 +153,11 @@ func errorMiddleware(err error, c echo.Context) {
 				continue
 			}
 
+			if e.Tag() == "min" {
+				fields = append(fields, fmt.Sprintf("%s must have at least %s items or characters", e.Field(), e.Param()))
+				continue
+			}
+
 			if e.Tag() == "max" {
 				fields = append(fields, fmt.Sprintf("%s must not exceed %s characters", e.Field(), e.Param()))
 				continue
@@ -110,6 +172,9 @@ func errorMiddleware(err error, c echo.Context) {
 		}
 		_ = c.JSON(http.StatusBadRequest, ve)
 
+	case domain.ValidationError:
+		_ = c.JSON(http.StatusBadRequest, err)
+
 	case *pgconn.PgError:
 		res := domain.SystemError{
 			Code:    domain.ErrorCodeINTERNAL_SERVER_ERROR,
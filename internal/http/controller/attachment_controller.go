@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/labstack/echo/v4"
+
+	"github.com/chatApp/internal/domain"
+	"github.com/chatApp/internal/http/transport"
+)
+
+type AttachmentController struct {
+	as domain.AttachmentService
+	ps domain.PersonnelService
+}
+
+func NewAttachmentController(as domain.AttachmentService, ps domain.PersonnelService) AttachmentController {
+	return AttachmentController{as: as, ps: ps}
+}
+
+// UploadAttachment uploads a file to a conversation.
+//
+//	@Summary		Upload an attachment
+//	@Description	Upload a file to a conversation the authenticated user participates in, it is sent by listing its ID in the attachment_ids of a message
+//	@Tags			Attachment
+//	@ID				uploadAttachment
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"Conversation ID"
+//	@Param			file			formData	file	true	"File to upload"
+//	@Success		201				{object}	domain.BaseResponse{data=domain.Attachment}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/conversations/{id}/attachments [post]
+func (c AttachmentController) UploadAttachment(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// get file from multipart form
+	fh, err := ctx.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+	}
+	file, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	in := domain.UploadAttachmentInput{
+		ConversationID: id,
+		FileName:       fh.Filename,
+		Size:           fh.Size,
+		Content:        file,
+	}
+	// call service
+	result, err := c.as.Upload(personnelID, in)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusCreated, result)
+}
+
+// DownloadAttachment downloads an attachment.
+//
+//	@Summary		Download an attachment
+//	@Description	Download an attachment of a conversation the authenticated user participates in
+//	@Tags			Attachment
+//	@ID				downloadAttachment
+//	@Produce		octet-stream
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"Attachment ID"
+//	@Success		200				{file}		file
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/attachments/{id} [get]
+func (c AttachmentController) DownloadAttachment(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// call service
+	result, content, err := c.as.Download(personnelID, id)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	// return result
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": result.FileName}))
+	ctx.Response().Header().Set(echo.HeaderContentLength, strconv.FormatInt(result.Size, 10))
+	ctx.Response().Header().Set("X-Content-Type-Options", "nosniff")
+	return ctx.Stream(http.StatusOK, result.ContentType, content)
+}
+
+// DownloadAttachmentThumbnail downloads a thumbnail of an image attachment.
+//
+//	@Summary		Download an attachment thumbnail
+//	@Description	Download a scaled down copy of an image attachment, the sizes available are listed in the thumbnails of the attachment
+//	@Tags			Attachment
+//	@ID				downloadAttachmentThumbnail
+//	@Produce		octet-stream
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"Attachment ID"
+//	@Param			size			path		int		true	"Thumbnail size"
+//	@Success		200				{file}		file
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/attachments/{id}/thumbnails/{size} [get]
+func (c AttachmentController) DownloadAttachmentThumbnail(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get id and size from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	size, err := strconv.Atoi(ctx.Param("size"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "size must be a number")
+	}
+	// call service
+	result, content, err := c.as.DownloadThumbnail(personnelID, id, size)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	// return result
+	ctx.Response().Header().Set("X-Content-Type-Options", "nosniff")
+	return ctx.Stream(http.StatusOK, result.ContentType, content)
+}
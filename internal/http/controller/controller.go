@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+
+	"github.com/chatApp/internal/domain"
+	"github.com/chatApp/internal/pkg/security"
+)
+
+// getPersonnelIDForContext resolves the personnel of the user the auth token was issued to
+func getPersonnelIDForContext(ctx echo.Context, ps domain.PersonnelService) (uuid.UUID, error) {
+	userID, err := security.GetUserIDForContext(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	personnel, err := ps.FindByUserID(userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, domain.UnauthorizedError{}
+		}
+		return uuid.Nil, err
+	}
+	return personnel.ID, nil
+}
@@ -0,0 +1,528 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/labstack/echo/v4"
+
+	"github.com/chatApp/internal/domain"
+	"github.com/chatApp/internal/http/transport"
+)
+
+type ConversationController struct {
+	cs domain.ConversationService
+	ps domain.PersonnelService
+}
+
+func NewConversationController(cs domain.ConversationService, ps domain.PersonnelService) ConversationController {
+	return ConversationController{cs: cs, ps: ps}
+}
+
+// FindMyConversations lists the conversations of the authenticated user.
+//
+//	@Summary		List my conversations
+//	@Description	List the conversations of the authenticated user with their latest message and unread count
+//	@Tags			Conversation
+//	@ID				findMyConversations
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Success		200				{object}	domain.BaseResponse{data=[]domain.ConversationSummary}
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/conversations [get]
+func (c ConversationController) FindMyConversations(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.cs.FindByParticipant(personnelID)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// FindConversationByID finds a conversation by ID.
+//
+//	@Summary		Find conversation by ID
+//	@Description	Find a conversation the authenticated user participates in
+//	@Tags			Conversation
+//	@ID				findConversationByID
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"Conversation ID"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.Conversation}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/conversations/{id} [get]
+func (c ConversationController) FindConversationByID(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.cs.FindByID(personnelID, id)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// CreateDirectConversation opens the direct conversation with another personnel.
+//
+//	@Summary		Open a direct conversation
+//	@Description	Return the direct conversation between the authenticated user and another personnel, creating it if needed
+//	@Tags			Conversation
+//	@ID				createDirectConversation
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string									true	"Bearer "
+//	@Param			body			body		domain.CreateDirectConversationInput	true	"Direct conversation input"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.Conversation}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/conversations/direct [post]
+func (c ConversationController) CreateDirectConversation(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get input from request body
+	var in domain.CreateDirectConversationInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.cs.CreateDirect(personnelID, in)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// CreateGroupConversation creates a named group conversation.
+//
+//	@Summary		Create a group conversation
+//	@Description	Create a named group conversation with the authenticated user as a participant
+//	@Tags			Conversation
+//	@ID				createGroupConversation
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string								true	"Bearer "
+//	@Param			body			body		domain.CreateGroupConversationInput	true	"Group conversation input"
+//	@Success		201				{object}	domain.BaseResponse{data=domain.Conversation}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/conversations/group [post]
+func (c ConversationController) CreateGroupConversation(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get input from request body
+	var in domain.CreateGroupConversationInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.cs.CreateGroup(personnelID, in)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusCreated, result)
+}
+
+// FindParticipants lists the participants of a conversation.
+//
+//	@Summary		List conversation participants
+//	@Description	List the participants of a conversation the authenticated user participates in
+//	@Tags			Conversation
+//	@ID				findConversationParticipants
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"Conversation ID"
+//	@Success		200				{object}	domain.BaseResponse{data=[]domain.ConversationParticipant}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/conversations/{id}/participants [get]
+func (c ConversationController) FindParticipants(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.cs.FindParticipants(personnelID, id)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// AddParticipants adds participants to a group conversation.
+//
+//	@Summary		Add conversation participants
+//	@Description	Add participants to a group conversation the authenticated user participates in
+//	@Tags			Conversation
+//	@ID				addConversationParticipants
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string						true	"Bearer "
+//	@Param			id				path		string						true	"Conversation ID"
+//	@Param			body			body		domain.AddParticipantsInput	true	"Participants input"
+//	@Success		200				{object}	domain.BaseResponse{data=[]domain.ConversationParticipant}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/conversations/{id}/participants [post]
+func (c ConversationController) AddParticipants(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// get input from request body
+	var in domain.AddParticipantsInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.cs.AddParticipants(personnelID, id, in)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// RemoveParticipant removes a participant from a group conversation.
+//
+//	@Summary		Remove a conversation participant
+//	@Description	Leave a group conversation, or remove another participant from it
+//	@Tags			Conversation
+//	@ID				removeConversationParticipant
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"Conversation ID"
+//	@Param			personnelId		path		string	true	"Personnel ID"
+//	@Success		204				{object}	nil
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/conversations/{id}/participants/{personnelId} [delete]
+func (c ConversationController) RemoveParticipant(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get ids from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	participantID, err := uuid.FromString(ctx.Param("personnelId"))
+	if err != nil {
+		return err
+	}
+	// call service
+	err = c.cs.RemoveParticipant(personnelID, id, participantID)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusNoContent, nil)
+}
+
+// UpdateParticipantRole promotes or demotes a participant of a group conversation.
+//
+//	@Summary		Change a participant role
+//	@Description	Promote a participant to admin or demote them to member, only owners and admins may do so
+//	@Tags			Conversation
+//	@ID				updateConversationParticipantRole
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string								true	"Bearer "
+//	@Param			id				path		string								true	"Conversation ID"
+//	@Param			personnelId		path		string								true	"Personnel ID"
+//	@Param			body			body		domain.UpdateParticipantRoleInput	true	"Role input"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.ConversationParticipant}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/conversations/{id}/participants/{personnelId}/role [put]
+func (c ConversationController) UpdateParticipantRole(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get ids from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	participantID, err := uuid.FromString(ctx.Param("personnelId"))
+	if err != nil {
+		return err
+	}
+	// get input from request body
+	var in domain.UpdateParticipantRoleInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.cs.UpdateParticipantRole(personnelID, id, participantID, in)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// MuteParticipant mutes a participant of a group conversation.
+//
+//	@Summary		Mute a participant
+//	@Description	Stop a participant from sending messages for a number of hours, a duration of 0 lifts the mute
+//	@Tags			Conversation
+//	@ID				muteConversationParticipant
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string						true	"Bearer "
+//	@Param			id				path		string						true	"Conversation ID"
+//	@Param			personnelId		path		string						true	"Personnel ID"
+//	@Param			body			body		domain.MuteParticipantInput	true	"Mute input"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.ConversationParticipant}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/conversations/{id}/participants/{personnelId}/mute [put]
+func (c ConversationController) MuteParticipant(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get ids from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	participantID, err := uuid.FromString(ctx.Param("personnelId"))
+	if err != nil {
+		return err
+	}
+	// get input from request body
+	var in domain.MuteParticipantInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.cs.MuteParticipant(personnelID, id, participantID, in)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// CreateInvite creates an invite link to a group conversation.
+//
+//	@Summary		Create an invite
+//	@Description	Create an expiring invite token to a group conversation, only owners and admins may do so
+//	@Tags			Conversation
+//	@ID				createConversationInvite
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string						true	"Bearer "
+//	@Param			id				path		string						true	"Conversation ID"
+//	@Param			body			body		domain.CreateInviteInput	true	"Invite input"
+//	@Success		201				{object}	domain.BaseResponse{data=domain.ConversationInvite}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/conversations/{id}/invites [post]
+func (c ConversationController) CreateInvite(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// get input from request body
+	var in domain.CreateInviteInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.cs.CreateInvite(personnelID, id, in)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusCreated, result)
+}
+
+// FindInvites lists the active invites of a group conversation.
+//
+//	@Summary		List invites
+//	@Description	List the invites of a group conversation that have not expired, only owners and admins may do so
+//	@Tags			Conversation
+//	@ID				findConversationInvites
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"Conversation ID"
+//	@Success		200				{object}	domain.BaseResponse{data=[]domain.ConversationInvite}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/conversations/{id}/invites [get]
+func (c ConversationController) FindInvites(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.cs.FindInvites(personnelID, id)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// RevokeInvite revokes an invite of a group conversation.
+//
+//	@Summary		Revoke an invite
+//	@Description	Revoke an invite of a group conversation, only owners and admins may do so
+//	@Tags			Conversation
+//	@ID				revokeConversationInvite
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"Conversation ID"
+//	@Param			inviteId		path		string	true	"Invite ID"
+//	@Success		204				{object}	nil
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/conversations/{id}/invites/{inviteId} [delete]
+func (c ConversationController) RevokeInvite(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get ids from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	inviteID, err := uuid.FromString(ctx.Param("inviteId"))
+	if err != nil {
+		return err
+	}
+	// call service
+	err = c.cs.RevokeInvite(personnelID, id, inviteID)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusNoContent, nil)
+}
+
+// JoinConversation joins a group conversation with an invite token.
+//
+//	@Summary		Join with an invite
+//	@Description	Join the group conversation of an invite token as a member
+//	@Tags			Conversation
+//	@ID				joinConversation
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string							true	"Bearer "
+//	@Param			body			body		domain.JoinConversationInput	true	"Join input"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.Conversation}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/conversations/join [post]
+func (c ConversationController) JoinConversation(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get input from request body
+	var in domain.JoinConversationInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.cs.Join(personnelID, in)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/chatApp/internal/pkg/security"
+)
+
+type KeyController struct {
+	scm security.Manager
+}
+
+func NewKeyController(scm security.Manager) KeyController {
+	return KeyController{scm: scm}
+}
+
+// FindJWKS returns the public keys auth tokens are verified with.
+//
+//	@Summary		JSON Web Key Set
+//	@Description	Get the public keys auth tokens are signed with as a JSON Web Key Set (RFC 7517), a token names its key in the kid header. Served at /.well-known/jwks.json, outside of the API base path.
+//	@Tags			Auth
+//	@ID				findJWKS
+//	@Produce		json
+//	@Success		200	{object}	security.JWKS
+//	@Router			/.well-known/jwks.json [get]
+func (c KeyController) FindJWKS(ctx echo.Context) error {
+	// verifiers refetch the set for unknown kids, so a short cache still picks up new keys quickly
+	ctx.Response().Header().Set("Cache-Control", "public, max-age=300")
+	// the key set is a standard document rather than an API response, so it is not wrapped in data
+	return ctx.JSON(http.StatusOK, c.scm.JWKS())
+}
@@ -1,4 +1,625 @@
 package controller
 
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/labstack/echo/v4"
+
+	"github.com/chatApp/internal/domain"
+	"github.com/chatApp/internal/http/transport"
+)
+
 type MessageController struct {
+	ms domain.MessageService
+	ps domain.PersonnelService
+}
+
+func NewMessageController(ms domain.MessageService, ps domain.PersonnelService) MessageController {
+	return MessageController{ms: ms, ps: ps}
+}
+
+// SendMessage sends a new message from the authenticated user.
+//
+//	@Summary		Send a message
+//	@Description	Send a message to a conversation, the sender is taken from the auth token
+//	@Tags			Message
+//	@ID				sendMessage
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string						true	"Bearer "
+//	@Param			body			body		domain.CreateMessageInput	true	"Message input"
+//	@Success		201				{object}	domain.BaseResponse{data=domain.Message}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/messages [post]
+func (c MessageController) SendMessage(ctx echo.Context) error {
+	// resolve the sender from the auth token
+	senderID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get input from request body
+	var in domain.CreateMessageInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	in.SenderID = senderID
+	// call service
+	result, err := c.ms.Create(in)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusCreated, result)
+}
+
+// FindMessageByID finds a message by ID.
+//
+//	@Summary		Find message by ID
+//	@Description	Find a message of a conversation the authenticated user participates in
+//	@Tags			Message
+//	@ID				findMessageByID
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"Message ID"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.Message}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/messages/{id} [get]
+func (c MessageController) FindMessageByID(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.ms.FindByID(personnelID, id)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// FindSentMessages lists the messages sent by the authenticated user.
+//
+//	@Summary		List sent messages
+//	@Description	List a page of the messages sent by the authenticated user, oldest first.
+//	@Description	Without a cursor the latest messages are returned.
+//	@Tags			Message
+//	@ID				findSentMessages
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			before			query		string	false	"Cursor of the page to return the older messages of"
+//	@Param			after			query		string	false	"Cursor of the page to return the newer messages of"
+//	@Param			size			query		int		false	"Number of messages, 50 by default"
+//	@Success		200				{object}	domain.CursorPaginationResponse{data=[]domain.Message}
+//	@Failure		400				{object}	domain.UserError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/messages/sent [get]
+func (c MessageController) FindSentMessages(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get cursor from query
+	q, err := transport.DecodeCursorQuery(ctx)
+	if err != nil {
+		return err
+	}
+	// call service
+	result, page, err := c.ms.FindBySenderID(personnelID, q)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendCursorPaginationResponse(ctx, http.StatusOK, result, page, q.Limit)
+}
+
+// SearchMessages searches the messages of the conversations of the authenticated user.
+//
+//	@Summary		Search messages
+//	@Description	Full-text search of the messages of the conversations the authenticated user participates in, most relevant first.
+//	@Description	Each result carries an HTML escaped snippet with the matching words wrapped in <mark> tags.
+//	@Tags			Message
+//	@ID				searchMessages
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			q				query		string	true	"Words to search for, quoted phrases, or and -excluded words are supported"
+//	@Param			sender_id		query		string	false	"Only messages sent by this personnel"
+//	@Param			conversation_id	query		string	false	"Only messages of this conversation"
+//	@Param			from			query		string	false	"Only messages sent at or after this RFC 3339 time"
+//	@Param			to				query		string	false	"Only messages sent at or before this RFC 3339 time"
+//	@Param			after			query		string	false	"Cursor of the page to return the following results of"
+//	@Param			size			query		int		false	"Number of results, 50 by default"
+//	@Success		200				{object}	domain.CursorPaginationResponse{data=[]domain.MessageSearchResult}
+//	@Failure		400				{object}	domain.UserError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/messages/search [get]
+func (c MessageController) SearchMessages(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get search from query
+	in, err := transport.DecodeSearchQuery(ctx)
+	if err != nil {
+		return err
+	}
+	// call service
+	result, next, err := c.ms.Search(personnelID, in)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendSearchPaginationResponse(ctx, http.StatusOK, result, next, in.Limit)
+}
+
+// FindConversationMessages lists the messages of a conversation.
+//
+//	@Summary		List conversation messages
+//	@Description	List a page of the messages of a conversation the authenticated user participates in, oldest first.
+//	@Description	Without a cursor the latest messages are returned.
+//	@Tags			Message
+//	@ID				findConversationMessages
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"Conversation ID"
+//	@Param			before			query		string	false	"Cursor of the page to return the older messages of"
+//	@Param			after			query		string	false	"Cursor of the page to return the newer messages of"
+//	@Param			size			query		int		false	"Number of messages, 50 by default"
+//	@Success		200				{object}	domain.CursorPaginationResponse{data=[]domain.Message}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/conversations/{id}/messages [get]
+func (c MessageController) FindConversationMessages(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// get cursor from query
+	q, err := transport.DecodeCursorQuery(ctx)
+	if err != nil {
+		return err
+	}
+	// call service
+	result, page, err := c.ms.FindByConversationID(personnelID, id, q)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendCursorPaginationResponse(ctx, http.StatusOK, result, page, q.Limit)
+}
+
+// UpdateMessage edits the content of a message.
+//
+//	@Summary		Edit a message
+//	@Description	Edit the content of a message sent by the authenticated user, the prior content is kept in its edit history
+//	@Tags			Message
+//	@ID				updateMessage
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string						true	"Bearer "
+//	@Param			id				path		string						true	"Message ID"
+//	@Param			body			body		domain.UpdateMessageInput	true	"Message update input"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.Message}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/messages/{id} [put]
+func (c MessageController) UpdateMessage(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// get input from request body
+	var in domain.UpdateMessageInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.ms.Update(personnelID, id, in)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// UpdateMessageStatus updates the delivery status of a message.
+//
+//	@Summary		Update message status
+//	@Description	Mark a message sent by another participant as delivered or read
+//	@Tags			Message
+//	@ID				updateMessageStatus
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string							true	"Bearer "
+//	@Param			id				path		string							true	"Message ID"
+//	@Param			body			body		domain.UpdateMessageStatusInput	true	"Message status input"
+//	@Success		204				{object}	nil
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/messages/{id}/status [put]
+func (c MessageController) UpdateMessageStatus(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// get input from request body
+	var in domain.UpdateMessageStatusInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	in.MessageID = id
+	// call service
+	err = c.ms.UpdateMessageStatus(personnelID, in)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusNoContent, nil)
+}
+
+// MarkConversationRead marks the messages of a conversation as read.
+//
+//	@Summary		Mark a conversation read
+//	@Description	Mark every message of a conversation up to the given message as read by the authenticated user
+//	@Tags			Message
+//	@ID				markConversationRead
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string								true	"Bearer "
+//	@Param			id				path		string								true	"Conversation ID"
+//	@Param			body			body		domain.MarkConversationReadInput	true	"Read marker input"
+//	@Success		204				{object}	nil
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/conversations/{id}/read [post]
+func (c MessageController) MarkConversationRead(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// get input from request body
+	var in domain.MarkConversationReadInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	in.ConversationID = id
+	// call service
+	err = c.ms.MarkConversationRead(personnelID, in)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusNoContent, nil)
+}
+
+// FindMessageReceipts lists the receipts of a message.
+//
+//	@Summary		List message receipts
+//	@Description	List the delivery and read status of a message for each of its recipients
+//	@Tags			Message
+//	@ID				findMessageReceipts
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"Message ID"
+//	@Success		200				{object}	domain.BaseResponse{data=[]domain.MessageStatus}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/messages/{id}/receipts [get]
+func (c MessageController) FindMessageReceipts(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.ms.FindReceipts(personnelID, id)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// FindMessageReplies lists the replies in the thread of a message.
+//
+//	@Summary		List thread replies
+//	@Description	List a page of the replies in the thread of a message, oldest first. A reply lists the thread it belongs to.
+//	@Description	Without a cursor the latest replies are returned.
+//	@Tags			Message
+//	@ID				findMessageReplies
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"Message ID"
+//	@Param			before			query		string	false	"Cursor of the page to return the older replies of"
+//	@Param			after			query		string	false	"Cursor of the page to return the newer replies of"
+//	@Param			size			query		int		false	"Number of replies, 50 by default"
+//	@Success		200				{object}	domain.CursorPaginationResponse{data=[]domain.Message}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/messages/{id}/replies [get]
+func (c MessageController) FindMessageReplies(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// get cursor from query
+	q, err := transport.DecodeCursorQuery(ctx)
+	if err != nil {
+		return err
+	}
+	// call service
+	result, page, err := c.ms.FindReplies(personnelID, id, q)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendCursorPaginationResponse(ctx, http.StatusOK, result, page, q.Limit)
+}
+
+// AddReaction reacts to a message.
+//
+//	@Summary		React to a message
+//	@Description	React to a message with an emoji, reacting twice with the same emoji has no effect
+//	@Tags			Message
+//	@ID				addMessageReaction
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string					true	"Bearer "
+//	@Param			id				path		string					true	"Message ID"
+//	@Param			body			body		domain.AddReactionInput	true	"Reaction input"
+//	@Success		200				{object}	domain.BaseResponse{data=[]domain.ReactionSummary}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/messages/{id}/reactions [post]
+func (c MessageController) AddReaction(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// get input from request body
+	var in domain.AddReactionInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.ms.AddReaction(personnelID, id, in)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// RemoveReaction takes a reaction to a message back.
+//
+//	@Summary		Remove a reaction
+//	@Description	Take back the reaction of the authenticated user to a message
+//	@Tags			Message
+//	@ID				removeMessageReaction
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"Message ID"
+//	@Param			emoji			path		string	true	"Emoji, url encoded"
+//	@Success		200				{object}	domain.BaseResponse{data=[]domain.ReactionSummary}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/messages/{id}/reactions/{emoji} [delete]
+func (c MessageController) RemoveReaction(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get id and emoji from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	emoji, err := url.PathUnescape(ctx.Param("emoji"))
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.ms.RemoveReaction(personnelID, id, emoji)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// FindMessageReactions lists the reactions to a message.
+//
+//	@Summary		List message reactions
+//	@Description	List the reactions to a message aggregated per emoji
+//	@Tags			Message
+//	@ID				findMessageReactions
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"Message ID"
+//	@Success		200				{object}	domain.BaseResponse{data=[]domain.ReactionSummary}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/messages/{id}/reactions [get]
+func (c MessageController) FindMessageReactions(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.ms.FindReactions(personnelID, id)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// FindMessageEdits lists the revision history of a message.
+//
+//	@Summary		List message edits
+//	@Description	List the prior versions of the content of a message, oldest first
+//	@Tags			Message
+//	@ID				findMessageEdits
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"Message ID"
+//	@Success		200				{object}	domain.BaseResponse{data=[]domain.MessageEdit}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/messages/{id}/edits [get]
+func (c MessageController) FindMessageEdits(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.ms.FindEdits(personnelID, id)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// DeleteMessage deletes a message.
+//
+//	@Summary		Delete a message
+//	@Description	Delete a message sent by the authenticated user
+//	@Tags			Message
+//	@ID				deleteMessage
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"Message ID"
+//	@Success		204				{object}	nil
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/messages/{id} [delete]
+func (c MessageController) DeleteMessage(ctx echo.Context) error {
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// call service
+	err = c.ms.Delete(personnelID, id)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusNoContent, nil)
 }
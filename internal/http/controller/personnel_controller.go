@@ -1,13 +1,17 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
 	"github.com/labstack/echo/v4"
 
 	"github.com/chatApp/internal/domain"
 	"github.com/chatApp/internal/http/transport"
+	"github.com/chatApp/internal/pkg/security"
 )
 
 type PersonnelController struct {
@@ -18,6 +22,23 @@ func NewPersonnelController(ps domain.PersonnelService) PersonnelController {
 	return PersonnelController{ps: ps}
 }
 
+// PersonnelOwner returns the user of the personnel in the id path param, it is the security.OwnerFunc of the routes
+// personnel may use on themselves
+func (c PersonnelController) PersonnelOwner(ctx echo.Context) (uuid.UUID, error) {
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	personnel, err := c.ps.FindByID(id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, domain.DataNotFoundError{}
+		}
+		return uuid.Nil, err
+	}
+	return personnel.UserID, nil
+}
+
 // FindPersonnelByID finds personnel by ID.
 //
 //	@Summary		Find personnel by ID
@@ -85,7 +106,7 @@ func (c PersonnelController) Filter(ctx echo.Context) error {
 // CreatePersonnel creates a new personnel entry.
 //
 //	@Summary		Create new personnel
-//	@Description	Create a new personnel record
+//	@Description	Create a new personnel record, only ADMIN users may create personnel
 //	@Tags			Personnel
 //	@ID				createPersonnel
 //	@Accept			json
@@ -96,6 +117,7 @@ func (c PersonnelController) Filter(ctx echo.Context) error {
 //	@Success		201				{object}	domain.BaseResponse{data=domain.Personnel}
 //	@Failure		400				{object}	domain.InvalidRequestError
 //	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
 //	@Failure		500				{object}	domain.SystemError
 //	@Router			/personnel [post]
 func (c PersonnelController) CreatePersonnel(ctx echo.Context) error {
@@ -115,7 +137,7 @@ func (c PersonnelController) CreatePersonnel(ctx echo.Context) error {
 // UpdatePersonnel updates an existing personnel record.
 //
 //	@Summary		Update personnel
-//	@Description	Update personnel based on the provided ID and input
+//	@Description	Update personnel based on the provided ID and input. Users may update their own personnel, ADMIN users any, and only ADMIN users may change the role and activation status
 //	@Tags			Personnel
 //	@ID				updatePersonnel
 //	@Accept			json
@@ -139,6 +161,10 @@ func (c PersonnelController) UpdatePersonnel(ctx echo.Context) error {
 	//  get input from request body
 	var in domain.UpdatePersonnelInput
 	transport.DecodeAndValidateRequestBody(ctx, &in)
+	// personnel may update themselves, but not their role and activation status
+	if (in.Role != "" || in.ActivationStatus != "") && !security.HasPermission(ctx, domain.PermissionPersonnelManage) {
+		return domain.ForbiddenAccessError{}
+	}
 	//  call service
 	result, err := c.ps.Update(id, in)
 	if err != nil {
@@ -151,7 +177,7 @@ func (c PersonnelController) UpdatePersonnel(ctx echo.Context) error {
 // DeletePersonnel deletes personnel based on the provided ID.
 //
 //	@Summary		Delete personnel
-//	@Description	Delete personnel using the provided ID
+//	@Description	Delete personnel using the provided ID, only ADMIN users may delete personnel
 //	@Tags			Personnel
 //	@ID				deletePersonnel
 //	@Accept			json
@@ -162,6 +188,7 @@ func (c PersonnelController) UpdatePersonnel(ctx echo.Context) error {
 //	@Success		204				{object}	nil
 //	@Failure		400				{object}	domain.InvalidRequestError
 //	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
 //	@Failure		500				{object}	domain.SystemError
 //	@Router			/personnel/{id} [delete]
 func (c PersonnelController) DeletePersonnel(ctx echo.Context) error {
@@ -178,3 +205,113 @@ func (c PersonnelController) DeletePersonnel(ctx echo.Context) error {
 	//  return result
 	return transport.SendResponse(ctx, http.StatusNoContent, nil)
 }
+
+// UpdateAvatar uploads the avatar of a personnel.
+//
+//	@Summary		Upload avatar
+//	@Description	Replace the avatar of a personnel with an uploaded image, it is cropped to a square and stored in several sizes. Only the personnel and ADMIN users may change it
+//	@Tags			Personnel
+//	@ID				updatePersonnelAvatar
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"Personnel ID"
+//	@Param			file			formData	file	true	"JPEG, PNG, GIF, WebP or BMP image"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.Personnel}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/personnel/{id}/avatar [put]
+func (c PersonnelController) UpdateAvatar(ctx echo.Context) error {
+	//  get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	//  get file from multipart form
+	fh, err := ctx.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+	}
+	file, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	//  call service
+	result, err := c.ps.UpdateAvatar(id, domain.UploadAvatarInput{Size: fh.Size, Content: file})
+	if err != nil {
+		return err
+	}
+	//  return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// DeleteAvatar deletes the avatar of a personnel.
+//
+//	@Summary		Delete avatar
+//	@Description	Remove the avatar of a personnel, only the personnel and ADMIN users may remove it
+//	@Tags			Personnel
+//	@ID				deletePersonnelAvatar
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"Personnel ID"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.Personnel}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/personnel/{id}/avatar [delete]
+func (c PersonnelController) DeleteAvatar(ctx echo.Context) error {
+	//  get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	//  call service
+	result, err := c.ps.DeleteAvatar(id)
+	if err != nil {
+		return err
+	}
+	//  return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// FindAvatar serves the avatar of a personnel.
+//
+//	@Summary		Download avatar
+//	@Description	Serve the avatar at the URL set on the personnel, in the smallest stored size at least as large as the size asked for. The URL changes with every upload so the response may be cached for good
+//	@Tags			Personnel
+//	@ID				findPersonnelAvatar
+//	@Produce		png
+//	@Produce		jpeg
+//	@Param			id		path		string	true	"Personnel ID"
+//	@Param			version	path		string	true	"Avatar version"
+//	@Param			size	query		int		false	"Size in pixels, the largest when omitted"
+//	@Success		200		{file}		file
+//	@Failure		400		{object}	domain.InvalidRequestError
+//	@Failure		500		{object}	domain.SystemError
+//	@Router			/avatars/{id}/{version} [get]
+func (c PersonnelController) FindAvatar(ctx echo.Context) error {
+	//  get id and version from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	//  get size from query
+	size, _ := strconv.Atoi(ctx.QueryParam("size"))
+	//  call service
+	content, contentType, err := c.ps.FindAvatar(id, ctx.Param("version"), size)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	//  return result
+	ctx.Response().Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	ctx.Response().Header().Set("X-Content-Type-Options", "nosniff")
+	return ctx.Stream(http.StatusOK, contentType, content)
+}
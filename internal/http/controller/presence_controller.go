@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/labstack/echo/v4"
+
+	"github.com/chatApp/internal/domain"
+	"github.com/chatApp/internal/http/transport"
+)
+
+type PresenceController struct {
+	prs domain.PresenceService
+}
+
+func NewPresenceController(prs domain.PresenceService) PresenceController {
+	return PresenceController{prs: prs}
+}
+
+// FindPresence finds the presence of a user.
+//
+//	@Summary		Find the presence of a user
+//	@Description	Find whether a user is ONLINE, AWAY or OFFLINE and when they were last seen
+//	@Tags			Presence
+//	@ID				findPresence
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"User ID"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.Presence}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/{id}/presence [get]
+func (c PresenceController) FindPresence(ctx echo.Context) error {
+	// get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.prs.FindByUserID(id)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// FindPresences finds the presence of several users.
+//
+//	@Summary		Find the presence of users
+//	@Description	Find the presence of up to 500 users at once, such as a contact list. Unknown users are left out
+//	@Tags			Presence
+//	@ID				findPresences
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string						true	"Bearer "
+//	@Param			body			body		domain.FindPresenceInput	true	"Users to find the presence of"
+//	@Success		200				{object}	domain.BaseResponse{data=[]domain.Presence}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/presence [post]
+func (c PresenceController) FindPresences(ctx echo.Context) error {
+	// get input from request body
+	var in domain.FindPresenceInput
+	err := transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.prs.FindByUserIDs(in)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+
+	"github.com/chatApp/internal/domain"
+	"github.com/chatApp/internal/pkg/realtime"
+	"github.com/chatApp/internal/pkg/security"
+)
+
+type RealtimeController struct {
+	hub      *realtime.Hub
+	prs      domain.PresenceService
+	ps       domain.PersonnelService
+	ts       domain.TypingService
+	upgrader websocket.Upgrader
+}
+
+func NewRealtimeController(hub *realtime.Hub, prs domain.PresenceService, ps domain.PersonnelService, ts domain.TypingService) RealtimeController {
+	return RealtimeController{
+		hub: hub,
+		prs: prs,
+		ps:  ps,
+		ts:  ts,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			// the connection is authenticated with a bearer token rather than cookies,
+			// so cross origin clients are allowed just like the CORS middleware does
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// Connect upgrades the request to a websocket connection.
+//
+//	@Summary		Open the realtime channel
+//	@Description	Upgrade to a websocket connection that receives realtime events for the authenticated user. The token may be passed as the token query param for browser clients. The connection counts towards the presence of the user, send presence.set with {"status": "AWAY"} or {"status": "ONLINE"} to change it. Send typing.start with {"conversation_id": "..."} every few seconds while typing and typing.stop when done, the other participants receive typing.started and typing.stopped.
+//	@Tags			Realtime
+//	@ID				connectRealtime
+//	@Security		JWT
+//	@Param			Authorization	header	string	false	"Bearer "
+//	@Param			token			query	string	false	"Auth token"
+//	@Success		101
+//	@Failure		401	{object}	domain.UnauthorizedError
+//	@Failure		500	{object}	domain.SystemError
+//	@Router			/ws [get]
+func (c RealtimeController) Connect(ctx echo.Context) error {
+	// resolve the personnel from the auth token
+	personnelID, err := getPersonnelIDForContext(ctx, c.ps)
+	if err != nil {
+		return err
+	}
+	// the connection is closed when the session of the token ends
+	sessionID, err := security.GetSessionIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	// upgrade the connection, the upgrader replies to the client on failure
+	conn, err := c.upgrader.Upgrade(ctx.Response(), ctx.Request(), nil)
+	if err != nil {
+		slog.Warn("failed to upgrade websocket connection", "err", err)
+		return nil
+	}
+	// pump frames until the client goes away
+	realtime.NewClient(c.hub, c.prs, c.ts, personnelID, sessionID, conn).Run()
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/chatApp/internal/domain"
 	"github.com/chatApp/internal/http/transport"
+	"github.com/chatApp/internal/pkg/security"
 )
 
 type UserController struct {
@@ -89,7 +90,7 @@ func (c UserController) FindByUserName(ctx echo.Context) error {
 // Login authenticates a user based on login credentials.
 //
 //	@Summary		User login
-//	@Description	Authenticate a user using provided credentials
+//	@Description	Authenticate a user using provided credentials. Every failure is answered with the same 401, and repeated failures of a user name or from an address delay further attempts and then lock them out for a while. Users with two-factor authentication get a challenge token instead of tokens, it is exchanged at /users/login/mfa.
 //	@Tags			Auth
 //	@ID				userLogin
 //	@Accept			json
@@ -107,6 +108,8 @@ func (c UserController) Login(ctx echo.Context) error {
 	if err != nil {
 		return err
 	}
+	in.UserAgent = ctx.Request().UserAgent()
+	in.IPAddress = ctx.RealIP()
 	// Call the service to login
 	result, err := c.ur.Login(in)
 	if err != nil {
@@ -117,10 +120,432 @@ func (c UserController) Login(ctx echo.Context) error {
 
 }
 
+// RefreshToken exchanges a refresh token for a new pair of tokens.
+//
+//	@Summary		Refresh tokens
+//	@Description	Exchange a refresh token for a new token and refresh token. A refresh token can be used only once, presenting it again signs out every device of its login.
+//	@Tags			Auth
+//	@ID				refreshToken
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.RefreshTokenInput	true	"Refresh token"
+//	@Success		200		{object}	domain.BaseResponse{data=domain.LoginOutput}
+//	@Failure		400		{object}	domain.InvalidRequestError
+//	@Failure		401		{object}	domain.UnauthorizedError
+//	@Failure		500		{object}	domain.SystemError
+//	@Router			/users/token/refresh [post]
+func (c UserController) RefreshToken(ctx echo.Context) error {
+	// Decode the request body
+	var in domain.RefreshTokenInput
+	err := transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	in.UserAgent = ctx.Request().UserAgent()
+	in.IPAddress = ctx.RealIP()
+	// Call the service to refresh the tokens
+	result, err := c.ur.RefreshToken(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// RequestOTP sends a one time password to a mobile number.
+//
+//	@Summary		Request OTP
+//	@Description	Send a one time password by SMS to the mobile number of a user. The response is the same whether or not the number is registered, a new code can be asked for once the resend cooldown is over.
+//	@Tags			Auth
+//	@ID				requestOTP
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.RequestOTPInput	true	"Mobile number"
+//	@Success		200		{object}	domain.BaseResponse{data=domain.RequestOTPOutput}
+//	@Failure		400		{object}	domain.InvalidRequestError
+//	@Failure		500		{object}	domain.SystemError
+//	@Router			/users/otp [post]
+func (c UserController) RequestOTP(ctx echo.Context) error {
+	// Decode the request body
+	var in domain.RequestOTPInput
+	err := transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	// Call the service to send the code
+	result, err := c.ur.RequestOTP(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// VerifyOTP logs a user in with a one time password.
+//
+//	@Summary		Verify OTP
+//	@Description	Exchange a one time password for a token and refresh token, it also verifies the mobile number of the user. A code can be used only once and only a few wrong codes are accepted. Users with two-factor authentication get a challenge token instead of tokens, as with a password login.
+//	@Tags			Auth
+//	@ID				verifyOTP
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.VerifyOTPInput	true	"Mobile number and code"
+//	@Success		200		{object}	domain.BaseResponse{data=domain.LoginOutput}
+//	@Failure		400		{object}	domain.InvalidRequestError
+//	@Failure		500		{object}	domain.SystemError
+//	@Router			/users/otp/verify [post]
+func (c UserController) VerifyOTP(ctx echo.Context) error {
+	// Decode the request body
+	var in domain.VerifyOTPInput
+	err := transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	in.UserAgent = ctx.Request().UserAgent()
+	in.IPAddress = ctx.RealIP()
+	// Call the service to check the code
+	result, err := c.ur.VerifyOTP(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// VerifyMFA completes a login with the code of an authenticator.
+//
+//	@Summary		Verify two-factor login
+//	@Description	Exchange the challenge token of a login and a code of the authenticator of the user, or an unused recovery code, for a token and refresh token. A code works once, a challenge expires after a few minutes or wrong codes, wrong codes count towards the lockout of failed logins, and every failure is answered with the same 401.
+//	@Tags			Auth
+//	@ID				verifyMFA
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.VerifyMFAInput	true	"Challenge token and code"
+//	@Success		200		{object}	domain.BaseResponse{data=domain.LoginOutput}
+//	@Failure		400		{object}	domain.InvalidRequestError
+//	@Failure		401		{object}	domain.UnauthorizedError
+//	@Failure		500		{object}	domain.SystemError
+//	@Router			/users/login/mfa [post]
+func (c UserController) VerifyMFA(ctx echo.Context) error {
+	// Decode the request body
+	var in domain.VerifyMFAInput
+	err := transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	in.UserAgent = ctx.Request().UserAgent()
+	in.IPAddress = ctx.RealIP()
+	// Call the service to check the code
+	result, err := c.ur.VerifyMFA(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// ForgotPassword sends a password reset token.
+//
+//	@Summary		Forgot password
+//	@Description	Send a single use password reset token to the user, by text message unless configured otherwise. The response is the same whether or not the user exists, and a new token replaces the previous one.
+//	@Tags			Auth
+//	@ID				forgotPassword
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.ForgotPasswordInput	true	"User name"
+//	@Success		204		{object}	nil
+//	@Failure		400		{object}	domain.InvalidRequestError
+//	@Failure		500		{object}	domain.SystemError
+//	@Router			/users/password/forgot [post]
+func (c UserController) ForgotPassword(ctx echo.Context) error {
+	// Decode the request body
+	var in domain.ForgotPasswordInput
+	err := transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	// Call the service to send the token
+	err = c.ur.ForgotPassword(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusNoContent, nil)
+}
+
+// ResetPassword sets a new password with a reset token.
+//
+//	@Summary		Reset password
+//	@Description	Replace the password of a user with a password reset token. The token works once and until it expires, and every device of the user is signed out.
+//	@Tags			Auth
+//	@ID				resetPassword
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.ResetPasswordInput	true	"Reset token and new password"
+//	@Success		204		{object}	nil
+//	@Failure		400		{object}	domain.InvalidRequestError
+//	@Failure		500		{object}	domain.SystemError
+//	@Router			/users/password/reset [post]
+func (c UserController) ResetPassword(ctx echo.Context) error {
+	// Decode the request body
+	var in domain.ResetPasswordInput
+	err := transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	// Call the service to reset the password
+	err = c.ur.ResetPassword(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusNoContent, nil)
+}
+
+// Logout revokes the auth token and ends its session.
+//
+//	@Summary		User logout
+//	@Description	Revoke the auth token and end its session
+//	@Tags			Auth
+//	@ID				userLogout
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Success		204				{object}	nil
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/logout [post]
+func (c UserController) Logout(ctx echo.Context) error {
+	// get the token id from the auth token
+	tokenID, expiresAt, err := security.GetTokenIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	// Call the service to logout
+	err = c.ur.Logout(tokenID, expiresAt)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusNoContent, nil)
+}
+
+// ChangePassword changes the password of the authenticated user.
+//
+//	@Summary		Change password
+//	@Description	Replace the password of the authenticated user once the old one is checked. Every other device of the user is signed out.
+//	@Tags			Auth
+//	@ID				changePassword
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string						true	"Bearer "
+//	@Param			body			body		domain.ChangePasswordInput	true	"Old and new password"
+//	@Success		204				{object}	nil
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/me/password [put]
+func (c UserController) ChangePassword(ctx echo.Context) error {
+	// get the user and token id from the auth token
+	userID, err := security.GetUserIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	tokenID, _, err := security.GetTokenIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	// get input from request body
+	var in domain.ChangePasswordInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	in.IPAddress = ctx.RealIP()
+	// call service
+	err = c.ur.ChangePassword(userID, tokenID, in)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusNoContent, nil)
+}
+
+// FindSessions lists the active sessions of the authenticated user.
+//
+//	@Summary		List sessions
+//	@Description	List the devices the authenticated user is logged in on, latest used first. The session of the auth token is marked current.
+//	@Tags			Auth
+//	@ID				findUserSessions
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Success		200				{object}	domain.BaseResponse{data=[]domain.Session}
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/me/sessions [get]
+func (c UserController) FindSessions(ctx echo.Context) error {
+	// get the user and token id from the auth token
+	userID, err := security.GetUserIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	tokenID, _, err := security.GetTokenIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.ur.FindSessions(userID, tokenID)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// DeleteSession signs the authenticated user out of a session.
+//
+//	@Summary		Delete a session
+//	@Description	End a session of the authenticated user, its tokens stop working at once
+//	@Tags			Auth
+//	@ID				deleteUserSession
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"Session ID"
+//	@Success		204				{object}	nil
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/me/sessions/{id} [delete]
+func (c UserController) DeleteSession(ctx echo.Context) error {
+	userID, err := security.GetUserIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	// get id from path
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// call service
+	err = c.ur.DeleteSession(userID, id)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusNoContent, nil)
+}
+
+// EnrollTOTP starts the enrollment of an authenticator for the authenticated user.
+//
+//	@Summary		Enroll authenticator
+//	@Description	Generate a secret for an RFC 6238 authenticator app, to be scanned from the provisioning URI or typed in. It takes part in logins once confirmed, enrolling again before that replaces the secret.
+//	@Tags			Auth
+//	@ID				enrollTOTP
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Success		200				{object}	domain.BaseResponse{data=domain.EnrollTOTPOutput}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/me/totp [post]
+func (c UserController) EnrollTOTP(ctx echo.Context) error {
+	userID, err := security.GetUserIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.ur.EnrollTOTP(userID)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// ConfirmTOTP turns on two-factor authentication for the authenticated user.
+//
+//	@Summary		Confirm authenticator
+//	@Description	Confirm the enrolled authenticator with a code it shows, from then on logins ask for its codes. The response holds recovery codes that each stand in for the authenticator once, they are shown only this time.
+//	@Tags			Auth
+//	@ID				confirmTOTP
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string					true	"Bearer "
+//	@Param			body			body		domain.TOTPCodeInput	true	"Authenticator code"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.RecoveryCodesOutput}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/me/totp/confirm [post]
+func (c UserController) ConfirmTOTP(ctx echo.Context) error {
+	userID, err := security.GetUserIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	// get input from request body
+	var in domain.TOTPCodeInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	// call service
+	result, err := c.ur.ConfirmTOTP(userID, in)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// DisableTOTP turns off two-factor authentication for the authenticated user.
+//
+//	@Summary		Disable authenticator
+//	@Description	Remove the authenticator and the recovery codes of the authenticated user once a code of the authenticator or a recovery code is checked
+//	@Tags			Auth
+//	@ID				disableTOTP
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string					true	"Bearer "
+//	@Param			body			body		domain.TOTPCodeInput	true	"Authenticator or recovery code"
+//	@Success		204				{object}	nil
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/me/totp [delete]
+func (c UserController) DisableTOTP(ctx echo.Context) error {
+	userID, err := security.GetUserIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	// get input from request body
+	var in domain.TOTPCodeInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	// call service
+	err = c.ur.DisableTOTP(userID, in)
+	if err != nil {
+		return err
+	}
+	// return result
+	return transport.SendResponse(ctx, http.StatusNoContent, nil)
+}
+
 // RegisterUser  Register a new user
 //
 //	@Summary		Register a new user
-//	@Description	Create a new user with the provided details
+//	@Description	Create a new user with the provided details, registration only creates USER accounts
 //	@Tags			Auth
 //	@Accept			json
 //	@Produce		json
@@ -134,7 +559,10 @@ func (c UserController) Login(ctx echo.Context) error {
 func (c UserController) RegisterUser(ctx echo.Context) error {
 	// Decode the request body
 	var in domain.RegisterUserInput
-	transport.DecodeAndValidateRequestBody(ctx, &in)
+	err := transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
 
 	// Call service method to create  a new user
 	result, err := c.ur.CreateUser(in)
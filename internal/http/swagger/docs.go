@@ -12,7 +12,7 @@ const docTemplate = `{
         "termsOfService": "https://example.com/terms",
         "contact": {
             "name": "Mohammad Developer",
-            "url": "https://example.com",
+            "url": "https://rizwank123.github.io",
             "email": "mohammad.developer@example.com"
         },
         "version": "{{.Version}}"
@@ -20,25 +20,43 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/personnel": {
-            "post": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Get the public keys auth tokens are signed with as a JSON Web Key Set (RFC 7517), a token names its key in the kid header. Served at /.well-known/jwks.json, outside of the API base path.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "JSON Web Key Set",
+                "operationId": "findJWKS",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_chatApp_internal_pkg_security.JWKS"
+                        }
+                    }
+                }
+            }
+        },
+        "/attachments/{id}": {
+            "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Create a new personnel record",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Download an attachment of a conversation the authenticated user participates in",
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "Personnel"
+                    "Attachment"
                 ],
-                "summary": "Create new personnel",
-                "operationId": "createPersonnel",
+                "summary": "Download an attachment",
+                "operationId": "downloadAttachment",
                 "parameters": [
                     {
                         "type": "string",
@@ -48,32 +66,18 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Personnel creation input",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/CreatePersonnelInput"
-                        }
+                        "type": "string",
+                        "description": "Attachment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/BaseResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/Personnel"
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -88,6 +92,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/UnauthorizedError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -97,25 +107,22 @@ const docTemplate = `{
                 }
             }
         },
-        "/personnel/filter": {
-            "post": {
+        "/attachments/{id}/thumbnails/{size}": {
+            "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Filter personnel using provided criteria",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Download a scaled down copy of an image attachment, the sizes available are listed in the thumbnails of the attachment",
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "Personnel"
+                    "Attachment"
                 ],
-                "summary": "Filter personnel",
-                "operationId": "filterPersonnel",
+                "summary": "Download an attachment thumbnail",
+                "operationId": "downloadAttachmentThumbnail",
                 "parameters": [
                     {
                         "type": "string",
@@ -125,35 +132,25 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Filter input",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/FilterInput"
-                        }
+                        "type": "string",
+                        "description": "Attachment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Thumbnail size",
+                        "name": "size",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/BaseResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/Personnel"
-                                            }
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -168,6 +165,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/UnauthorizedError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -177,14 +180,70 @@ const docTemplate = `{
                 }
             }
         },
-        "/personnel/{id}": {
+        "/avatars/{id}/{version}": {
+            "get": {
+                "description": "Serve the avatar at the URL set on the personnel, in the smallest stored size at least as large as the size asked for. The URL changes with every upload so the response may be cached for good",
+                "produces": [
+                    "image/png",
+                    "image/jpeg"
+                ],
+                "tags": [
+                    "Personnel"
+                ],
+                "summary": "Download avatar",
+                "operationId": "findPersonnelAvatar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Personnel ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Avatar version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Size in pixels, the largest when omitted",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/conversations": {
             "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Find personnel based on the provided ID",
+                "description": "List the conversations of the authenticated user with their latest message and unread count",
                 "consumes": [
                     "application/json"
                 ],
@@ -192,10 +251,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Personnel"
+                    "Conversation"
                 ],
-                "summary": "Find personnel by ID",
-                "operationId": "findPersonnelByID",
+                "summary": "List my conversations",
+                "operationId": "findMyConversations",
                 "parameters": [
                     {
                         "type": "string",
@@ -203,13 +262,6 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Personnel ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
@@ -224,31 +276,22 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/Personnel"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/ConversationSummary"
+                                            }
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/InvalidRequestError"
-                        }
-                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/UnauthorizedError"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/ForbiddenAccessError"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -256,14 +299,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/conversations/direct": {
+            "post": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Update personnel based on the provided ID and input",
+                "description": "Return the direct conversation between the authenticated user and another personnel, creating it if needed",
                 "consumes": [
                     "application/json"
                 ],
@@ -271,10 +316,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Personnel"
+                    "Conversation"
                 ],
-                "summary": "Update personnel",
-                "operationId": "updatePersonnel",
+                "summary": "Open a direct conversation",
+                "operationId": "createDirectConversation",
                 "parameters": [
                     {
                         "type": "string",
@@ -284,19 +329,12 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Personnel ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Personnel update input",
+                        "description": "Direct conversation input",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/UpdatePersonnelInput"
+                            "$ref": "#/definitions/CreateDirectConversationInput"
                         }
                     }
                 ],
@@ -312,7 +350,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/Personnel"
+                                            "$ref": "#/definitions/Conversation"
                                         }
                                     }
                                 }
@@ -331,12 +369,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/UnauthorizedError"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/ForbiddenAccessError"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -344,14 +376,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/conversations/group": {
+            "post": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Delete personnel using the provided ID",
+                "description": "Create a named group conversation with the authenticated user as a participant",
                 "consumes": [
                     "application/json"
                 ],
@@ -359,10 +393,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Personnel"
+                    "Conversation"
                 ],
-                "summary": "Delete personnel",
-                "operationId": "deletePersonnel",
+                "summary": "Create a group conversation",
+                "operationId": "createGroupConversation",
                 "parameters": [
                     {
                         "type": "string",
@@ -372,16 +406,33 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Personnel ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Group conversation input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CreateGroupConversationInput"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/Conversation"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -404,9 +455,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/products": {
+        "/conversations/join": {
             "post": {
-                "description": "Add a new product to the system.",
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Join the group conversation of an invite token as a member",
                 "consumes": [
                     "application/json"
                 ],
@@ -414,24 +470,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Product"
+                    "Conversation"
                 ],
-                "summary": "Create a new Product",
-                "operationId": "createProduct",
+                "summary": "Join with an invite",
+                "operationId": "joinConversation",
                 "parameters": [
                     {
-                        "description": "Product details",
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Join input",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/Product"
+                            "$ref": "#/definitions/JoinConversationInput"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -441,7 +504,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/Product"
+                                            "$ref": "#/definitions/Conversation"
                                         }
                                     }
                                 }
@@ -454,7 +517,13 @@ const docTemplate = `{
                             "$ref": "#/definitions/InvalidRequestError"
                         }
                     },
-                    "500": {
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/SystemError"
@@ -463,9 +532,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/category/{cat}": {
+        "/conversations/{id}": {
             "get": {
-                "description": "Retrieve a list of products that belong to a specific category.",
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Find a conversation the authenticated user participates in",
                 "consumes": [
                     "application/json"
                 ],
@@ -473,15 +547,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Product"
+                    "Conversation"
                 ],
-                "summary": "Get Products by Category",
-                "operationId": "getProductByCategory",
+                "summary": "Find conversation by ID",
+                "operationId": "findConversationByID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Product Category",
-                        "name": "cat",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Conversation ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -498,10 +579,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/Product"
-                                            }
+                                            "$ref": "#/definitions/Conversation"
                                         }
                                     }
                                 }
@@ -514,10 +592,16 @@ const docTemplate = `{
                             "$ref": "#/definitions/InvalidRequestError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/NotFoundError"
+                            "$ref": "#/definitions/ForbiddenAccessError"
                         }
                     },
                     "500": {
@@ -529,33 +613,51 @@ const docTemplate = `{
                 }
             }
         },
-        "/users": {
+        "/conversations/{id}/attachments": {
             "post": {
-                "description": "Create a new user with the provided details",
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Upload a file to a conversation the authenticated user participates in, it is sent by listing its ID in the attachment_ids of a message",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Attachment"
                 ],
-                "summary": "Register a new user",
+                "summary": "Upload an attachment",
+                "operationId": "uploadAttachment",
                 "parameters": [
                     {
-                        "description": "User registration details",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/CreateUserInput"
-                        }
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Conversation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "allOf": [
                                 {
@@ -565,7 +667,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/User"
+                                            "$ref": "#/definitions/Attachment"
                                         }
                                     }
                                 }
@@ -599,9 +701,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/login": {
-            "post": {
-                "description": "Authenticate a user using provided credentials",
+        "/conversations/{id}/invites": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "List the invites of a group conversation that have not expired, only owners and admins may do so",
                 "consumes": [
                     "application/json"
                 ],
@@ -609,19 +716,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Conversation"
                 ],
-                "summary": "User login",
-                "operationId": "userLogin",
+                "summary": "List invites",
+                "operationId": "findConversationInvites",
                 "parameters": [
                     {
-                        "description": "Login input",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/LoginInput"
-                        }
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Conversation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -636,7 +748,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/LoginOutput"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/ConversationInvite"
+                                            }
                                         }
                                     }
                                 }
@@ -655,6 +770,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/UnauthorizedError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -662,16 +783,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/users/{id}": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Find a user based on the provided ID",
+                "description": "Create an expiring invite token to a group conversation, only owners and admins may do so",
                 "consumes": [
                     "application/json"
                 ],
@@ -679,10 +798,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Conversation"
                 ],
-                "summary": "Find a user by ID",
-                "operationId": "findUserByID",
+                "summary": "Create an invite",
+                "operationId": "createConversationInvite",
                 "parameters": [
                     {
                         "type": "string",
@@ -693,15 +812,24 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Conversation ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Invite input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CreateInviteInput"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "allOf": [
                                 {
@@ -711,7 +839,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/User"
+                                            "$ref": "#/definitions/ConversationInvite"
                                         }
                                     }
                                 }
@@ -745,14 +873,87 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{username}": {
+        "/conversations/{id}/invites/{inviteId}": {
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Revoke an invite of a group conversation, only owners and admins may do so",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Conversation"
+                ],
+                "summary": "Revoke an invite",
+                "operationId": "revokeConversationInvite",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Conversation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Invite ID",
+                        "name": "inviteId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/conversations/{id}/messages": {
             "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Get user information by their username",
+                "description": "List a page of the messages of a conversation the authenticated user participates in, oldest first.\nWithout a cursor the latest messages are returned.",
                 "consumes": [
                     "application/json"
                 ],
@@ -760,9 +961,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Message"
                 ],
-                "summary": "Find a user by username",
+                "summary": "List conversation messages",
+                "operationId": "findConversationMessages",
                 "parameters": [
                     {
                         "type": "string",
@@ -773,10 +975,28 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "username",
-                        "name": "username",
+                        "description": "Conversation ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to return the older messages of",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to return the newer messages of",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of messages, 50 by default",
+                        "name": "size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -785,13 +1005,16 @@ const docTemplate = `{
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/BaseResponse"
+                                    "$ref": "#/definitions/CursorPaginationResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/User"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/Message"
+                                            }
                                         }
                                     }
                                 }
@@ -824,52 +1047,3902 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "ActivationStatus": {
-            "type": "string",
-            "enum": [
-                "ACTIVE",
-                "DISABLED"
-            ],
-            "x-enum-varnames": [
-                "ActivationStatusACTIVE",
-                "ActivationStatusDISABLED"
-            ]
-        },
-        "Address": {
-            "type": "object",
-            "properties": {
-                "city": {
-                    "type": "string",
-                    "example": "Mumbai"
-                },
-                "coordinates": {
-                    "$ref": "#/definitions/JSONB"
-                },
-                "country": {
-                    "type": "string",
-                    "example": "India"
-                },
-                "pincode": {
-                    "type": "string",
-                    "example": "123456"
-                },
-                "state": {
-                    "type": "string",
-                    "example": "Maharashtra"
-                },
-                "street": {
-                    "type": "string",
-                    "example": "123 Main St"
-                }
-            }
         },
-        "BaseResponse": {
+        "/conversations/{id}/participants": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "List the participants of a conversation the authenticated user participates in",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Conversation"
+                ],
+                "summary": "List conversation participants",
+                "operationId": "findConversationParticipants",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Conversation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/ConversationParticipant"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Add participants to a group conversation the authenticated user participates in",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Conversation"
+                ],
+                "summary": "Add conversation participants",
+                "operationId": "addConversationParticipants",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Conversation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Participants input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/AddParticipantsInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/ConversationParticipant"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/conversations/{id}/participants/{personnelId}": {
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Leave a group conversation, or remove another participant from it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Conversation"
+                ],
+                "summary": "Remove a conversation participant",
+                "operationId": "removeConversationParticipant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Conversation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Personnel ID",
+                        "name": "personnelId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/conversations/{id}/participants/{personnelId}/mute": {
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Stop a participant from sending messages for a number of hours, a duration of 0 lifts the mute",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Conversation"
+                ],
+                "summary": "Mute a participant",
+                "operationId": "muteConversationParticipant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Conversation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Personnel ID",
+                        "name": "personnelId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Mute input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/MuteParticipantInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ConversationParticipant"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/conversations/{id}/participants/{personnelId}/role": {
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Promote a participant to admin or demote them to member, only owners and admins may do so",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Conversation"
+                ],
+                "summary": "Change a participant role",
+                "operationId": "updateConversationParticipantRole",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Conversation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Personnel ID",
+                        "name": "personnelId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/UpdateParticipantRoleInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ConversationParticipant"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/conversations/{id}/read": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Mark every message of a conversation up to the given message as read by the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "Mark a conversation read",
+                "operationId": "markConversationRead",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Conversation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Read marker input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/MarkConversationReadInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Send a message to a conversation, the sender is taken from the auth token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "Send a message",
+                "operationId": "sendMessage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Message input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CreateMessageInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/Message"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/search": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Full-text search of the messages of the conversations the authenticated user participates in, most relevant first.\nEach result carries an HTML escaped snippet with the matching words wrapped in \u003cmark\u003e tags.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "Search messages",
+                "operationId": "searchMessages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Words to search for, quoted phrases, or and -excluded words are supported",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only messages sent by this personnel",
+                        "name": "sender_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only messages of this conversation",
+                        "name": "conversation_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only messages sent at or after this RFC 3339 time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only messages sent at or before this RFC 3339 time",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to return the following results of",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of results, 50 by default",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/CursorPaginationResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/MessageSearchResult"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/UserError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/sent": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "List a page of the messages sent by the authenticated user, oldest first.\nWithout a cursor the latest messages are returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "List sent messages",
+                "operationId": "findSentMessages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to return the older messages of",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to return the newer messages of",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of messages, 50 by default",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/CursorPaginationResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/Message"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/UserError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Find a message of a conversation the authenticated user participates in",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "Find message by ID",
+                "operationId": "findMessageByID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/Message"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Edit the content of a message sent by the authenticated user, the prior content is kept in its edit history",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "Edit a message",
+                "operationId": "updateMessage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Message update input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/UpdateMessageInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/Message"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Delete a message sent by the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "Delete a message",
+                "operationId": "deleteMessage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/edits": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "List the prior versions of the content of a message, oldest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "List message edits",
+                "operationId": "findMessageEdits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/MessageEdit"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/reactions": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "List the reactions to a message aggregated per emoji",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "List message reactions",
+                "operationId": "findMessageReactions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/ReactionSummary"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "React to a message with an emoji, reacting twice with the same emoji has no effect",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "React to a message",
+                "operationId": "addMessageReaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reaction input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/AddReactionInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/ReactionSummary"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/reactions/{emoji}": {
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Take back the reaction of the authenticated user to a message",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "Remove a reaction",
+                "operationId": "removeMessageReaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Emoji, url encoded",
+                        "name": "emoji",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/ReactionSummary"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/receipts": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "List the delivery and read status of a message for each of its recipients",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "List message receipts",
+                "operationId": "findMessageReceipts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/MessageStatus"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/replies": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "List a page of the replies in the thread of a message, oldest first. A reply lists the thread it belongs to.\nWithout a cursor the latest replies are returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "List thread replies",
+                "operationId": "findMessageReplies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to return the older replies of",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to return the newer replies of",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of replies, 50 by default",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/CursorPaginationResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/Message"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/status": {
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Mark a message sent by another participant as delivered or read",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "Update message status",
+                "operationId": "updateMessageStatus",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Message status input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/UpdateMessageStatusInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/personnel": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Create a new personnel record, only ADMIN users may create personnel",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Personnel"
+                ],
+                "summary": "Create new personnel",
+                "operationId": "createPersonnel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Personnel creation input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CreatePersonnelInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/Personnel"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/personnel/filter": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Filter personnel using provided criteria",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Personnel"
+                ],
+                "summary": "Filter personnel",
+                "operationId": "filterPersonnel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Filter input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/FilterInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/Personnel"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/personnel/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Find personnel based on the provided ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Personnel"
+                ],
+                "summary": "Find personnel by ID",
+                "operationId": "findPersonnelByID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Personnel ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/Personnel"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Update personnel based on the provided ID and input. Users may update their own personnel, ADMIN users any, and only ADMIN users may change the role and activation status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Personnel"
+                ],
+                "summary": "Update personnel",
+                "operationId": "updatePersonnel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Personnel ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Personnel update input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/UpdatePersonnelInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/Personnel"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Delete personnel using the provided ID, only ADMIN users may delete personnel",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Personnel"
+                ],
+                "summary": "Delete personnel",
+                "operationId": "deletePersonnel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Personnel ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/personnel/{id}/avatar": {
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Replace the avatar of a personnel with an uploaded image, it is cropped to a square and stored in several sizes. Only the personnel and ADMIN users may change it",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Personnel"
+                ],
+                "summary": "Upload avatar",
+                "operationId": "updatePersonnelAvatar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Personnel ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "JPEG, PNG, GIF, WebP or BMP image",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/Personnel"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Remove the avatar of a personnel, only the personnel and ADMIN users may remove it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Personnel"
+                ],
+                "summary": "Delete avatar",
+                "operationId": "deletePersonnelAvatar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Personnel ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/Personnel"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "post": {
+                "description": "Create a new user with the provided details, registration only creates USER accounts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User registration details",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CreateUserInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login": {
+            "post": {
+                "description": "Authenticate a user using provided credentials. Every failure is answered with the same 401, and repeated failures of a user name or from an address delay further attempts and then lock them out for a while. Users with two-factor authentication get a challenge token instead of tokens, it is exchanged at /users/login/mfa.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "User login",
+                "operationId": "userLogin",
+                "parameters": [
+                    {
+                        "description": "Login input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/LoginInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/LoginOutput"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login/mfa": {
+            "post": {
+                "description": "Exchange the challenge token of a login and a code of the authenticator of the user, or an unused recovery code, for a token and refresh token. A code works once, a challenge expires after a few minutes or wrong codes, wrong codes count towards the lockout of failed logins, and every failure is answered with the same 401.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Verify two-factor login",
+                "operationId": "verifyMFA",
+                "parameters": [
+                    {
+                        "description": "Challenge token and code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/VerifyMFAInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/LoginOutput"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/logout": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Revoke the auth token and end its session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "User logout",
+                "operationId": "userLogout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/password": {
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Replace the password of the authenticated user once the old one is checked. Every other device of the user is signed out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Change password",
+                "operationId": "changePassword",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Old and new password",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ChangePasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "List the devices the authenticated user is logged in on, latest used first. The session of the auth token is marked current.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "List sessions",
+                "operationId": "findUserSessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/Session"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "End a session of the authenticated user, its tokens stop working at once",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Delete a session",
+                "operationId": "deleteUserSession",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/totp": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Generate a secret for an RFC 6238 authenticator app, to be scanned from the provisioning URI or typed in. It takes part in logins once confirmed, enrolling again before that replaces the secret.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Enroll authenticator",
+                "operationId": "enrollTOTP",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/EnrollTOTPOutput"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Remove the authenticator and the recovery codes of the authenticated user once a code of the authenticator or a recovery code is checked",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Disable authenticator",
+                "operationId": "disableTOTP",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Authenticator or recovery code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/TOTPCodeInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/totp/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Confirm the enrolled authenticator with a code it shows, from then on logins ask for its codes. The response holds recovery codes that each stand in for the authenticator once, they are shown only this time.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Confirm authenticator",
+                "operationId": "confirmTOTP",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Authenticator code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/TOTPCodeInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/RecoveryCodesOutput"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/otp": {
+            "post": {
+                "description": "Send a one time password by SMS to the mobile number of a user. The response is the same whether or not the number is registered, a new code can be asked for once the resend cooldown is over.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Request OTP",
+                "operationId": "requestOTP",
+                "parameters": [
+                    {
+                        "description": "Mobile number",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/RequestOTPInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/RequestOTPOutput"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/otp/verify": {
+            "post": {
+                "description": "Exchange a one time password for a token and refresh token, it also verifies the mobile number of the user. A code can be used only once and only a few wrong codes are accepted. Users with two-factor authentication get a challenge token instead of tokens, as with a password login.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Verify OTP",
+                "operationId": "verifyOTP",
+                "parameters": [
+                    {
+                        "description": "Mobile number and code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/VerifyOTPInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/LoginOutput"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/password/forgot": {
+            "post": {
+                "description": "Send a single use password reset token to the user, by text message unless configured otherwise. The response is the same whether or not the user exists, and a new token replaces the previous one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Forgot password",
+                "operationId": "forgotPassword",
+                "parameters": [
+                    {
+                        "description": "User name",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ForgotPasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/password/reset": {
+            "post": {
+                "description": "Replace the password of a user with a password reset token. The token works once and until it expires, and every device of the user is signed out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Reset password",
+                "operationId": "resetPassword",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ResetPasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/presence": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Find the presence of up to 500 users at once, such as a contact list. Unknown users are left out",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Presence"
+                ],
+                "summary": "Find the presence of users",
+                "operationId": "findPresences",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Users to find the presence of",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/FindPresenceInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/Presence"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/token/refresh": {
+            "post": {
+                "description": "Exchange a refresh token for a new token and refresh token. A refresh token can be used only once, presenting it again signs out every device of its login.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Refresh tokens",
+                "operationId": "refreshToken",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/RefreshTokenInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/LoginOutput"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Find a user based on the provided ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Find a user by ID",
+                "operationId": "findUserByID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/presence": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Find whether a user is ONLINE, AWAY or OFFLINE and when they were last seen",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Presence"
+                ],
+                "summary": "Find the presence of a user",
+                "operationId": "findPresence",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/Presence"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{username}": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get user information by their username",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Find a user by username",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/InvalidRequestError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ForbiddenAccessError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        },
+        "/ws": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Upgrade to a websocket connection that receives realtime events for the authenticated user. The token may be passed as the token query param for browser clients. The connection counts towards the presence of the user, send presence.set with {\"status\": \"AWAY\"} or {\"status\": \"ONLINE\"} to change it. Send typing.start with {\"conversation_id\": \"...\"} every few seconds while typing and typing.stop when done, the other participants receive typing.started and typing.stopped.",
+                "tags": [
+                    "Realtime"
+                ],
+                "summary": "Open the realtime channel",
+                "operationId": "connectRealtime",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer ",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Auth token",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/UnauthorizedError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/SystemError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "ActivationStatus": {
+            "type": "string",
+            "enum": [
+                "ACTIVE",
+                "DISABLED"
+            ],
+            "x-enum-varnames": [
+                "ActivationStatusACTIVE",
+                "ActivationStatusDISABLED"
+            ]
+        },
+        "AddParticipantsInput": {
+            "type": "object",
+            "required": [
+                "participant_ids"
+            ],
+            "properties": {
+                "participant_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "AddReactionInput": {
+            "type": "object",
+            "required": [
+                "emoji"
+            ],
+            "properties": {
+                "emoji": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "example": "👍"
+                }
+            }
+        },
+        "Address": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "example": "Mumbai"
+                },
+                "coordinates": {
+                    "$ref": "#/definitions/JSONB"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "India"
+                },
+                "pincode": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "Maharashtra"
+                },
+                "street": {
+                    "type": "string",
+                    "example": "123 Main St"
+                }
+            }
+        },
+        "Attachment": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string",
+                    "example": "image/png"
+                },
+                "conversation_id": {
+                    "type": "string",
+                    "example": "12345678-1234-1234-1234-123456789012"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
+                "file_name": {
+                    "type": "string",
+                    "example": "screenshot.png"
+                },
+                "height": {
+                    "type": "integer",
+                    "example": 1080
+                },
+                "id": {
+                    "type": "string",
+                    "example": ""
+                },
+                "message_id": {
+                    "description": "MessageID is set once the attachment is sent with a message",
+                    "type": "string",
+                    "example": "12345678-1234-1234-1234-123456789012"
+                },
+                "placeholder": {
+                    "description": "Placeholder is a BlurHash of the image for clients to show while the thumbnail loads",
+                    "type": "string",
+                    "example": "LEHV6nWB2yk8pyo0adR*.7kCMdnj"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 48213
+                },
+                "thumbnails": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/AttachmentThumbnail"
+                    }
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
+                "uploader_id": {
+                    "type": "string",
+                    "example": "12345678-1234-1234-1234-123456789012"
+                },
+                "width": {
+                    "description": "Width, Height and Placeholder are set once an image has been processed, Width and Height are as displayed",
+                    "type": "integer",
+                    "example": 1920
+                }
+            }
+        },
+        "AttachmentThumbnail": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "height": {
+                    "type": "integer",
+                    "example": 270
+                },
+                "size": {
+                    "description": "Size is the box in pixels the thumbnail fits in",
+                    "type": "integer",
+                    "example": 480
+                },
+                "width": {
+                    "type": "integer",
+                    "example": 480
+                }
+            }
+        },
+        "BaseResponse": {
+            "type": "object",
+            "properties": {
+                "data": {}
+            }
+        },
+        "ChangePasswordInput": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "old_password"
+            ],
+            "properties": {
+                "new_password": {
+                    "type": "string",
+                    "example": "correct horse battery staple"
+                },
+                "old_password": {
+                    "type": "string",
+                    "example": "password123"
+                }
+            }
+        },
+        "Conversation": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "12345678-1234-1234-1234-123456789012"
+                },
+                "id": {
+                    "type": "string",
+                    "example": ""
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Backend team"
+                },
+                "type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/ConversationType"
+                        }
+                    ],
+                    "example": "GROUP"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                }
+            }
+        },
+        "ConversationInvite": {
+            "type": "object",
+            "properties": {
+                "conversation_id": {
+                    "type": "string",
+                    "example": "12345678-1234-1234-1234-123456789012"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "12345678-1234-1234-1234-123456789012"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
+                "id": {
+                    "type": "string",
+                    "example": ""
+                },
+                "max_uses": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "token": {
+                    "type": "string",
+                    "example": "3f1c8a52-6f0e-4c1b-9d7e-2b8f1f2d9a10"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
+                "uses": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "ConversationParticipant": {
+            "type": "object",
+            "properties": {
+                "conversation_id": {
+                    "type": "string",
+                    "example": "12345678-1234-1234-1234-123456789012"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
+                "id": {
+                    "type": "string",
+                    "example": ""
+                },
+                "last_read_at": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
+                "muted_until": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
+                "personnel_id": {
+                    "type": "string",
+                    "example": "12345678-1234-1234-1234-123456789012"
+                },
+                "role": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/ParticipantRole"
+                        }
+                    ],
+                    "example": "MEMBER"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                }
+            }
+        },
+        "ConversationSummary": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "12345678-1234-1234-1234-123456789012"
+                },
+                "id": {
+                    "type": "string",
+                    "example": ""
+                },
+                "latest_message": {
+                    "$ref": "#/definitions/Message"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Backend team"
+                },
+                "type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/ConversationType"
+                        }
+                    ],
+                    "example": "GROUP"
+                },
+                "unread_count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                }
+            }
+        },
+        "ConversationType": {
+            "type": "string",
+            "enum": [
+                "DIRECT",
+                "GROUP"
+            ],
+            "x-enum-varnames": [
+                "ConversationTypeDIRECT",
+                "ConversationTypeGROUP"
+            ]
+        },
+        "CreateDirectConversationInput": {
             "type": "object",
+            "required": [
+                "personnel_id"
+            ],
             "properties": {
-                "data": {}
+                "personnel_id": {
+                    "type": "string",
+                    "example": "12345678-1234-1234-1234-123456789012"
+                }
+            }
+        },
+        "CreateGroupConversationInput": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Backend team"
+                },
+                "participant_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "CreateInviteInput": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "description": "ExpiresIn is the validity of the invite in hours, the configured default is used when omitted",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 24
+                },
+                "max_uses": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 10
+                }
+            }
+        },
+        "CreateMessageInput": {
+            "type": "object",
+            "required": [
+                "conversation_id"
+            ],
+            "properties": {
+                "attachment_ids": {
+                    "description": "AttachmentIDs are uploaded attachments of the conversation sent with the message",
+                    "type": "array",
+                    "maxItems": 10,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "content": {
+                    "description": "Content may be left out when the message carries attachments",
+                    "type": "string"
+                },
+                "conversation_id": {
+                    "type": "string"
+                },
+                "reply_to_id": {
+                    "description": "ReplyToID optionally quotes a message of the same conversation, the new message joins its thread",
+                    "type": "string"
+                }
             }
         },
         "CreatePersonnelInput": {
@@ -886,10 +4959,6 @@ const docTemplate = `{
                 "address": {
                     "$ref": "#/definitions/Address"
                 },
-                "avatar": {
-                    "type": "string",
-                    "example": "https://example.com/avatar.jpg"
-                },
                 "email": {
                     "type": "string",
                     "example": "expertkhan@gmail.com"
@@ -926,6 +4995,9 @@ const docTemplate = `{
         },
         "CreateUserInput": {
             "type": "object",
+            "required": [
+                "password"
+            ],
             "properties": {
                 "first_name": {
                     "type": "string",
@@ -937,7 +5009,7 @@ const docTemplate = `{
                 },
                 "password": {
                     "type": "string",
-                    "example": "password123"
+                    "example": "correct horse battery staple"
                 },
                 "role": {
                     "allOf": [
@@ -945,7 +5017,7 @@ const docTemplate = `{
                             "$ref": "#/definitions/github_com_chatApp_internal_domain.UserRole"
                         }
                     ],
-                    "example": "ADMIN"
+                    "example": "USER"
                 },
                 "user_name": {
                     "type": "string",
@@ -953,6 +5025,40 @@ const docTemplate = `{
                 }
             }
         },
+        "CursorPaginationResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "next_cursor": {
+                    "description": "NextCursor fetches the newer page with ?after=, empty when there is none",
+                    "type": "string",
+                    "example": "MjAyNC0xMC0xNlQxNjowNzo1My4xMjM0NTZa..."
+                },
+                "prev_cursor": {
+                    "description": "PrevCursor fetches the older page with ?before=, empty when there is none",
+                    "type": "string",
+                    "example": "MjAyNC0xMC0xNlQxNjowNzo1My4xMjM0NTZa..."
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 50
+                }
+            }
+        },
+        "EnrollTOTPOutput": {
+            "type": "object",
+            "properties": {
+                "provisioning_uri": {
+                    "type": "string",
+                    "example": "otpauth://totp/ChatApp:+919984778491?algorithm=SHA1\u0026digits=6\u0026issuer=ChatApp\u0026period=30\u0026secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+                },
+                "secret": {
+                    "description": "Secret is entered in authenticator apps that cannot scan the provisioning URI",
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+                }
+            }
+        },
         "FilterFieldPredicate": {
             "type": "object",
             "properties": {
@@ -1048,6 +5154,22 @@ const docTemplate = `{
                 "FilterOpBetween"
             ]
         },
+        "FindPresenceInput": {
+            "type": "object",
+            "required": [
+                "user_ids"
+            ],
+            "properties": {
+                "user_ids": {
+                    "type": "array",
+                    "maxItems": 500,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "ForbiddenAccessError": {
             "type": "object",
             "properties": {
@@ -1061,44 +5183,352 @@ const docTemplate = `{
                 }
             }
         },
+        "ForgotPasswordInput": {
+            "type": "object",
+            "required": [
+                "user_name"
+            ],
+            "properties": {
+                "user_name": {
+                    "type": "string",
+                    "example": "+919984778491"
+                }
+            }
+        },
         "InvalidRequestError": {
             "type": "object",
             "properties": {
                 "message": {
                     "type": "string",
-                    "example": "invalid request"
+                    "example": "invalid request"
+                }
+            }
+        },
+        "JSONB": {
+            "type": "object",
+            "additionalProperties": true
+        },
+        "JoinConversationInput": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "3f1c8a52-6f0e-4c1b-9d7e-2b8f1f2d9a10"
+                }
+            }
+        },
+        "LoginInput": {
+            "type": "object",
+            "properties": {
+                "device_name": {
+                    "description": "DeviceName names the session of the login, it is made up from the user agent when left empty",
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Rizwan's phone"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "user_name": {
+                    "type": "string",
+                    "example": "+919984778491 or example"
+                }
+            }
+        },
+        "LoginOutput": {
+            "type": "object",
+            "properties": {
+                "challenge_expires_in": {
+                    "description": "ChallengeExpiresIn is the lifetime of the challenge token in seconds",
+                    "type": "integer",
+                    "example": 300
+                },
+                "challenge_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "description": "ExpiresIn is the lifetime of the token in seconds",
+                    "type": "integer",
+                    "example": 900
+                },
+                "mfa_required": {
+                    "description": "MFARequired tells that the user has two-factor authentication, the tokens are then left empty and the\nchallenge token is exchanged for them with the code of the authenticator",
+                    "type": "boolean",
+                    "example": false
+                },
+                "refresh_expires_in": {
+                    "description": "RefreshExpiresIn is the lifetime of the refresh token in seconds",
+                    "type": "integer",
+                    "example": 324000
+                },
+                "refresh_token": {
+                    "description": "RefreshToken is exchanged for a new pair of tokens once the token expires, it can be used only once",
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "MarkConversationReadInput": {
+            "type": "object",
+            "required": [
+                "message_id"
+            ],
+            "properties": {
+                "message_id": {
+                    "description": "MessageID is the latest message read, every message up to it is marked as read",
+                    "type": "string"
+                }
+            }
+        },
+        "Message": {
+            "type": "object",
+            "required": [
+                "conversation_id",
+                "sender_id"
+            ],
+            "properties": {
+                "attachments": {
+                    "description": "Attachments are the files sent with the message, filled in when messages are listed",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Attachment"
+                    }
+                },
+                "content": {
+                    "type": "string",
+                    "example": "hi how are you"
+                },
+                "conversation_id": {
+                    "type": "string",
+                    "example": ""
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
+                "edited_at": {
+                    "description": "EditedAt is set once the content has been edited",
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
+                "id": {
+                    "type": "string",
+                    "example": ""
+                },
+                "last_reply_at": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
+                "reactions": {
+                    "description": "Reactions are the reactions aggregated per emoji, filled in when messages are listed",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ReactionSummary"
+                    }
+                },
+                "reply_count": {
+                    "description": "ReplyCount and LastReplyAt summarize the thread of a root message",
+                    "type": "integer",
+                    "example": 3
+                },
+                "reply_to_id": {
+                    "description": "ReplyToID is the message quoted by this one",
+                    "type": "string"
+                },
+                "sender_id": {
+                    "type": "string",
+                    "example": ""
+                },
+                "thread_root_id": {
+                    "description": "ThreadRootID is the first message of the thread this one replies in",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                }
+            }
+        },
+        "MessageEdit": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "hi how are you"
+                },
+                "created_at": {
+                    "description": "CreatedAt is when the content was replaced",
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
+                "edited_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": ""
+                },
+                "message_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "MessageSearchResult": {
+            "type": "object",
+            "required": [
+                "conversation_id",
+                "sender_id"
+            ],
+            "properties": {
+                "attachments": {
+                    "description": "Attachments are the files sent with the message, filled in when messages are listed",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Attachment"
+                    }
+                },
+                "content": {
+                    "type": "string",
+                    "example": "hi how are you"
+                },
+                "conversation_id": {
+                    "type": "string",
+                    "example": ""
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
+                "edited_at": {
+                    "description": "EditedAt is set once the content has been edited",
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
+                "id": {
+                    "type": "string",
+                    "example": ""
+                },
+                "last_reply_at": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
+                "rank": {
+                    "description": "Rank is the relevance of the message to the search, results are ordered by it",
+                    "type": "number",
+                    "example": 0.0759909
+                },
+                "reactions": {
+                    "description": "Reactions are the reactions aggregated per emoji, filled in when messages are listed",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ReactionSummary"
+                    }
+                },
+                "reply_count": {
+                    "description": "ReplyCount and LastReplyAt summarize the thread of a root message",
+                    "type": "integer",
+                    "example": 3
+                },
+                "reply_to_id": {
+                    "description": "ReplyToID is the message quoted by this one",
+                    "type": "string"
+                },
+                "sender_id": {
+                    "type": "string",
+                    "example": ""
+                },
+                "snippet": {
+                    "description": "Snippet is an HTML escaped excerpt of the content with the matching words wrapped in \u003cmark\u003e tags",
+                    "type": "string",
+                    "example": "we \u003cmark\u003edecided\u003c/mark\u003e to ship on friday"
+                },
+                "thread_root_id": {
+                    "description": "ThreadRootID is the first message of the thread this one replies in",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
                 }
             }
         },
-        "JSONB": {
-            "type": "object",
-            "additionalProperties": true
-        },
-        "LoginInput": {
+        "MessageStatus": {
             "type": "object",
+            "required": [
+                "m_status",
+                "message_id",
+                "recipient_id"
+            ],
             "properties": {
-                "password": {
+                "id": {
+                    "type": "string",
+                    "example": ""
+                },
+                "m_status": {
+                    "enum": [
+                        "Sent",
+                        "Delivered",
+                        "Read"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/Mstatus"
+                        }
+                    ]
+                },
+                "message_id": {
                     "type": "string"
                 },
-                "user_name": {
+                "recipient_id": {
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string",
-                    "example": "+919984778491 or example"
+                    "example": ""
                 }
             }
         },
-        "LoginOutput": {
+        "Mstatus": {
+            "type": "string",
+            "enum": [
+                "Sent",
+                "Delivered",
+                "Read"
+            ],
+            "x-enum-varnames": [
+                "MstatusSent",
+                "MstatusDelivered",
+                "MstatusRead"
+            ]
+        },
+        "MuteParticipantInput": {
             "type": "object",
             "properties": {
-                "expires_in": {
-                    "type": "integer"
-                },
-                "token": {
-                    "type": "string"
+                "duration": {
+                    "description": "Duration of the mute in hours, 0 lifts the mute",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 24
                 }
             }
         },
-        "NotFoundError": {
-            "type": "object"
+        "ParticipantRole": {
+            "type": "string",
+            "enum": [
+                "OWNER",
+                "ADMIN",
+                "MEMBER"
+            ],
+            "x-enum-varnames": [
+                "ParticipantRoleOWNER",
+                "ParticipantRoleADMIN",
+                "ParticipantRoleMEMBER"
+            ]
         },
         "Personnel": {
             "type": "object",
@@ -1116,7 +5546,7 @@ const docTemplate = `{
                 },
                 "avatar": {
                     "type": "string",
-                    "example": "https://example.com/avatar.jpg"
+                    "example": "https://example.com/api/v1/avatars/12345678-1234-1234-1234-123456789012/87654321-4321-4321-4321-210987654321"
                 },
                 "created_at": {
                     "type": "string",
@@ -1142,6 +5572,10 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Rizwan"
                 },
+                "last_seen_at": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
                 "mobile": {
                     "type": "string",
                     "example": "+919984778492"
@@ -1164,23 +5598,184 @@ const docTemplate = `{
                 }
             }
         },
-        "Product": {
+        "Presence": {
+            "type": "object",
+            "properties": {
+                "last_seen_at": {
+                    "description": "LastSeenAt is when the personnel was last connected",
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
+                "personnel_id": {
+                    "type": "string",
+                    "example": "12345678-1234-1234-1234-123456789012"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/PresenceStatus"
+                        }
+                    ],
+                    "example": "ONLINE"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "12345678-1234-1234-1234-123456789012"
+                }
+            }
+        },
+        "PresenceStatus": {
+            "type": "string",
+            "enum": [
+                "ONLINE",
+                "AWAY",
+                "OFFLINE"
+            ],
+            "x-enum-varnames": [
+                "PresenceStatusONLINE",
+                "PresenceStatusAWAY",
+                "PresenceStatusOFFLINE"
+            ]
+        },
+        "ReactionSummary": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "emoji": {
+                    "type": "string",
+                    "example": "👍"
+                },
+                "personnel_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "reacted_by_me": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "RecoveryCodesOutput": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "description": "RecoveryCodes each stand in for the authenticator once, they are shown only this time",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "dp4bm-tgcs3",
+                        "nlgqr-te4hw"
+                    ]
+                }
+            }
+        },
+        "RefreshTokenInput": {
             "type": "object",
+            "required": [
+                "refresh_token"
+            ],
             "properties": {
-                "description": {
+                "refresh_token": {
                     "type": "string"
+                }
+            }
+        },
+        "RequestOTPInput": {
+            "type": "object",
+            "required": [
+                "user_name"
+            ],
+            "properties": {
+                "user_name": {
+                    "type": "string",
+                    "example": "+919984778491"
+                }
+            }
+        },
+        "RequestOTPOutput": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "description": "ExpiresIn is the lifetime of the code in seconds",
+                    "type": "integer",
+                    "example": 300
+                },
+                "resend_in": {
+                    "description": "ResendIn is the number of seconds before another code can be requested",
+                    "type": "integer",
+                    "example": 60
+                }
+            }
+        },
+        "ResetPasswordInput": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "correct horse battery staple"
+                },
+                "token": {
+                    "type": "string",
+                    "maxLength": 128
+                }
+            }
+        },
+        "Session": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
+                "current": {
+                    "description": "Current tells whether the session is the one of the auth token of the request",
+                    "type": "boolean",
+                    "example": true
+                },
+                "device_name": {
+                    "type": "string",
+                    "example": "Chrome on Windows"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2022-02-20 09:35:10.535606+05:30"
                 },
                 "id": {
-                    "type": "integer"
+                    "type": "string",
+                    "example": ""
                 },
-                "image": {
-                    "type": "string"
+                "ip_address": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "last_used_at": {
+                    "description": "LastUsedAt is when the session last logged in or refreshed its tokens",
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
                 },
-                "price": {
-                    "type": "number"
+                "mfa": {
+                    "description": "MFA tells whether the login gave the code of an authenticator, which roles such as ADMIN need",
+                    "type": "boolean",
+                    "example": true
                 },
-                "title": {
-                    "type": "string"
+                "user_agent": {
+                    "type": "string",
+                    "example": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) ..."
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "12345678-1234-1234-1234-123456789012"
                 }
             }
         },
@@ -1212,6 +5807,20 @@ const docTemplate = `{
                 }
             }
         },
+        "TOTPCodeInput": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "description": "Code is the current code of the authenticator, or a recovery code where accepted",
+                    "type": "string",
+                    "maxLength": 32,
+                    "example": "123456"
+                }
+            }
+        },
         "UnauthorizedError": {
             "type": "object",
             "properties": {
@@ -1225,6 +5834,57 @@ const docTemplate = `{
                 }
             }
         },
+        "UpdateMessageInput": {
+            "type": "object",
+            "required": [
+                "content"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string"
+                }
+            }
+        },
+        "UpdateMessageStatusInput": {
+            "type": "object",
+            "required": [
+                "m_status"
+            ],
+            "properties": {
+                "m_status": {
+                    "enum": [
+                        "Sent",
+                        "Delivered",
+                        "Read"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/Mstatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "UpdateParticipantRoleInput": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "enum": [
+                        "ADMIN",
+                        "MEMBER"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/ParticipantRole"
+                        }
+                    ],
+                    "example": "ADMIN"
+                }
+            }
+        },
         "UpdatePersonnelInput": {
             "type": "object",
             "properties": {
@@ -1239,10 +5899,6 @@ const docTemplate = `{
                 "address": {
                     "$ref": "#/definitions/Address"
                 },
-                "avatar": {
-                    "type": "string",
-                    "example": "https://example.com/avatar.jpg"
-                },
                 "email": {
                     "type": "string",
                     "example": "expertkhan@gmail.com"
@@ -1280,6 +5936,11 @@ const docTemplate = `{
                     "type": "string",
                     "example": ""
                 },
+                "mobile_verified_at": {
+                    "description": "MobileVerifiedAt is when a one time password sent to the user name was first verified",
+                    "type": "string",
+                    "example": "2022-02-16 15:35:10.535606+05:30"
+                },
                 "role": {
                     "type": "string",
                     "example": "ADMIN"
@@ -1294,6 +5955,62 @@ const docTemplate = `{
                 }
             }
         },
+        "UserError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "INVALID_REQUEST"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Oops! Something went wrong. Please try again later"
+                }
+            }
+        },
+        "VerifyMFAInput": {
+            "type": "object",
+            "required": [
+                "challenge_token",
+                "code"
+            ],
+            "properties": {
+                "challenge_token": {
+                    "type": "string",
+                    "maxLength": 128
+                },
+                "code": {
+                    "description": "Code is the current code of the authenticator or an unused recovery code",
+                    "type": "string",
+                    "maxLength": 32,
+                    "example": "123456"
+                }
+            }
+        },
+        "VerifyOTPInput": {
+            "type": "object",
+            "required": [
+                "code",
+                "user_name"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 12,
+                    "example": "123456"
+                },
+                "device_name": {
+                    "description": "DeviceName names the session of the login, it is made up from the user agent when left empty",
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Rizwan's phone"
+                },
+                "user_name": {
+                    "type": "string",
+                    "example": "+919984778491"
+                }
+            }
+        },
         "github_com_chatApp_internal_domain.UserRole": {
             "type": "string",
             "enum": [
@@ -1304,6 +6021,48 @@ const docTemplate = `{
                 "UserRoleAmin",
                 "UserRoleUser"
             ]
+        },
+        "github_com_chatApp_internal_pkg_security.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "description": "Crv and X are the curve and public key of Ed25519 keys",
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "description": "N and E are the modulus and exponent of RSA keys",
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_chatApp_internal_pkg_security.JWKS": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_chatApp_internal_pkg_security.JWK"
+                    }
+                }
+            }
         }
     },
     "securityDefinitions": {
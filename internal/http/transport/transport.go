@@ -1,9 +1,13 @@
 package transport
 
 import (
+	"encoding/base64"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
 
 	"github.com/go-playground/validator"
 	"github.com/labstack/echo/v4"
@@ -14,6 +18,9 @@ import (
 
 const PageMax = 500
 
+// CursorPageDefault is the size of a keyset page when none is requested
+const CursorPageDefault = 50
+
 // DecodeQueryOptions decodes the query options
 func DecodeQueryOptions(ctx echo.Context) domain.QueryOptions {
 	limit, offset := GetLimitAndOffset(ctx)
@@ -127,6 +134,176 @@ func SendPaginationResponse(ctx echo.Context, status int, data interface{}, tota
 	return ctx.JSON(status, finalResult)
 }
 
+// DecodeCursorQuery decodes the before/after cursors and the size from the query params
+func DecodeCursorQuery(ctx echo.Context) (q domain.CursorQuery, err error) {
+	invalidCursor := domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: domain.MessageCURSORINVALID}
+	before := ctx.QueryParam("before")
+	after := ctx.QueryParam("after")
+	if before != "" && after != "" {
+		return q, invalidCursor
+	}
+	if before != "" {
+		c, err := DecodeCursor(before)
+		if err != nil {
+			return q, invalidCursor
+		}
+		q.Before = &c
+	}
+	if after != "" {
+		c, err := DecodeCursor(after)
+		if err != nil {
+			return q, invalidCursor
+		}
+		q.After = &c
+	}
+
+	size, _ := strconv.Atoi(ctx.QueryParam("size"))
+	switch {
+	case size > PageMax:
+		size = PageMax
+	case size <= 0:
+		size = CursorPageDefault
+	}
+	q.Limit = int64(size)
+	return q, nil
+}
+
+// DecodeSearchQuery decodes the search query, its filters, the after cursor and the size from the query params
+func DecodeSearchQuery(ctx echo.Context) (in domain.SearchMessagesInput, err error) {
+	invalidFilter := domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: domain.MessageSEARCHFILTER}
+	in.Query = ctx.QueryParam("q")
+	if v := ctx.QueryParam("sender_id"); v != "" {
+		id, err := uuid.FromString(v)
+		if err != nil {
+			return in, invalidFilter
+		}
+		in.SenderID = &id
+	}
+	if v := ctx.QueryParam("conversation_id"); v != "" {
+		id, err := uuid.FromString(v)
+		if err != nil {
+			return in, invalidFilter
+		}
+		in.ConversationID = &id
+	}
+	if v := ctx.QueryParam("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return in, invalidFilter
+		}
+		in.From = &t
+	}
+	if v := ctx.QueryParam("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return in, invalidFilter
+		}
+		in.To = &t
+	}
+	if v := ctx.QueryParam("after"); v != "" {
+		c, err := DecodeSearchCursor(v)
+		if err != nil {
+			return in, domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: domain.MessageCURSORINVALID}
+		}
+		in.After = &c
+	}
+
+	size, _ := strconv.Atoi(ctx.QueryParam("size"))
+	switch {
+	case size > PageMax:
+		size = PageMax
+	case size <= 0:
+		size = CursorPageDefault
+	}
+	in.Limit = int64(size)
+	return in, nil
+}
+
+// SendCursorPaginationResponse sends a keyset paginated response
+func SendCursorPaginationResponse(ctx echo.Context, status int, data interface{}, page domain.CursorPage, size int64) error {
+	finalResult := domain.CursorPaginationResponse{
+		Data: data,
+		Size: size,
+	}
+	if page.Next != nil {
+		finalResult.NextCursor = EncodeCursor(*page.Next)
+	}
+	if page.Prev != nil {
+		finalResult.PrevCursor = EncodeCursor(*page.Prev)
+	}
+	if status == http.StatusNoContent {
+		return ctx.NoContent(status)
+	}
+	return ctx.JSON(status, finalResult)
+}
+
+// EncodeCursor encodes a cursor into an opaque url safe string
+func EncodeCursor(c domain.Cursor) string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor decodes a cursor produced by EncodeCursor
+func DecodeCursor(v string) (c domain.Cursor, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil {
+		return c, err
+	}
+	createdAt, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return c, domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: domain.MessageCURSORINVALID}
+	}
+	c.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return c, err
+	}
+	c.ID, err = uuid.FromString(id)
+	if err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// SendSearchPaginationResponse sends a page of search results, the next cursor fetches the following page with ?after=
+func SendSearchPaginationResponse(ctx echo.Context, status int, data interface{}, next *domain.SearchCursor, size int64) error {
+	finalResult := domain.CursorPaginationResponse{
+		Data: data,
+		Size: size,
+	}
+	if next != nil {
+		finalResult.NextCursor = EncodeSearchCursor(*next)
+	}
+	return ctx.JSON(status, finalResult)
+}
+
+// EncodeSearchCursor encodes a search cursor into an opaque url safe string
+func EncodeSearchCursor(c domain.SearchCursor) string {
+	raw := strconv.FormatFloat(float64(c.Rank), 'g', -1, 32) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeSearchCursor decodes a search cursor produced by EncodeSearchCursor
+func DecodeSearchCursor(v string) (c domain.SearchCursor, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil {
+		return c, err
+	}
+	rank, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return c, domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: domain.MessageCURSORINVALID}
+	}
+	r, err := strconv.ParseFloat(rank, 32)
+	if err != nil {
+		return c, err
+	}
+	c.Rank = float32(r)
+	c.ID, err = uuid.FromString(id)
+	if err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
 // CustomValidator custom validator for echo
 type CustomValidator struct {
 	Validator *validator.Validate
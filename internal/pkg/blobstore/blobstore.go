@@ -0,0 +1,45 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/chatApp/internal/pkg/config"
+)
+
+const (
+	// DriverLocal keeps blobs on the local filesystem
+	DriverLocal = "local"
+	// DriverS3 keeps blobs in an S3 compatible bucket, such as AWS S3 or MinIO
+	DriverS3 = "s3"
+)
+
+// defaultLocalPath is where the local driver keeps blobs when BLOBSTORE_LOCAL_PATH is not set
+const defaultLocalPath = "data/blobs"
+
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore defines the methods that any blob storage implementation should implement
+type BlobStore interface {
+	// Put stores the content read from r under the key, replacing any previous blob
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (err error)
+	// Get opens the blob stored under the key, it fails with ErrNotFound when there is none
+	Get(ctx context.Context, key string) (rc io.ReadCloser, err error)
+	// Delete removes the blob stored under the key, removing a missing blob is not an error
+	Delete(ctx context.Context, key string) (err error)
+}
+
+// NewBlobStore creates the blob store configured by BLOBSTORE_DRIVER, defaulting to the local filesystem
+func NewBlobStore(cfg config.ChatApiConfig) (BlobStore, error) {
+	switch cfg.BlobStoreDriver {
+	case DriverS3:
+		return NewS3BlobStore(cfg.S3Endpoint, cfg.S3AccessKey, cfg.S3SecretKey, cfg.S3Bucket, cfg.S3Region, cfg.S3UseSSL)
+	default:
+		path := cfg.BlobStoreLocalPath
+		if path == "" {
+			path = defaultLocalPath
+		}
+		return NewLocalBlobStore(path)
+	}
+}
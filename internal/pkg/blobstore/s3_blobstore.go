@@ -0,0 +1,53 @@
+package blobstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type s3BlobStore struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3BlobStore creates a blob store keeping every blob as an object of the bucket, it works with AWS S3 as well as
+// with S3 compatible stores such as MinIO
+func NewS3BlobStore(endpoint, accessKey, secretKey, bucket, region string, useSSL bool) (BlobStore, error) {
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure: useSSL,
+		Region: region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 client: %v", err)
+	}
+	return &s3BlobStore{client: client, bucket: bucket}, nil
+}
+
+// Put implements BlobStore.
+func (s *s3BlobStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (err error) {
+	_, err = s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+// Get implements BlobStore.
+func (s *s3BlobStore) Get(ctx context.Context, key string) (rc io.ReadCloser, err error) {
+	// GetObject is lazy, stat the object so a missing blob is reported here rather than on the first read
+	_, err = s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+}
+
+// Delete implements BlobStore.
+func (s *s3BlobStore) Delete(ctx context.Context, key string) (err error) {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
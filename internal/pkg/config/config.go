@@ -13,6 +13,12 @@ type ChatApiConfig struct {
 	AppName string `mapstructure:"APP_NAME"`
 	AppEnv  string `mapstructure:"APP_ENV"`
 	AppPort int    `mapstructure:"APP_PORT"`
+	// PublicBaseUrl is the URL clients reach the API at, such as https://chat.example.com, it prefixes the URLs
+	// handed out to clients and they are left relative when it is not set
+	PublicBaseUrl string `mapstructure:"PUBLIC_BASE_URL"`
+	// TrustedProxies is the comma separated list of the addresses or CIDR ranges of the reverse proxies whose
+	// X-Forwarded-For header is believed, the address of the connection is the client's when it is empty
+	TrustedProxies []string `mapstructure:"TRUSTED_PROXIES"`
 
 	DatabaseHost     string `mapstructure:"DB_HOST"`
 	DatabasePort     string `mapstructure:"DB_PORT"`
@@ -25,8 +31,90 @@ type ChatApiConfig struct {
 	SwaggerUsername   string `mapstructure:"SWAGGER_USERNAME"`
 	SwaggerPassword   string `mapstructure:"SWAGGER_PASSWORD"`
 
-	AuthSecret       string `mapstructure:"AUTH_SECRET"`
-	AuthExpiryPeriod int    `mapstructure:"AUTH_EXPIRY_PERIOD"`
+	AuthSecret string `mapstructure:"AUTH_SECRET"`
+	// AuthExpiryPeriod is the number of hours a login lasts without being used, the lifetime of refresh tokens
+	AuthExpiryPeriod int `mapstructure:"AUTH_EXPIRY_PERIOD"`
+	// AuthAccessExpiryPeriod is the lifetime of access tokens in minutes
+	AuthAccessExpiryPeriod int `mapstructure:"AUTH_ACCESS_EXPIRY_PERIOD"`
+	// AuthSigningKeys is the comma separated list of the PEM files of the RSA or Ed25519 keys auth tokens are
+	// verified with, the first private key signs them. AuthSecret signs them with HS256 when it is empty.
+	AuthSigningKeys []string `mapstructure:"AUTH_SIGNING_KEYS"`
+
+	PubSubDriver string `mapstructure:"PUBSUB_DRIVER"`
+
+	SMSDriver   string `mapstructure:"SMS_DRIVER"`
+	SMSFilePath string `mapstructure:"SMS_FILE_PATH"`
+	// OTPLength is the number of digits of one time passwords
+	OTPLength int `mapstructure:"OTP_LENGTH"`
+	// OTPExpiryPeriod is the number of minutes a one time password is valid
+	OTPExpiryPeriod int `mapstructure:"OTP_EXPIRY_PERIOD"`
+	// OTPMaxAttempts is the number of wrong guesses after which a one time password stops working
+	OTPMaxAttempts int `mapstructure:"OTP_MAX_ATTEMPTS"`
+	// OTPResendCooldown is the number of seconds before another one time password can be sent to a number
+	OTPResendCooldown int `mapstructure:"OTP_RESEND_COOLDOWN"`
+
+	NotifierDriver string `mapstructure:"NOTIFIER_DRIVER"`
+	// PasswordResetExpiryPeriod is the number of minutes a password reset token is valid
+	PasswordResetExpiryPeriod int `mapstructure:"PASSWORD_RESET_EXPIRY_PERIOD"`
+	// PasswordResetUrl is the page of the client that resets passwords, the token is sent as its token query param.
+	// The token is sent alone when it is empty.
+	PasswordResetUrl string `mapstructure:"PASSWORD_RESET_URL"`
+	// PasswordHash is the algorithm passwords are hashed with, argon2id unless it is bcrypt. Hashes made with the other
+	// one still verify and are replaced at the next login.
+	PasswordHash string `mapstructure:"PASSWORD_HASH"`
+	// PasswordMinLength is the least number of characters of a password
+	PasswordMinLength int `mapstructure:"PASSWORD_MIN_LENGTH"`
+	// PasswordCharacterClasses is the comma separated list of the classes a password needs a character of, out of
+	// lower, upper, digit and symbol
+	PasswordCharacterClasses []string `mapstructure:"PASSWORD_CHARACTER_CLASSES"`
+	// PasswordCommonList is a file of passwords, one per line, refused on top of the built-in list of common ones
+	PasswordCommonList string `mapstructure:"PASSWORD_COMMON_LIST"`
+	// PasswordHistory is the number of previous passwords a new password may not repeat, 0 allows any
+	PasswordHistory int `mapstructure:"PASSWORD_HISTORY"`
+
+	// LoginMaxAttempts is the number of failed logins of a user name that lock it out
+	LoginMaxAttempts int `mapstructure:"LOGIN_MAX_ATTEMPTS"`
+	// LoginIPMaxAttempts is the number of failed logins from an IP address that lock it out
+	LoginIPMaxAttempts int `mapstructure:"LOGIN_IP_MAX_ATTEMPTS"`
+	// LoginLockoutPeriod is the number of minutes a lockout lasts, and failures older than it are forgotten
+	LoginLockoutPeriod int `mapstructure:"LOGIN_LOCKOUT_PERIOD"`
+
+	// TOTPEncryptionKey is the secret authenticator secrets are encrypted with, AuthSecret when it is empty
+	TOTPEncryptionKey string `mapstructure:"TOTP_ENCRYPTION_KEY"`
+	// MFAChallengeExpiryPeriod is the number of minutes a login has to give the code of its authenticator
+	MFAChallengeExpiryPeriod int `mapstructure:"MFA_CHALLENGE_EXPIRY_PERIOD"`
+	// MFAMaxAttempts is the number of wrong codes after which a login challenge stops working
+	MFAMaxAttempts int `mapstructure:"MFA_MAX_ATTEMPTS"`
+
+	ConversationInviteExpiryPeriod int `mapstructure:"CONVERSATION_INVITE_EXPIRY_PERIOD"`
+	// MessageEditWindow is the number of minutes a message can be edited after it is sent, 0 allows edits at any time
+	MessageEditWindow int `mapstructure:"MESSAGE_EDIT_WINDOW"`
+
+	BlobStoreDriver    string `mapstructure:"BLOBSTORE_DRIVER"`
+	BlobStoreLocalPath string `mapstructure:"BLOBSTORE_LOCAL_PATH"`
+	S3Endpoint         string `mapstructure:"S3_ENDPOINT"`
+	S3AccessKey        string `mapstructure:"S3_ACCESS_KEY"`
+	S3SecretKey        string `mapstructure:"S3_SECRET_KEY"`
+	S3Bucket           string `mapstructure:"S3_BUCKET"`
+	S3Region           string `mapstructure:"S3_REGION"`
+	S3UseSSL           bool   `mapstructure:"S3_USE_SSL"`
+
+	// AttachmentMaxSize is the largest attachment accepted, in megabytes
+	AttachmentMaxSize int `mapstructure:"ATTACHMENT_MAX_SIZE"`
+	// AttachmentAllowedTypes is the comma separated list of accepted MIME types, a type may end with /* to accept its subtypes
+	AttachmentAllowedTypes []string `mapstructure:"ATTACHMENT_ALLOWED_TYPES"`
+	// AttachmentThumbnailSizes is the comma separated list of the boxes, in pixels, image thumbnails are scaled to fit
+	AttachmentThumbnailSizes []int `mapstructure:"ATTACHMENT_THUMBNAIL_SIZES"`
+
+	// AvatarMaxSize is the largest avatar image accepted, in megabytes
+	AvatarMaxSize int `mapstructure:"AVATAR_MAX_SIZE"`
+	// AvatarSizes is the comma separated list of the square sizes, in pixels, avatars are stored in
+	AvatarSizes []int `mapstructure:"AVATAR_SIZES"`
+
+	// WorkerPoolSize is the number of background tasks, such as image processing, run at once
+	WorkerPoolSize int `mapstructure:"WORKER_POOL_SIZE"`
+	// WorkerQueueSize is the number of background tasks that may wait for a worker before new ones are refused
+	WorkerQueueSize int `mapstructure:"WORKER_QUEUE_SIZE"`
 }
 
 type Options struct {
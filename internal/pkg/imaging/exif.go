@@ -0,0 +1,328 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+)
+
+const (
+	markerSOI  = 0xD8
+	markerSOS  = 0xDA
+	markerAPP1 = 0xE1
+
+	tagOrientation = 0x0112
+	tagGPSInfo     = 0x8825
+)
+
+var (
+	exifHeader   = []byte("Exif\x00\x00")
+	xmpHeader    = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+)
+
+// webpFlagXMP is the bit of the VP8X flags telling that a WebP file has XMP data
+const webpFlagXMP = 0x04
+
+// exifTypeSizes are the sizes in bytes of the TIFF field types, indexed by type
+var exifTypeSizes = [...]int{0, 1, 1, 2, 4, 8, 1, 1, 2, 4, 8, 4, 8}
+
+// jpegSegment is a marker segment of a JPEG file, start and end delimit it including its marker
+type jpegSegment struct {
+	marker     byte
+	start, end int
+}
+
+// payload returns the content of the segment following its marker and length
+func (s jpegSegment) payload(data []byte) []byte {
+	return data[s.start+4 : s.end]
+}
+
+// jpegSegments returns the marker segments that precede the image data of a JPEG file
+func jpegSegments(data []byte) (segments []jpegSegment) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != markerSOI {
+		return nil
+	}
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		if marker == 0xFF {
+			// fill byte
+			i++
+			continue
+		}
+		if marker == markerSOS {
+			break
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) || end < i+4 {
+			break
+		}
+		segments = append(segments, jpegSegment{marker: marker, start: i, end: end})
+		i = end
+	}
+	return segments
+}
+
+// StripLocation removes the location a JPEG, PNG or WebP file was taken at, the GPS fields of its EXIF data are
+// blanked and its XMP packets, which may repeat them, are dropped. It returns the data unchanged and false when there
+// was nothing to remove, the pixels are never re-encoded. PNG and WebP files whose chunks cannot be read fail with
+// ErrMalformedImage, other formats carry no EXIF data and are returned unchanged.
+func StripLocation(data []byte) (out []byte, changed bool, err error) {
+	switch {
+	case bytes.HasPrefix(data, pngSignature):
+		return stripPNGLocation(data)
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return stripWebPLocation(data)
+	}
+	out, changed = stripJPEGLocation(data)
+	return out, changed, nil
+}
+
+// stripJPEGLocation blanks the GPS fields of the EXIF data of a JPEG file and drops its XMP packets
+func stripJPEGLocation(data []byte) (out []byte, changed bool) {
+	segments := jpegSegments(data)
+	if len(segments) == 0 {
+		return data, false
+	}
+	out = make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+	last := 2
+	for _, s := range segments {
+		if s.marker != markerAPP1 {
+			continue
+		}
+		p := s.payload(data)
+		if bytes.HasPrefix(p, xmpHeader) {
+			out = append(out, data[last:s.start]...)
+			last = s.end
+			changed = true
+			continue
+		}
+		if bytes.HasPrefix(p, exifHeader) {
+			// the fields are blanked in place so every offset in the EXIF data stays valid
+			tiff := make([]byte, len(p)-len(exifHeader))
+			copy(tiff, p[len(exifHeader):])
+			if blankGPS(tiff) {
+				out = append(out, data[last:s.start+4+len(exifHeader)]...)
+				out = append(out, tiff...)
+				last = s.end
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return data, false
+	}
+	return append(out, data[last:]...), true
+}
+
+// stripPNGLocation blanks the GPS fields of the eXIf chunk of a PNG file and drops its XMP and raw profile text
+// chunks, anything after the IEND chunk is dropped as well
+func stripPNGLocation(data []byte) (out []byte, changed bool, err error) {
+	out = make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	i := len(pngSignature)
+	for {
+		if i+12 > len(data) {
+			return nil, false, ErrMalformedImage
+		}
+		n := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + n
+		if n < 0 || end > len(data) || end < i {
+			return nil, false, ErrMalformedImage
+		}
+		typ := string(data[i+4 : i+8])
+		content := data[i+8 : i+8+n]
+		switch typ {
+		case "eXIf":
+			chunk := bytes.Clone(data[i:end])
+			if blankGPS(chunk[8 : 8+n]) {
+				binary.BigEndian.PutUint32(chunk[8+n:], crc32.ChecksumIEEE(chunk[4:8+n]))
+				out = append(out, chunk...)
+				changed = true
+				i = end
+				continue
+			}
+		case "tEXt", "zTXt", "iTXt":
+			if pngLocationText(content) {
+				changed = true
+				i = end
+				continue
+			}
+		}
+		out = append(out, data[i:end]...)
+		i = end
+		if typ == "IEND" {
+			break
+		}
+	}
+	if i < len(data) {
+		changed = true
+	}
+	if !changed {
+		return data, false, nil
+	}
+	return out, true, nil
+}
+
+// pngLocationText tells whether a PNG text chunk may hold a location, XMP packets and the EXIF, APP1 and IPTC raw
+// profiles some tools write
+func pngLocationText(content []byte) bool {
+	keyword, _, _ := bytes.Cut(content, []byte{0})
+	return string(keyword) == "XML:com.adobe.xmp" || bytes.HasPrefix(keyword, []byte("Raw profile type"))
+}
+
+// stripWebPLocation blanks the GPS fields of the EXIF chunk of a WebP file and drops its XMP chunk, anything after
+// the RIFF container is dropped as well
+func stripWebPLocation(data []byte) (out []byte, changed bool, err error) {
+	size := int(binary.LittleEndian.Uint32(data[4:])) + 8
+	if size < 12 || size > len(data) {
+		return nil, false, ErrMalformedImage
+	}
+	out = make([]byte, 0, size)
+	out = append(out, data[:12]...)
+	flags := -1
+	for i := 12; i < size; {
+		if i+8 > size {
+			return nil, false, ErrMalformedImage
+		}
+		n := int(binary.LittleEndian.Uint32(data[i+4:]))
+		// chunks are padded to an even size
+		end := i + 8 + n + n&1
+		if n < 0 || end > size || end < i {
+			return nil, false, ErrMalformedImage
+		}
+		switch string(data[i : i+4]) {
+		case "XMP ":
+			changed = true
+			i = end
+			continue
+		case "EXIF":
+			chunk := bytes.Clone(data[i:end])
+			// some writers keep the header of the JPEG segment
+			tiff := bytes.TrimPrefix(chunk[8:8+n], exifHeader)
+			if blankGPS(tiff) {
+				out = append(out, chunk...)
+				changed = true
+				i = end
+				continue
+			}
+		case "VP8X":
+			if n > 0 {
+				flags = len(out) + 8
+			}
+		}
+		out = append(out, data[i:end]...)
+		i = end
+	}
+	if size < len(data) {
+		changed = true
+	}
+	if !changed {
+		return data, false, nil
+	}
+	if flags >= 0 {
+		// every XMP chunk has been dropped
+		out[flags] &^= webpFlagXMP
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, true, nil
+}
+
+// jpegOrientation returns the EXIF orientation of a JPEG file, 1 when it has none
+func jpegOrientation(data []byte) int {
+	for _, s := range jpegSegments(data) {
+		p := s.payload(data)
+		if s.marker != markerAPP1 || !bytes.HasPrefix(p, exifHeader) {
+			continue
+		}
+		tiff := p[len(exifHeader):]
+		order, ifd, ok := tiffHeader(tiff)
+		if !ok {
+			return 1
+		}
+		if entry, ok := findIFDEntry(tiff, order, ifd, tagOrientation); ok {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+		return 1
+	}
+	return 1
+}
+
+// tiffHeader returns the byte order and the offset of the first IFD of TIFF data
+func tiffHeader(tiff []byte) (order binary.ByteOrder, ifd int, ok bool) {
+	if len(tiff) < 8 {
+		return nil, 0, false
+	}
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, 0, false
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return nil, 0, false
+	}
+	return order, int(order.Uint32(tiff[4:])), true
+}
+
+// findIFDEntry returns the offset of the entry of the tag in the IFD
+func findIFDEntry(tiff []byte, order binary.ByteOrder, ifd int, tag uint16) (entry int, ok bool) {
+	if ifd < 0 || ifd+2 > len(tiff) {
+		return 0, false
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry = ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0, false
+		}
+		if order.Uint16(tiff[entry:]) == tag {
+			return entry, true
+		}
+	}
+	return 0, false
+}
+
+// blankGPS zeroes the GPS IFD of TIFF data along with the values it points to, and empties it
+func blankGPS(tiff []byte) bool {
+	order, ifd0, ok := tiffHeader(tiff)
+	if !ok {
+		return false
+	}
+	pointer, ok := findIFDEntry(tiff, order, ifd0, tagGPSInfo)
+	if !ok {
+		return false
+	}
+	gps := int(order.Uint32(tiff[pointer+8:]))
+	if gps < 8 || gps+2 > len(tiff) {
+		return false
+	}
+	count := int(order.Uint16(tiff[gps:]))
+	if count == 0 {
+		return false
+	}
+	for i := 0; i < count; i++ {
+		entry := gps + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		typ := int(order.Uint16(tiff[entry+2:]))
+		if typ > 0 && typ < len(exifTypeSizes) {
+			size := int64(order.Uint32(tiff[entry+4:])) * int64(exifTypeSizes[typ])
+			// values larger than 4 bytes are stored apart from the entry
+			if size > 4 {
+				offset := int64(order.Uint32(tiff[entry+8:]))
+				if offset >= 8 && offset+size <= int64(len(tiff)) {
+					clear(tiff[offset : offset+size])
+				}
+			}
+		}
+		clear(tiff[entry : entry+12])
+	}
+	// an empty IFD, its next IFD offset now reads as the zeroed first entry
+	order.PutUint16(tiff[gps:], 0)
+	return true
+}
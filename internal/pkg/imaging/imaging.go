@@ -0,0 +1,166 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	// register the decoders of the supported formats
+	_ "image/gif"
+
+	_ "golang.org/x/image/bmp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// MaxPixels is the largest image decoded, larger images are rejected before their pixels are allocated
+const MaxPixels = 50_000_000
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrImageTooLarge     = errors.New("image is too large")
+	ErrMalformedImage    = errors.New("malformed image")
+)
+
+// supportedTypes are the MIME types of the images that can be decoded
+var supportedTypes = map[string]struct{}{
+	"image/jpeg": {},
+	"image/png":  {},
+	"image/gif":  {},
+	"image/webp": {},
+	"image/bmp":  {},
+}
+
+// Supported reports whether images of the MIME type can be decoded
+func Supported(contentType string) bool {
+	_, ok := supportedTypes[contentType]
+	return ok
+}
+
+// Picture is a decoded image along with the EXIF orientation it is displayed with
+type Picture struct {
+	img         image.Image
+	orientation int
+}
+
+// Decode decodes the image, only the first frame of an animated image is decoded.
+// Images larger than MaxPixels are rejected before their pixels are allocated.
+func Decode(data []byte) (p Picture, err error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return p, ErrUnsupportedFormat
+		}
+		return p, err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > MaxPixels {
+		return p, ErrImageTooLarge
+	}
+	p.img, _, err = image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return p, err
+	}
+	if format == "jpeg" {
+		p.orientation = jpegOrientation(data)
+	}
+	return p, nil
+}
+
+// Width returns the width of the picture as it is displayed
+func (p Picture) Width() int {
+	if p.orientation >= 5 {
+		return p.img.Bounds().Dy()
+	}
+	return p.img.Bounds().Dx()
+}
+
+// Height returns the height of the picture as it is displayed
+func (p Picture) Height() int {
+	if p.orientation >= 5 {
+		return p.img.Bounds().Dx()
+	}
+	return p.img.Bounds().Dy()
+}
+
+// Thumbnail scales the picture down so it fits in a size x size box, keeping its aspect ratio.
+// The result is upright, the orientation is applied after scaling so only the small image is transformed.
+func (p Picture) Thumbnail(size int) image.Image {
+	b := p.img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return orient(p.img, p.orientation)
+	}
+	if w >= h {
+		h = max(1, h*size/w)
+		w = size
+	} else {
+		w = max(1, w*size/h)
+		h = size
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), p.img, b, draw.Src, nil)
+	return orient(dst, p.orientation)
+}
+
+// Square crops the largest centred square out of the picture and scales it to size x size, smaller pictures are
+// scaled up. The result is upright.
+func (p Picture) Square(size int) image.Image {
+	b := p.img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), p.img, image.Rect(x0, y0, x0+side, y0+side), draw.Src, nil)
+	return orient(dst, p.orientation)
+}
+
+// Encode encodes the image as a JPEG, or as a PNG when it has transparent pixels, and returns its MIME type
+func Encode(img image.Image) (data []byte, contentType string, err error) {
+	var buf bytes.Buffer
+	if o, ok := img.(interface{ Opaque() bool }); ok && !o.Opaque() {
+		err = png.Encode(&buf, img)
+		return buf.Bytes(), "image/png", err
+	}
+	err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 80})
+	return buf.Bytes(), "image/jpeg", err
+}
+
+// orient transforms the image as described by the EXIF orientation, 1 to 8
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	// orientations 5 to 8 swap the width and the height
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotated 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // mirrored along the top-left diagonal
+				dx, dy = y, x
+			case 6: // rotated 90 clockwise
+				dx, dy = h-1-y, x
+			case 7: // mirrored along the top-right diagonal
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90 counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
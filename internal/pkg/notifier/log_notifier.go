@@ -0,0 +1,20 @@
+package notifier
+
+import (
+	"context"
+	"log/slog"
+)
+
+type logNotifier struct{}
+
+// NewLogNotifier creates a notifier that writes notifications to the application log, it is meant for local
+// development
+func NewLogNotifier() Notifier {
+	return &logNotifier{}
+}
+
+// Notify implements Notifier.
+func (n *logNotifier) Notify(ctx context.Context, to string, subject string, message string) (err error) {
+	slog.Info("notification", "to", to, "subject", subject, "message", message)
+	return nil
+}
@@ -0,0 +1,33 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/chatApp/internal/pkg/config"
+	"github.com/chatApp/internal/pkg/sms"
+)
+
+const (
+	// DriverSMS texts notifications to the mobile number of the user through the SMS sender
+	DriverSMS = "sms"
+	// DriverLog writes notifications to the application log
+	DriverLog = "log"
+)
+
+// Notifier defines the methods that any channel account notices, such as password reset tokens, are delivered
+// through should implement
+type Notifier interface {
+	// Notify delivers the message to the recipient, the user name of a user, channels without subjects drop it
+	Notify(ctx context.Context, to string, subject string, message string) (err error)
+}
+
+// NewNotifier creates the notifier configured by NOTIFIER_DRIVER, defaulting to text messages since user names
+// are mobile numbers
+func NewNotifier(cfg config.ChatApiConfig, sender sms.SMSSender) (Notifier, error) {
+	switch cfg.NotifierDriver {
+	case DriverLog:
+		return NewLogNotifier(), nil
+	default:
+		return NewSMSNotifier(sender), nil
+	}
+}
@@ -0,0 +1,21 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/chatApp/internal/pkg/sms"
+)
+
+type smsNotifier struct {
+	sender sms.SMSSender
+}
+
+// NewSMSNotifier creates a notifier that texts the message to the recipient
+func NewSMSNotifier(sender sms.SMSSender) Notifier {
+	return &smsNotifier{sender: sender}
+}
+
+// Notify implements Notifier.
+func (n *smsNotifier) Notify(ctx context.Context, to string, subject string, message string) (err error) {
+	return n.sender.Send(ctx, to, message)
+}
@@ -0,0 +1,54 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// memoryPubSub delivers payloads to subscribers of the current process only.
+// It is meant for tests and single instance deployments.
+type memoryPubSub struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+// NewMemoryPubSub creates a new in-process PubSub
+func NewMemoryPubSub() PubSub {
+	return &memoryPubSub{
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Publish implements PubSub.
+func (p *memoryPubSub) Publish(ctx context.Context, channel string, payload []byte) (err error) {
+	p.mu.RLock()
+	h, ok := p.handlers[channel]
+	p.mu.RUnlock()
+	if ok {
+		h(channel, payload)
+	}
+	return nil
+}
+
+// Subscribe implements PubSub.
+func (p *memoryPubSub) Subscribe(channel string, handler Handler) (err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers[channel] = handler
+	return nil
+}
+
+// Unsubscribe implements PubSub.
+func (p *memoryPubSub) Unsubscribe(channel string) (err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.handlers, channel)
+	return nil
+}
+
+// Close implements PubSub.
+func (p *memoryPubSub) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers = make(map[string]Handler)
+}
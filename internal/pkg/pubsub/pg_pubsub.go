@@ -0,0 +1,184 @@
+package pubsub
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// maxPayloadSize is the largest payload NOTIFY accepts with the default server configuration
+	maxPayloadSize = 7999
+	// reconnectDelay is how long the listener waits before reconnecting after a failure
+	reconnectDelay = 2 * time.Second
+)
+
+// pgPubSub fans payloads out to every instance through Postgres LISTEN/NOTIFY.
+// A single pooled connection is held for LISTEN, and its channel set follows the
+// subscriptions. Payloads published while the listener reconnects are lost.
+type pgPubSub struct {
+	db *pgxpool.Pool
+
+	mu        sync.Mutex
+	handlers  map[string]Handler
+	interrupt context.CancelFunc
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPgPubSub creates a new PubSub backed by Postgres LISTEN/NOTIFY
+func NewPgPubSub(db *pgxpool.Pool) PubSub {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &pgPubSub{
+		db:       db,
+		handlers: make(map[string]Handler),
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	go p.listen(ctx)
+	return p
+}
+
+// Publish implements PubSub.
+func (p *pgPubSub) Publish(ctx context.Context, channel string, payload []byte) (err error) {
+	if len(payload) > maxPayloadSize {
+		return ErrPayloadTooLarge
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	q := `SELECT pg_notify($1, $2)`
+	_, err = p.db.Exec(ctx, q, channel, string(payload))
+	return err
+}
+
+// Subscribe implements PubSub.
+func (p *pgPubSub) Subscribe(channel string, handler Handler) (err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers[channel] = handler
+	p.wake()
+	return nil
+}
+
+// Unsubscribe implements PubSub.
+func (p *pgPubSub) Unsubscribe(channel string) (err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.handlers, channel)
+	p.wake()
+	return nil
+}
+
+// Close implements PubSub.
+func (p *pgPubSub) Close() {
+	p.cancel()
+	<-p.done
+}
+
+// wake interrupts the listener so it picks up subscription changes, the caller must hold p.mu
+func (p *pgPubSub) wake() {
+	if p.interrupt != nil {
+		p.interrupt()
+	}
+}
+
+// listen keeps a listener connection running until the pub/sub is closed
+func (p *pgPubSub) listen(ctx context.Context) {
+	defer close(p.done)
+	for ctx.Err() == nil {
+		err := p.run(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.Error("pubsub listener failed, reconnecting", "err", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(reconnectDelay):
+			}
+		}
+	}
+}
+
+// run listens on a single connection until it fails or the context is cancelled
+func (p *pgPubSub) run(ctx context.Context) (err error) {
+	conn, err := p.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// the connection holds LISTEN state, so it must not go back to the pool
+	defer func() {
+		_ = conn.Conn().Close(context.Background())
+		conn.Release()
+	}()
+
+	listening := make(map[string]bool)
+	for {
+		p.mu.Lock()
+		channels := make(map[string]bool, len(p.handlers))
+		for ch := range p.handlers {
+			channels[ch] = true
+		}
+		waitCtx, interrupt := context.WithCancel(ctx)
+		p.interrupt = interrupt
+		p.mu.Unlock()
+
+		err = p.syncChannels(ctx, conn.Conn(), listening, channels)
+		if err != nil {
+			interrupt()
+			return err
+		}
+
+		n, err := conn.Conn().WaitForNotification(waitCtx)
+		interrupt()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if waitCtx.Err() != nil {
+				// interrupted by a subscription change
+				continue
+			}
+			return err
+		}
+		p.dispatch(n.Channel, []byte(n.Payload))
+	}
+}
+
+// dispatch hands the payload to the handler subscribed to the channel
+func (p *pgPubSub) dispatch(channel string, payload []byte) {
+	p.mu.Lock()
+	h, ok := p.handlers[channel]
+	p.mu.Unlock()
+	if ok {
+		h(channel, payload)
+	}
+}
+
+// syncChannels issues LISTEN and UNLISTEN so the connection follows the subscribed channels
+func (p *pgPubSub) syncChannels(ctx context.Context, conn *pgx.Conn, listening map[string]bool, channels map[string]bool) (err error) {
+	for ch := range channels {
+		if listening[ch] {
+			continue
+		}
+		_, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{ch}.Sanitize())
+		if err != nil {
+			return err
+		}
+		listening[ch] = true
+	}
+	for ch := range listening {
+		if channels[ch] {
+			continue
+		}
+		_, err = conn.Exec(ctx, "UNLISTEN "+pgx.Identifier{ch}.Sanitize())
+		if err != nil {
+			return err
+		}
+		delete(listening, ch)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/chatApp/internal/pkg/config"
+)
+
+const (
+	// DriverPostgres fans payloads out to every instance through Postgres LISTEN/NOTIFY
+	DriverPostgres = "postgres"
+	// DriverMemory keeps payloads inside the current process
+	DriverMemory = "memory"
+)
+
+var ErrPayloadTooLarge = errors.New("pubsub payload is too large")
+
+// Handler is called with every payload published on a subscribed channel
+type Handler func(channel string, payload []byte)
+
+// PubSub defines the methods that any pub/sub implementation should implement
+type PubSub interface {
+	// Publish sends the payload to the subscribers of the channel
+	Publish(ctx context.Context, channel string, payload []byte) (err error)
+	// Subscribe starts delivering the payloads published on the channel to the handler
+	Subscribe(channel string, handler Handler) (err error)
+	// Unsubscribe stops delivering the payloads published on the channel
+	Unsubscribe(channel string) (err error)
+	// Close stops delivering payloads and releases the resources held by the pub/sub
+	Close()
+}
+
+// NewPubSub creates the pub/sub configured by PUBSUB_DRIVER, defaulting to Postgres
+func NewPubSub(cfg config.ChatApiConfig, db *pgxpool.Pool) PubSub {
+	switch cfg.PubSubDriver {
+	case DriverMemory:
+		return NewMemoryPubSub()
+	default:
+		return NewPgPubSub(db)
+	}
+}
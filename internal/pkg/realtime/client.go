@@ -0,0 +1,239 @@
+package realtime
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/gorilla/websocket"
+
+	"github.com/chatApp/internal/domain"
+)
+
+const (
+	// writeWait is the time allowed to write a frame to the peer
+	writeWait = 10 * time.Second
+	// pongWait is the time allowed to read the next frame from the peer
+	pongWait = 60 * time.Second
+	// pingPeriod is how often pings are sent, it must be less than pongWait
+	pingPeriod = (pongWait * 9) / 10
+	// maxMessageSize is the maximum size of a frame sent by the peer
+	maxMessageSize = 4096
+	// sendBufferSize is the number of outbound frames buffered per connection
+	sendBufferSize = 64
+	// heartbeatInterval is the least time between two presence heartbeats of a connection
+	heartbeatInterval = 30 * time.Second
+)
+
+// Client is a single websocket connection of a personnel
+type Client struct {
+	PersonnelID uuid.UUID
+	// SessionID is the session of the token the connection was opened with, the connection ends with it
+	SessionID uuid.UUID
+	// ConnectionID tells the connections of a personnel apart in their presence
+	ConnectionID uuid.UUID
+
+	hub       *Hub
+	presence  domain.PresenceService
+	typing    domain.TypingService
+	conn      *websocket.Conn
+	send      chan []byte
+	done      chan struct{}
+	once      sync.Once
+	closeCode int
+
+	// status and lastHeartbeat are only touched by the read pump
+	status        domain.PresenceStatus
+	lastHeartbeat time.Time
+	// typingIn holds the conversations the peer is typing in, it is only touched by the read pump
+	typingIn map[uuid.UUID]struct{}
+}
+
+// NewClient creates a new Client for the connection
+func NewClient(hub *Hub, presence domain.PresenceService, typing domain.TypingService, personnelID, sessionID uuid.UUID, conn *websocket.Conn) *Client {
+	return &Client{
+		PersonnelID:  personnelID,
+		SessionID:    sessionID,
+		ConnectionID: uuid.Must(uuid.NewV4()),
+		hub:          hub,
+		presence:     presence,
+		typing:       typing,
+		conn:         conn,
+		send:         make(chan []byte, sendBufferSize),
+		done:         make(chan struct{}),
+		status:       domain.PresenceStatusONLINE,
+		typingIn:     make(map[uuid.UUID]struct{}),
+	}
+}
+
+// Run registers the client with the hub and pumps frames until the connection is closed
+func (c *Client) Run() {
+	err := c.hub.Register(c)
+	if err != nil {
+		_ = c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(writeWait))
+		_ = c.conn.Close()
+		return
+	}
+	c.lastHeartbeat = time.Now()
+	err = c.presence.Connect(c.PersonnelID, c.ConnectionID)
+	if err != nil {
+		slog.Error("failed to record presence", "personnel_id", c.PersonnelID, "err", err)
+	}
+	go c.writePump()
+	c.readPump()
+}
+
+// Close asks the client to disconnect with the given close code
+func (c *Client) Close(code int) {
+	c.once.Do(func() {
+		c.closeCode = code
+		close(c.done)
+	})
+}
+
+// enqueue queues a frame for the peer, slow peers are disconnected rather than blocking the hub
+func (c *Client) enqueue(b []byte) {
+	select {
+	case <-c.done:
+	case c.send <- b:
+	default:
+		slog.Warn("dropping slow websocket client", "personnel_id", c.PersonnelID)
+		c.Close(websocket.ClosePolicyViolation)
+	}
+}
+
+// readPump reads frames from the peer until it goes away or stops answering pings
+func (c *Client) readPump() {
+	defer func() {
+		c.hub.Unregister(c)
+		c.Close(websocket.CloseNormalClosure)
+		// the peer cannot stop typing anymore, so stop for it
+		for conversationID := range c.typingIn {
+			err := c.typing.Stop(c.PersonnelID, conversationID)
+			if err != nil {
+				slog.Error("failed to stop typing", "personnel_id", c.PersonnelID, "conversation_id", conversationID, "err", err)
+			}
+		}
+		err := c.presence.Disconnect(c.PersonnelID, c.ConnectionID)
+		if err != nil {
+			slog.Error("failed to record presence", "personnel_id", c.PersonnelID, "err", err)
+		}
+	}()
+	c.conn.SetReadLimit(maxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		c.heartbeat()
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		_, b, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				slog.Warn("websocket closed unexpectedly", "personnel_id", c.PersonnelID, "err", err)
+			}
+			return
+		}
+		// any frame from the peer proves it is alive
+		_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		c.heartbeat()
+		c.handle(b)
+	}
+}
+
+// incomingEvent is an event sent by the peer, its payload is decoded according to its type
+type incomingEvent struct {
+	Type    domain.EventType `json:"type"`
+	Payload json.RawMessage  `json:"payload"`
+}
+
+// handle processes an event sent by the peer
+func (c *Client) handle(b []byte) {
+	var event incomingEvent
+	err := json.Unmarshal(b, &event)
+	if err != nil {
+		return
+	}
+	switch event.Type {
+	case domain.EventTypePing:
+		// browsers cannot send ping frames, so answer application level heartbeats
+		pong, _ := json.Marshal(domain.Event{Type: domain.EventTypePong})
+		c.enqueue(pong)
+	case domain.EventTypePresenceSet:
+		var in domain.SetPresenceInput
+		if json.Unmarshal(event.Payload, &in) != nil || in.Status == c.status {
+			return
+		}
+		err := c.presence.SetStatus(c.PersonnelID, c.ConnectionID, in.Status)
+		if err != nil {
+			slog.Warn("failed to set presence", "personnel_id", c.PersonnelID, "err", err)
+			return
+		}
+		c.status = in.Status
+	case domain.EventTypeTypingStart:
+		var in domain.Typing
+		if json.Unmarshal(event.Payload, &in) != nil || in.ConversationID.IsNil() {
+			return
+		}
+		err := c.typing.Start(c.PersonnelID, in.ConversationID)
+		if err != nil {
+			slog.Warn("failed to start typing", "personnel_id", c.PersonnelID, "conversation_id", in.ConversationID, "err", err)
+			return
+		}
+		c.typingIn[in.ConversationID] = struct{}{}
+	case domain.EventTypeTypingStop:
+		var in domain.Typing
+		if json.Unmarshal(event.Payload, &in) != nil {
+			return
+		}
+		if _, ok := c.typingIn[in.ConversationID]; !ok {
+			return
+		}
+		delete(c.typingIn, in.ConversationID)
+		err := c.typing.Stop(c.PersonnelID, in.ConversationID)
+		if err != nil {
+			slog.Warn("failed to stop typing", "personnel_id", c.PersonnelID, "conversation_id", in.ConversationID, "err", err)
+		}
+	}
+}
+
+// heartbeat keeps the presence of the connection fresh, at most once per heartbeatInterval
+func (c *Client) heartbeat() {
+	if time.Since(c.lastHeartbeat) < heartbeatInterval {
+		return
+	}
+	c.lastHeartbeat = time.Now()
+	err := c.presence.Heartbeat(c.PersonnelID, c.ConnectionID, c.status)
+	if err != nil {
+		slog.Error("failed to record presence heartbeat", "personnel_id", c.PersonnelID, "err", err)
+	}
+}
+
+// writePump writes queued frames and pings to the peer
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		_ = c.conn.Close()
+	}()
+	for {
+		select {
+		case b := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			err := c.conn.WriteMessage(websocket.TextMessage, b)
+			if err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			err := c.conn.WriteMessage(websocket.PingMessage, nil)
+			if err != nil {
+				return
+			}
+		case <-c.done:
+			_ = c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(c.closeCode, ""), time.Now().Add(writeWait))
+			return
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/gorilla/websocket"
+
+	"github.com/chatApp/internal/domain"
+	"github.com/chatApp/internal/pkg/pubsub"
+)
+
+var ErrHubClosed = errors.New("realtime hub is closed")
+
+// sessionsChannel is the pub/sub channel carrying the ids of the sessions that ended
+const sessionsChannel = "sessions_closed"
+
+// Hub keeps track of the live websocket connections of every personnel.
+// A personnel may hold several connections at once, one per device.
+// Events go through the pub/sub so connections held by other instances receive them too.
+type Hub struct {
+	ps      pubsub.PubSub
+	mu      sync.RWMutex
+	clients map[uuid.UUID]map[*Client]struct{}
+	closed  bool
+}
+
+// NewHub creates a new Hub
+func NewHub(ps pubsub.PubSub) *Hub {
+	h := &Hub{
+		ps:      ps,
+		clients: make(map[uuid.UUID]map[*Client]struct{}),
+	}
+	err := ps.Subscribe(sessionsChannel, func(_ string, payload []byte) {
+		id, err := uuid.FromString(string(payload))
+		if err != nil {
+			slog.Warn("invalid closed session id", "payload", string(payload))
+			return
+		}
+		h.disconnectSession(id)
+	})
+	if err != nil {
+		slog.Error("failed to subscribe to closed sessions", "err", err)
+	}
+	return h
+}
+
+// channelForPersonnel returns the pub/sub channel carrying the events of a personnel
+func channelForPersonnel(id uuid.UUID) string {
+	return "personnel_" + id.String()
+}
+
+// Register adds the client to the hub
+func (h *Hub) Register(c *Client) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return ErrHubClosed
+	}
+	conns, ok := h.clients[c.PersonnelID]
+	if !ok {
+		// first connection of the personnel on this instance
+		personnelID := c.PersonnelID
+		err := h.ps.Subscribe(channelForPersonnel(personnelID), func(_ string, payload []byte) {
+			h.Deliver(personnelID, payload)
+		})
+		if err != nil {
+			return err
+		}
+		conns = make(map[*Client]struct{})
+		h.clients[c.PersonnelID] = conns
+	}
+	conns[c] = struct{}{}
+	return nil
+}
+
+// Unregister removes the client from the hub
+func (h *Hub) Unregister(c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	conns, ok := h.clients[c.PersonnelID]
+	if !ok {
+		return
+	}
+	delete(conns, c)
+	if len(conns) == 0 {
+		delete(h.clients, c.PersonnelID)
+		err := h.ps.Unsubscribe(channelForPersonnel(c.PersonnelID))
+		if err != nil {
+			slog.Error("failed to unsubscribe personnel channel", "personnel_id", c.PersonnelID, "err", err)
+		}
+	}
+}
+
+// Publish implements domain.EventPublisher.
+func (h *Hub) Publish(ctx context.Context, recipientID uuid.UUID, event domain.Event) (err error) {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	err = h.ps.Publish(ctx, channelForPersonnel(recipientID), b)
+	if errors.Is(err, pubsub.ErrPayloadTooLarge) {
+		// too large to fan out, at least reach the connections held by this instance
+		slog.Warn("realtime event too large for pubsub, delivering locally", "type", event.Type, "recipient_id", recipientID)
+		h.Deliver(recipientID, b)
+		return nil
+	}
+	return err
+}
+
+// Deliver writes an already encoded event to every connection of the recipient held by this instance
+func (h *Hub) Deliver(recipientID uuid.UUID, b []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for c := range h.clients[recipientID] {
+		c.enqueue(b)
+	}
+}
+
+// CloseSession implements domain.SessionCloser.
+func (h *Hub) CloseSession(ctx context.Context, sessionID uuid.UUID) (err error) {
+	err = h.ps.Publish(ctx, sessionsChannel, []byte(sessionID.String()))
+	if err != nil {
+		// at least disconnect the connections held by this instance
+		h.disconnectSession(sessionID)
+		return err
+	}
+	return nil
+}
+
+// disconnectSession closes the connections of the session held by this instance
+func (h *Hub) disconnectSession(sessionID uuid.UUID) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, conns := range h.clients {
+		for c := range conns {
+			if c.SessionID == sessionID {
+				c.Close(websocket.ClosePolicyViolation)
+			}
+		}
+	}
+}
+
+// Close disconnects every client, stops accepting new ones and closes the pub/sub.
+// It is meant to be registered with the http server shutdown hooks.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.closed = true
+	for _, conns := range h.clients {
+		for c := range conns {
+			c.Close(websocket.CloseGoingAway)
+		}
+	}
+	h.mu.Unlock()
+	// the pub/sub may be dispatching into Deliver, so it is closed without holding the lock
+	h.ps.Close()
+	slog.Info("realtime hub closed")
+}
@@ -0,0 +1,73 @@
+package security
+
+import (
+	"github.com/gofrs/uuid/v5"
+	"github.com/labstack/echo/v4"
+
+	"github.com/chatApp/internal/domain"
+)
+
+// OwnerFunc returns the user owning the resource a request is about
+type OwnerFunc func(ctx echo.Context) (userID uuid.UUID, err error)
+
+// GetRoleForContext returns the role of the user the auth token was issued to. A role that requires two-factor
+// authentication counts as USER when the login did not give a second factor.
+func GetRoleForContext(ctx echo.Context) (domain.UserRole, error) {
+	claims := GetClaimsForContext(ctx)
+	if claims == nil {
+		return "", domain.UnauthorizedError{}
+	}
+	role, ok := claims["role"].(string)
+	if !ok {
+		return "", domain.UnauthorizedError{}
+	}
+	if domain.UserRole(role).RequiresMFA() {
+		if mfa, _ := claims["mfa"].(bool); !mfa {
+			return domain.UserRoleUser, nil
+		}
+	}
+	return domain.UserRole(role), nil
+}
+
+// HasPermission tells whether the role of the auth token grants the permission
+func HasPermission(ctx echo.Context, p domain.Permission) bool {
+	role, err := GetRoleForContext(ctx)
+	return err == nil && role.Can(p)
+}
+
+// RequirePermission lets through the requests whose auth token has a role granting the permission.
+// It must run after the auth middleware.
+func RequirePermission(p domain.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !HasPermission(c, p) {
+				return domain.ForbiddenAccessError{}
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireSelfOrPermission lets through the requests of the owner of the resource and those whose auth token has a
+// role granting the permission, such as an ADMIN. It must run after the auth middleware.
+func RequireSelfOrPermission(p domain.Permission, owner OwnerFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if HasPermission(c, p) {
+				return next(c)
+			}
+			userID, err := GetUserIDForContext(c)
+			if err != nil {
+				return err
+			}
+			ownerID, err := owner(c)
+			if err != nil {
+				return err
+			}
+			if ownerID != userID {
+				return domain.ForbiddenAccessError{}
+			}
+			return next(c)
+		}
+	}
+}
@@ -1,8 +1,14 @@
 package security
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"time"
 
+	"github.com/gofrs/uuid/v5"
 	"github.com/golang-jwt/jwt/v5"
 
 	"github.com/chatApp/internal/pkg/config"
@@ -10,9 +16,23 @@ import (
 
 const issuer = "Chat-App-Server"
 
-// jwtSecurityManager represents the JWT security manager
+const (
+	// defaultAccessExpiryPeriod is the lifetime of access tokens in minutes when AUTH_ACCESS_EXPIRY_PERIOD is not set
+	defaultAccessExpiryPeriod = 15
+	// defaultRefreshExpiryPeriod is the lifetime of refresh tokens in hours when AUTH_EXPIRY_PERIOD is not set
+	defaultRefreshExpiryPeriod = 90
+)
+
+// refreshTokenSize is the number of random bytes in a refresh token
+const refreshTokenSize = 32
+
+// jwtSecurityManager represents the JWT security manager. It signs tokens with the first private key of
+// AUTH_SIGNING_KEYS and verifies them with any of the keys, or falls back to HS256 with AUTH_SECRET without keys.
 type jwtSecurityManager struct {
-	cfg config.ChatApiConfig
+	cfg  config.ChatApiConfig
+	keys []key
+	// signing is the key tokens are signed with, nil when signing with AUTH_SECRET
+	signing *key
 }
 
 // authClaims represents the claims in the auth token
@@ -21,28 +41,120 @@ type authClaims struct {
 	jwt.RegisteredClaims
 }
 
-// NewJwtSecurityManager creates a new JWT security manager
-func NewJwtSecurityManager(cfg config.ChatApiConfig) Manager {
-	return &jwtSecurityManager{
-		cfg: cfg,
+// NewJwtSecurityManager creates a new JWT security manager, loading the signing keys
+func NewJwtSecurityManager(cfg config.ChatApiConfig) (Manager, error) {
+	keys, err := loadKeys(cfg.AuthSigningKeys)
+	if err != nil {
+		return nil, err
+	}
+	s := &jwtSecurityManager{
+		cfg:  cfg,
+		keys: keys,
+	}
+	for i := range keys {
+		if keys[i].signer != nil {
+			s.signing = &keys[i]
+			break
+		}
+	}
+	if len(keys) > 0 && s.signing == nil {
+		return nil, errors.New("none of the signing keys is a private key")
 	}
+	return s, nil
 }
 
 // GenerateAuthToken generates an auth token for a user.
-func (s jwtSecurityManager) GenerateAuthToken(metadata TokenMetadata) (token string, err error) {
+func (s jwtSecurityManager) GenerateAuthToken(metadata TokenMetadata) (result AuthToken, err error) {
+	now := time.Now()
+	result = AuthToken{
+		ID:        uuid.Must(uuid.NewV4()),
+		ExpiresAt: now.Add(AccessExpiryPeriod(s.cfg)),
+	}
 	claims := &authClaims{
 		TokenMetadata: metadata,
 		RegisteredClaims: jwt.RegisteredClaims{
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * time.Duration(s.cfg.AuthExpiryPeriod))),
+			ID:        result.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(result.ExpiresAt),
 			Issuer:    issuer,
 		},
 	}
 
-	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	token, err = t.SignedString([]byte(s.cfg.AuthSecret))
+	if s.signing == nil {
+		t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		result.Token, err = t.SignedString([]byte(s.cfg.AuthSecret))
+	} else {
+		t := jwt.NewWithClaims(s.signing.method, claims)
+		t.Header["kid"] = s.signing.id
+		result.Token, err = t.SignedString(s.signing.signer)
+	}
+	if err != nil {
+		return AuthToken{}, err
+	}
+	return result, nil
+}
+
+// VerificationKey returns the key an auth token is verified with.
+func (s jwtSecurityManager) VerificationKey(token *jwt.Token) (interface{}, error) {
+	if len(s.keys) == 0 {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, ErrUnexpectedSigningMethod
+		}
+		return []byte(s.cfg.AuthSecret), nil
+	}
+	kid, _ := token.Header["kid"].(string)
+	for _, k := range s.keys {
+		if k.id != kid {
+			continue
+		}
+		// the algorithm comes from the token, so it must match the key to rule out algorithm confusion
+		if token.Method != k.method {
+			return nil, ErrUnexpectedSigningMethod
+		}
+		return k.public, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// JWKS returns the public keys auth tokens are verified with.
+func (s jwtSecurityManager) JWKS() JWKS {
+	result := JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, k := range s.keys {
+		result.Keys = append(result.Keys, k.jwk())
+	}
+	return result
+}
+
+// GenerateRefreshToken generates an opaque refresh token and the hash it is stored by.
+func (s jwtSecurityManager) GenerateRefreshToken() (token string, hash string, err error) {
+	b := make([]byte, refreshTokenSize)
+	_, err = rand.Read(b)
 	if err != nil {
-		return "", err
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, s.HashRefreshToken(token), nil
+}
+
+// HashRefreshToken returns the hash a refresh token is stored by.
+func (s jwtSecurityManager) HashRefreshToken(token string) string {
+	// refresh tokens are long and random, so a fast hash is enough to keep them useless when the table leaks
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// AccessExpiryPeriod returns the lifetime of access tokens
+func AccessExpiryPeriod(cfg config.ChatApiConfig) time.Duration {
+	if cfg.AuthAccessExpiryPeriod <= 0 {
+		return defaultAccessExpiryPeriod * time.Minute
+	}
+	return time.Duration(cfg.AuthAccessExpiryPeriod) * time.Minute
+}
+
+// RefreshExpiryPeriod returns the lifetime of refresh tokens, which is how long a login lasts without being used
+func RefreshExpiryPeriod(cfg config.ChatApiConfig) time.Duration {
+	if cfg.AuthExpiryPeriod <= 0 {
+		return defaultRefreshExpiryPeriod * time.Hour
 	}
-	return token, nil
+	return time.Duration(cfg.AuthExpiryPeriod) * time.Hour
 }
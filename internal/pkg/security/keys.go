@@ -0,0 +1,142 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSAKeySize is the smallest RSA modulus in bits accepted for signing keys
+const minRSAKeySize = 2048
+
+var (
+	// ErrUnknownKey is returned for tokens whose kid names no key of the key set
+	ErrUnknownKey = errors.New("unknown signing key")
+	// ErrUnexpectedSigningMethod is returned for tokens signed with another algorithm than their key
+	ErrUnexpectedSigningMethod = errors.New("unexpected signing method")
+)
+
+type (
+	// JWK represents a public key of a JSON Web Key Set, RFC 7517
+	JWK struct {
+		Kty string `json:"kty"`
+		Use string `json:"use"`
+		Kid string `json:"kid"`
+		Alg string `json:"alg"`
+		// N and E are the modulus and exponent of RSA keys
+		N string `json:"n,omitempty"`
+		E string `json:"e,omitempty"`
+		// Crv and X are the curve and public key of Ed25519 keys
+		Crv string `json:"crv,omitempty"`
+		X   string `json:"x,omitempty"`
+	}
+
+	// JWKS represents a JSON Web Key Set, RFC 7517
+	JWKS struct {
+		Keys []JWK `json:"keys"`
+	}
+)
+
+// key represents a key auth tokens are verified with, signer is only set for private keys
+type key struct {
+	id     string
+	method jwt.SigningMethod
+	public crypto.PublicKey
+	signer crypto.Signer
+}
+
+// loadKeys loads the keys from PEM files, the id of a key is the name of its file up to the first dot, so
+// 2026-04.pem and its public key 2026-04.pub.pem share the id.
+// Private keys sign and verify tokens while public keys only verify them, which keeps the tokens of a retired
+// key valid until they expire.
+func loadKeys(paths []string) (result []key, err error) {
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		k, err := loadKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load signing key %s: %w", path, err)
+		}
+		if seen[k.id] {
+			return nil, fmt.Errorf("duplicate signing key id %s", k.id)
+		}
+		seen[k.id] = true
+		result = append(result, k)
+	}
+	return result, nil
+}
+
+// loadKey loads a PKCS #8 or PKCS #1 private key or a PKIX public key from a PEM file
+func loadKey(path string) (result key, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return result, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return result, errors.New("no PEM data found")
+	}
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return result, fmt.Errorf("unsupported PEM block %s", block.Type)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	result.id, _, _ = strings.Cut(filepath.Base(path), ".")
+	if signer, ok := parsed.(crypto.Signer); ok {
+		result.signer = signer
+		parsed = signer.Public()
+	}
+	switch pub := parsed.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSAKeySize {
+			return result, fmt.Errorf("RSA keys must have at least %d bits", minRSAKeySize)
+		}
+		result.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		result.method = jwt.SigningMethodEdDSA
+	default:
+		return result, errors.New("only RSA and Ed25519 keys are supported")
+	}
+	result.public = parsed
+	return result, nil
+}
+
+// jwk returns the public key as a JSON Web Key
+func (k key) jwk() JWK {
+	result := JWK{Use: "sig", Kid: k.id, Alg: k.method.Alg()}
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		result.Kty = "RSA"
+		result.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		result.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		result.Kty = "OKP"
+		result.Crv = "Ed25519"
+		result.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+	return result
+}
@@ -0,0 +1,160 @@
+package security
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/chatApp/internal/pkg/config"
+)
+
+const (
+	// CharacterClassLower asks for a lowercase letter
+	CharacterClassLower = "lower"
+	// CharacterClassUpper asks for an uppercase letter
+	CharacterClassUpper = "upper"
+	// CharacterClassDigit asks for a digit
+	CharacterClassDigit = "digit"
+	// CharacterClassSymbol asks for a character that is neither a letter nor a digit
+	CharacterClassSymbol = "symbol"
+)
+
+const (
+	// defaultPasswordMinLength is the least number of characters of a password when PASSWORD_MIN_LENGTH is not set
+	defaultPasswordMinLength = 8
+	// passwordMaxLength is the most bytes of a password, bcrypt ignores the ones after
+	passwordMaxLength = 72
+)
+
+// commonPasswords is the built-in list of the most common passwords, one per line
+//
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// PasswordPolicy defines the methods that the rules passwords are held to should implement
+type PasswordPolicy interface {
+	// Check returns what a new password of the user breaks, each a phrase to follow the name of the field, such as
+	// "must have at least 8 characters". Reuse of previous passwords is checked against their hashes by the caller.
+	Check(password string, userName string) (result []string)
+	// HistorySize returns the number of previous passwords a new one may not repeat, 0 allows any
+	HistorySize() int
+}
+
+type passwordPolicy struct {
+	minLength int
+	classes   []string
+	common    map[string]struct{}
+	history   int
+}
+
+// NewPasswordPolicy creates the policy configured by the PASSWORD_* settings, the file at PASSWORD_COMMON_LIST is
+// refused on top of the built-in list of common passwords
+func NewPasswordPolicy(cfg config.ChatApiConfig) (PasswordPolicy, error) {
+	p := &passwordPolicy{
+		minLength: cfg.PasswordMinLength,
+		common:    make(map[string]struct{}),
+		history:   max(cfg.PasswordHistory, 0),
+	}
+	if p.minLength <= 0 {
+		p.minLength = defaultPasswordMinLength
+	}
+	for _, class := range cfg.PasswordCharacterClasses {
+		class = strings.ToLower(strings.TrimSpace(class))
+		switch class {
+		case "":
+			continue
+		case CharacterClassLower, CharacterClassUpper, CharacterClassDigit, CharacterClassSymbol:
+			p.classes = append(p.classes, class)
+		default:
+			return nil, fmt.Errorf("unknown password character class %q", class)
+		}
+	}
+	_ = p.addCommon(strings.NewReader(commonPasswords))
+	if cfg.PasswordCommonList != "" {
+		f, err := os.Open(cfg.PasswordCommonList)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read common password list: %w", err)
+		}
+		defer f.Close()
+		err = p.addCommon(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read common password list: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// Check implements PasswordPolicy.
+func (p *passwordPolicy) Check(password string, userName string) (result []string) {
+	if utf8.RuneCountInString(password) < p.minLength {
+		result = append(result, fmt.Sprintf("must have at least %d characters", p.minLength))
+	}
+	if len(password) > passwordMaxLength {
+		result = append(result, fmt.Sprintf("must not exceed %d bytes", passwordMaxLength))
+	}
+	for _, class := range p.classes {
+		if !strings.ContainsFunc(password, characterClass(class)) {
+			result = append(result, "must contain "+describeClass(class))
+		}
+	}
+	normalized := strings.ToLower(password)
+	if _, ok := p.common[normalized]; ok {
+		result = append(result, "is too common")
+	}
+	// user names are mobile numbers, which others know
+	name := strings.ToLower(strings.TrimPrefix(userName, "+"))
+	if name != "" && strings.Contains(normalized, name) {
+		result = append(result, "must not contain the user name")
+	}
+	return result
+}
+
+// HistorySize implements PasswordPolicy.
+func (p *passwordPolicy) HistorySize() int {
+	return p.history
+}
+
+// addCommon adds the passwords of a list, one per line, to the common ones
+func (p *passwordPolicy) addCommon(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if line != "" {
+			p.common[line] = struct{}{}
+		}
+	}
+	return scanner.Err()
+}
+
+// characterClass returns the test of the runes of a class
+func characterClass(class string) func(rune) bool {
+	switch class {
+	case CharacterClassLower:
+		return unicode.IsLower
+	case CharacterClassUpper:
+		return unicode.IsUpper
+	case CharacterClassDigit:
+		return unicode.IsDigit
+	default:
+		return func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }
+	}
+}
+
+// describeClass names a class in a message
+func describeClass(class string) string {
+	switch class {
+	case CharacterClassLower:
+		return "a lowercase letter"
+	case CharacterClassUpper:
+		return "an uppercase letter"
+	case CharacterClassDigit:
+		return "a digit"
+	default:
+		return "a symbol"
+	}
+}
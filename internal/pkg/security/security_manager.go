@@ -1,8 +1,13 @@
 package security
 
 import (
+	"time"
+
+	"github.com/gofrs/uuid/v5"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
+
+	"github.com/chatApp/internal/domain"
 )
 
 // TokenMetadata represents the metadata in the auth token
@@ -10,12 +15,32 @@ type TokenMetadata struct {
 	UserID         string `json:"user_id"`
 	OrganizationID string `json:"organization_id"`
 	Role           string `json:"role"`
+	// MFA tells whether the login gave a second factor, roles that require it grant only the USER permissions without it
+	MFA bool `json:"mfa,omitempty"`
+	// SessionID is the session the token was issued to
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// AuthToken represents a signed auth token
+type AuthToken struct {
+	Token string
+	// ID is the jti claim the token is revoked by
+	ID        uuid.UUID
+	ExpiresAt time.Time
 }
 
 // Manager defines the methods that a security manager should implement
 type Manager interface {
 	// GenerateAuthToken generates an auth token for a user.
-	GenerateAuthToken(metadata TokenMetadata) (token string, err error)
+	GenerateAuthToken(metadata TokenMetadata) (result AuthToken, err error)
+	// GenerateRefreshToken generates an opaque refresh token and the hash it is stored by.
+	GenerateRefreshToken() (token string, hash string, err error)
+	// HashRefreshToken returns the hash a refresh token is stored by.
+	HashRefreshToken(token string) string
+	// VerificationKey returns the key an auth token is verified with, it is the jwt.Keyfunc of the manager.
+	VerificationKey(token *jwt.Token) (interface{}, error)
+	// JWKS returns the public keys auth tokens are verified with, it is empty when they are signed with a secret.
+	JWKS() JWKS
 }
 
 func GetClaimsForContext(ctx echo.Context) jwt.MapClaims {
@@ -27,3 +52,58 @@ func GetClaimsForContext(ctx echo.Context) jwt.MapClaims {
 	}
 	return nil
 }
+
+// GetUserIDForContext returns the id of the user the auth token was issued to
+func GetUserIDForContext(ctx echo.Context) (uuid.UUID, error) {
+	claims := GetClaimsForContext(ctx)
+	if claims == nil {
+		return uuid.Nil, domain.UnauthorizedError{}
+	}
+	userID, ok := claims["user_id"].(string)
+	if !ok {
+		return uuid.Nil, domain.UnauthorizedError{}
+	}
+	id, err := uuid.FromString(userID)
+	if err != nil {
+		return uuid.Nil, domain.UnauthorizedError{}
+	}
+	return id, nil
+}
+
+// GetSessionIDForContext returns the id of the session the auth token was issued to
+func GetSessionIDForContext(ctx echo.Context) (uuid.UUID, error) {
+	claims := GetClaimsForContext(ctx)
+	if claims == nil {
+		return uuid.Nil, domain.UnauthorizedError{}
+	}
+	sessionID, ok := claims["session_id"].(string)
+	if !ok {
+		return uuid.Nil, domain.UnauthorizedError{}
+	}
+	id, err := uuid.FromString(sessionID)
+	if err != nil {
+		return uuid.Nil, domain.UnauthorizedError{}
+	}
+	return id, nil
+}
+
+// GetTokenIDForContext returns the jti of the auth token and when it expires
+func GetTokenIDForContext(ctx echo.Context) (id uuid.UUID, expiresAt time.Time, err error) {
+	claims := GetClaimsForContext(ctx)
+	if claims == nil {
+		return uuid.Nil, expiresAt, domain.UnauthorizedError{}
+	}
+	jti, ok := claims["jti"].(string)
+	if !ok {
+		return uuid.Nil, expiresAt, domain.UnauthorizedError{}
+	}
+	id, err = uuid.FromString(jti)
+	if err != nil {
+		return uuid.Nil, expiresAt, domain.UnauthorizedError{}
+	}
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return uuid.Nil, expiresAt, domain.UnauthorizedError{}
+	}
+	return id, exp.Time, nil
+}
@@ -0,0 +1,40 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type fileSMSSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileSMSSender creates a sender appending messages to the file at path, one line each
+func NewFileSMSSender(path string) (SMSSender, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0o750)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sms directory: %v", err)
+	}
+	return &fileSMSSender{path: path}, nil
+}
+
+// Send implements SMSSender.
+func (s *fileSMSSender) Send(ctx context.Context, to string, message string) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "%s\t%s\t%q\n", time.Now().Format(time.RFC3339), to, message)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
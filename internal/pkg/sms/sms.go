@@ -0,0 +1,38 @@
+package sms
+
+import (
+	"context"
+
+	"github.com/chatApp/internal/pkg/config"
+)
+
+const (
+	// DriverLog writes text messages to the application log
+	DriverLog = "log"
+	// DriverFile appends text messages to the file at SMS_FILE_PATH
+	DriverFile = "file"
+)
+
+// defaultFilePath is where the file driver appends messages when SMS_FILE_PATH is not set
+const defaultFilePath = "data/sms.log"
+
+// SMSSender defines the methods that any text message gateway should implement
+type SMSSender interface {
+	// Send sends the message to the mobile number in E.164 format
+	Send(ctx context.Context, to string, message string) (err error)
+}
+
+// NewSMSSender creates the sender configured by SMS_DRIVER, defaulting to the log. Both drivers are meant for
+// local development, a gateway implements SMSSender in production.
+func NewSMSSender(cfg config.ChatApiConfig) (SMSSender, error) {
+	switch cfg.SMSDriver {
+	case DriverFile:
+		path := cfg.SMSFilePath
+		if path == "" {
+			path = defaultFilePath
+		}
+		return NewFileSMSSender(path)
+	default:
+		return NewLogSMSSender(), nil
+	}
+}
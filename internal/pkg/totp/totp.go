@@ -0,0 +1,203 @@
+package totp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/chatApp/internal/pkg/config"
+)
+
+const (
+	// digits is the length of codes
+	digits = 6
+	// period is how long a code lasts
+	period = 30 * time.Second
+	// secretSize is the number of random bytes of a secret, the size of a SHA-1 block as RFC 4226 recommends
+	secretSize = 20
+	// skew is the number of periods before and after the current one whose codes are accepted, for clock drift
+	skew = 1
+	// recoveryCodeSize is the number of base32 characters of a recovery code
+	recoveryCodeSize = 10
+)
+
+// ErrNoEncryptionKey is returned when neither TOTP_ENCRYPTION_KEY nor AUTH_SECRET is set to seal secrets with
+var ErrNoEncryptionKey = errors.New("TOTP_ENCRYPTION_KEY is not set")
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Authenticator defines the methods of RFC 6238 time-based one time passwords, as authenticator apps make them
+type Authenticator interface {
+	// GenerateSecret returns a new base32 secret
+	GenerateSecret() (secret string, err error)
+	// ProvisioningURI returns the otpauth URI authenticator apps add the secret of the account with, usually from a
+	// QR code
+	ProvisioningURI(account string, secret string) string
+	// Validate checks a code of the secret at the time and returns the time step it belongs to. Callers refuse
+	// steps that are not after the last one used, so that a code works once.
+	Validate(secret string, code string, at time.Time) (step int64, ok bool)
+	// Seal encrypts a secret to be stored
+	Seal(secret string) (sealed string, err error)
+	// Open decrypts a sealed secret
+	Open(sealed string) (secret string, err error)
+	// GenerateRecoveryCodes returns n new recovery codes, such as abcde-fghij
+	GenerateRecoveryCodes(n int) (result []string, err error)
+}
+
+type authenticator struct {
+	issuer string
+	key    []byte
+}
+
+// NewAuthenticator creates the authenticator, secrets are sealed with a key derived from TOTP_ENCRYPTION_KEY or
+// AUTH_SECRET when it is not set
+func NewAuthenticator(cfg config.ChatApiConfig) Authenticator {
+	a := &authenticator{issuer: cfg.AppName}
+	if a.issuer == "" {
+		a.issuer = "ChatApp"
+	}
+	secret := cfg.TOTPEncryptionKey
+	if secret == "" {
+		secret = cfg.AuthSecret
+	}
+	if secret != "" {
+		sum := sha256.Sum256([]byte(secret))
+		a.key = sum[:]
+	}
+	return a
+}
+
+// GenerateSecret implements Authenticator.
+func (a *authenticator) GenerateSecret() (secret string, err error) {
+	b := make([]byte, secretSize)
+	_, err = rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// ProvisioningURI implements Authenticator.
+func (a *authenticator) ProvisioningURI(account string, secret string) string {
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", a.issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(digits))
+	q.Set("period", fmt.Sprint(int(period.Seconds())))
+	return "otpauth://totp/" + url.PathEscape(a.issuer+":"+account) + "?" + q.Encode()
+}
+
+// Validate implements Authenticator.
+func (a *authenticator) Validate(secret string, code string, at time.Time) (step int64, ok bool) {
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != digits {
+		return 0, false
+	}
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+	current := at.Unix() / int64(period.Seconds())
+	for s := current - skew; s <= current+skew; s++ {
+		if subtle.ConstantTimeCompare([]byte(generate(key, s)), []byte(code)) == 1 {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+// Seal implements Authenticator.
+func (a *authenticator) Seal(secret string) (sealed string, err error) {
+	gcm, err := a.cipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(secret), nil)), nil
+}
+
+// Open implements Authenticator.
+func (a *authenticator) Open(sealed string) (secret string, err error) {
+	gcm, err := a.cipher()
+	if err != nil {
+		return "", err
+	}
+	b, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+	if len(b) < gcm.NonceSize() {
+		return "", errors.New("sealed secret is too short")
+	}
+	plain, err := gcm.Open(nil, b[:gcm.NonceSize()], b[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// GenerateRecoveryCodes implements Authenticator.
+func (a *authenticator) GenerateRecoveryCodes(n int) (result []string, err error) {
+	result = make([]string, n)
+	for i := range result {
+		// base32 of 7 bytes is 12 characters, the first 10 carry 50 random bits
+		b := make([]byte, 7)
+		_, err = rand.Read(b)
+		if err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(encoding.EncodeToString(b))[:recoveryCodeSize]
+		result[i] = code[:recoveryCodeSize/2] + "-" + code[recoveryCodeSize/2:]
+	}
+	return result, nil
+}
+
+// cipher returns the AES-GCM cipher secrets are sealed with
+func (a *authenticator) cipher() (cipher.AEAD, error) {
+	if a.key == nil {
+		return nil, ErrNoEncryptionKey
+	}
+	block, err := aes.NewCipher(a.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// NormalizeRecoveryCode returns the form recovery codes are hashed in, so that case, spaces and dashes do not matter
+func NormalizeRecoveryCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+}
+
+// generate returns the code of the key at a time step, as RFC 4226 computes it
+func generate(key []byte, step int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%mod)
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/gofrs/uuid"
 	"golang.org/x/crypto/bcrypt"
+
+	"github.com/chatApp/internal/pkg/config"
 )
 
 var daysOfWeek = map[string]time.Weekday{
@@ -47,18 +49,34 @@ type AppUtil interface {
 	ParseWeekday(v string) (time.Weekday, error)
 	// IsTimeExpired ... Validate if the specified time has expired based on the current time
 	IsTimeExpired(t time.Time) bool
-	// EncryptPassword  ... Encrypt password using bcrypt
+	// EncryptPassword  ... Encrypt password using the configured algorithm, argon2id or bcrypt
 	EncryptPassword(password string) (string, error)
-	// PasswordCheck Check if the password matches the encrypted password
-	PasswordCheck(password string, encryptedPassword string) (bool, error)
+	// PasswordCheck Check if the password matches the encrypted password, made by either algorithm
+	PasswordCheck(encryptedPassword, password string) (bool, error)
+	// PasswordNeedsRehash ... Tell whether an encrypted password was made with another algorithm or weaker
+	// parameters than EncryptPassword uses now
+	PasswordNeedsRehash(encryptedPassword string) bool
 }
 
-// NewAppUtil ... Creates a new AppUtil
-func NewAppUtil() AppUtil {
-	return &simpleAppUtil{}
+const (
+	// PasswordHashArgon2id hashes passwords with argon2id
+	PasswordHashArgon2id = "argon2id"
+	// PasswordHashBcrypt hashes passwords with bcrypt
+	PasswordHashBcrypt = "bcrypt"
+)
+
+// NewAppUtil ... Creates a new AppUtil, passwords are hashed with PASSWORD_HASH, argon2id unless it is bcrypt
+func NewAppUtil(cfg config.ChatApiConfig) AppUtil {
+	passwordHash := PasswordHashArgon2id
+	if cfg.PasswordHash == PasswordHashBcrypt {
+		passwordHash = PasswordHashBcrypt
+	}
+	return &simpleAppUtil{passwordHash: passwordHash}
 }
 
-type simpleAppUtil struct{}
+type simpleAppUtil struct {
+	passwordHash string
+}
 
 func (as *simpleAppUtil) GetCurrentTime() time.Time {
 	return time.Now()
@@ -139,6 +157,9 @@ func (as *simpleAppUtil) ParseWeekday(v string) (time.Weekday, error) {
 }
 
 func (as *simpleAppUtil) EncryptPassword(password string) (string, error) {
+	if as.passwordHash == PasswordHashArgon2id {
+		return argon2idHash(password, defaultArgon2idParams)
+	}
 	hashPass, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return "", err
@@ -146,10 +167,28 @@ func (as *simpleAppUtil) EncryptPassword(password string) (string, error) {
 	return string(hashPass), nil
 }
 
-func (as *simpleAppUtil) PasswordCheck(inPass string, targetPass string) (bool, error) {
-	err := bcrypt.CompareHashAndPassword([]byte(inPass), []byte(targetPass))
+func (as *simpleAppUtil) PasswordCheck(encryptedPassword, password string) (bool, error) {
+	if isArgon2idHash(encryptedPassword) {
+		return argon2idCompare(encryptedPassword, password)
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(encryptedPassword), []byte(password))
 	if err != nil {
 		return false, err
 	}
 	return true, nil
 }
+
+func (as *simpleAppUtil) PasswordNeedsRehash(encryptedPassword string) bool {
+	if isArgon2idHash(encryptedPassword) {
+		if as.passwordHash != PasswordHashArgon2id {
+			return true
+		}
+		params, _, _, err := argon2idDecode(encryptedPassword)
+		return err != nil || params != defaultArgon2idParams
+	}
+	if as.passwordHash != PasswordHashBcrypt {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(encryptedPassword))
+	return err != nil || cost < bcrypt.DefaultCost
+}
@@ -0,0 +1,82 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// argon2idParams are the cost parameters of an argon2id hash
+type argon2idParams struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	keyLen  uint32
+}
+
+// defaultArgon2idParams follow the OWASP recommendation of 19 MiB of memory and two passes
+var defaultArgon2idParams = argon2idParams{memory: 19 * 1024, time: 2, threads: 1, keyLen: 32}
+
+// argon2idSaltLen is the number of random bytes salting every hash
+const argon2idSaltLen = 16
+
+var errInvalidArgon2idHash = errors.New("invalid argon2id hash")
+
+// argon2idHash hashes a password into the PHC string format, $argon2id$v=19$m=19456,t=2,p=1$salt$key
+func argon2idHash(password string, p argon2idParams) (string, error) {
+	salt := make([]byte, argon2idSaltLen)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, p.time, p.memory, p.threads, p.keyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, p.memory, p.time, p.threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// argon2idCompare tells whether a password matches an argon2id hash
+func argon2idCompare(hash, password string) (bool, error) {
+	p, salt, key, err := argon2idDecode(hash)
+	if err != nil {
+		return false, err
+	}
+	other := argon2.IDKey([]byte(password), salt, p.time, p.memory, p.threads, p.keyLen)
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// argon2idDecode splits an argon2id hash into its parameters, salt and key
+func argon2idDecode(hash string) (p argon2idParams, salt, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, errInvalidArgon2idHash
+	}
+	var version int
+	_, err = fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil || version != argon2.Version {
+		return p, nil, nil, errInvalidArgon2idHash
+	}
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads)
+	if err != nil || p.memory == 0 || p.time == 0 || p.threads == 0 {
+		return p, nil, nil, errInvalidArgon2idHash
+	}
+	salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, errInvalidArgon2idHash
+	}
+	key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, errInvalidArgon2idHash
+	}
+	p.keyLen = uint32(len(key))
+	return p, salt, key, nil
+}
+
+// isArgon2idHash tells an argon2id hash from a bcrypt one
+func isArgon2idHash(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
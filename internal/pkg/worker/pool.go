@@ -0,0 +1,109 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/chatApp/internal/pkg/config"
+)
+
+const (
+	// defaultWorkers is the number of tasks run at once when WORKER_POOL_SIZE is not set
+	defaultWorkers = 2
+	// defaultQueueSize is the number of tasks that may wait for a worker when WORKER_QUEUE_SIZE is not set
+	defaultQueueSize = 100
+)
+
+var (
+	ErrQueueFull  = errors.New("worker queue is full")
+	ErrPoolClosed = errors.New("worker pool is closed")
+)
+
+// Task is a unit of background work, ctx is cancelled when the pool is closed
+type Task func(ctx context.Context)
+
+// Pool runs tasks in the background on a fixed number of goroutines.
+// Tasks wait in a bounded queue, once it is full new tasks are refused rather than piling up.
+type Pool struct {
+	tasks  chan Task
+	ctx    context.Context
+	cancel context.CancelFunc
+	mu     sync.RWMutex
+	wg     sync.WaitGroup
+	closed bool
+}
+
+// NewPool creates a pool sized by WORKER_POOL_SIZE and WORKER_QUEUE_SIZE and starts its workers
+func NewPool(cfg config.ChatApiConfig) *Pool {
+	workers := cfg.WorkerPoolSize
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	queueSize := cfg.WorkerQueueSize
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		tasks:  make(chan Task, queueSize),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// Submit queues the task without waiting, it fails with ErrQueueFull when every worker is busy and the queue is full
+func (p *Pool) Submit(task Task) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+	select {
+	case p.tasks <- task:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close stops accepting tasks, cancels the running ones and waits for the workers to return.
+// The tasks still queued are dropped.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.tasks)
+	p.mu.Unlock()
+	p.cancel()
+	p.wg.Wait()
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for task := range p.tasks {
+		if p.ctx.Err() != nil {
+			continue
+		}
+		p.run(task)
+	}
+}
+
+// run runs the task, a panicking task is logged and does not take its worker down
+func (p *Pool) run(task Task) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("background task panicked", "panic", r)
+		}
+	}()
+	task(p.ctx)
+}
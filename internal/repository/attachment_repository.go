@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/chatApp/internal/domain"
+)
+
+type pgxAttachmentRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAttachmentRepository(db *pgxpool.Pool) domain.AttachmentRepository {
+	return &pgxAttachmentRepository{db: db}
+}
+
+// FindByID implements domain.AttachmentRepository.
+func (r *pgxAttachmentRepository) FindByID(ctx context.Context, id uuid.UUID) (result domain.Attachment, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `SELECT * FROM attachments WHERE id = $1 AND deleted_at IS NULL`
+	args := []interface{}{id}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.Attachment])
+	return result, err
+}
+
+// FindByMessageIDs implements domain.AttachmentRepository.
+func (r *pgxAttachmentRepository) FindByMessageIDs(ctx context.Context, messageIDs []uuid.UUID) (result []domain.Attachment, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `SELECT * FROM attachments WHERE message_id = ANY($1) AND deleted_at IS NULL ORDER BY created_at, id`
+	args := []interface{}{messageIDs}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.Attachment])
+	return result, err
+}
+
+// Create implements domain.AttachmentRepository.
+func (r *pgxAttachmentRepository) Create(ctx context.Context, entity *domain.Attachment) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `INSERT INTO attachments (conversation_id, message_id, uploader_id, file_name, content_type, size, storage_key) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at, updated_at`
+	args := []interface{}{entity.ConversationID, entity.MessageID, entity.UploaderID, entity.FileName, entity.ContentType, entity.Size, entity.StorageKey}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt, &entity.UpdatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt, &entity.UpdatedAt)
+	}
+	return err
+}
+
+// LinkToMessage implements domain.AttachmentRepository.
+func (r *pgxAttachmentRepository) LinkToMessage(ctx context.Context, message *domain.Message, ids []uuid.UUID) (linked int64, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `UPDATE attachments SET message_id = $1, updated_at = NOW()
+		WHERE id = ANY($2) AND conversation_id = $3 AND uploader_id = $4 AND message_id IS NULL AND deleted_at IS NULL`
+	args := []interface{}{message.ID, ids, message.ConversationID, message.SenderID}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		tag, err := tx.Exec(ctx, q, args...)
+		return tag.RowsAffected(), err
+	}
+	tag, err := r.db.Exec(ctx, q, args...)
+	return tag.RowsAffected(), err
+}
+
+// UpdateImage implements domain.AttachmentRepository.
+func (r *pgxAttachmentRepository) UpdateImage(ctx context.Context, entity *domain.Attachment) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `UPDATE attachments SET width = $2, height = $3, placeholder = $4, thumbnails = $5, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL RETURNING updated_at`
+	args := []interface{}{entity.ID, entity.Width, entity.Height, entity.Placeholder, entity.Thumbnails}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.UpdatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.UpdatedAt)
+	}
+	return err
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/chatApp/internal/domain"
+)
+
+type pgxAuditRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAuditRepository(db *pgxpool.Pool) domain.AuditRepository {
+	return &pgxAuditRepository{db: db}
+}
+
+// Create implements domain.AuditRepository.
+func (r *pgxAuditRepository) Create(ctx context.Context, entity *domain.AuditEvent) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	if entity.Details == nil {
+		entity.Details = domain.JSONB{}
+	}
+	q := `INSERT INTO audit_events (type, user_id, user_name, ip_address, details) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+	args := []interface{}{entity.Type, entity.UserID, entity.UserName, entity.IPAddress, map[string]interface{}(entity.Details)}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt)
+	}
+	return err
+}
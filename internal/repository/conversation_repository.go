@@ -0,0 +1,405 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/chatApp/internal/domain"
+)
+
+type pgxConversationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewConversationRepository(db *pgxpool.Pool) domain.ConversationRepository {
+	return &pgxConversationRepository{db: db}
+}
+
+// FindByID implements domain.ConversationRepository.
+func (r *pgxConversationRepository) FindByID(ctx context.Context, id uuid.UUID) (result domain.Conversation, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `SELECT * FROM conversations WHERE id = $1 AND deleted_at IS NULL`
+	args := []interface{}{id}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.Conversation])
+	return result, err
+}
+
+// FindByDirectKey implements domain.ConversationRepository.
+func (r *pgxConversationRepository) FindByDirectKey(ctx context.Context, directKey string) (result domain.Conversation, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `SELECT * FROM conversations WHERE direct_key = $1 AND deleted_at IS NULL`
+	args := []interface{}{directKey}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.Conversation])
+	return result, err
+}
+
+// FindByParticipant implements domain.ConversationRepository.
+func (r *pgxConversationRepository) FindByParticipant(ctx context.Context, personnelID uuid.UUID) (result []domain.ConversationSummary, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	// The latest message is returned as jsonb so the summary does not depend on the message columns
+	q := `SELECT c.*, to_jsonb(lm) AS latest_message,
+		(SELECT COUNT(*) FROM messages um WHERE um.conversation_id = c.id AND um.deleted_at IS NULL AND um.sender_id <> p.personnel_id AND (p.last_read_at IS NULL OR um.created_at > p.last_read_at)) AS unread_count
+		FROM conversation_participants p
+		JOIN conversations c ON c.id = p.conversation_id AND c.deleted_at IS NULL
+		LEFT JOIN LATERAL (SELECT m.id, m.sender_id, m.conversation_id, m.content, m.edited_at, m.reply_to_id, m.thread_root_id, m.reply_count, m.last_reply_at, m.created_at, m.updated_at FROM messages m WHERE m.conversation_id = c.id AND m.deleted_at IS NULL ORDER BY m.created_at DESC, m.id DESC LIMIT 1) lm ON TRUE
+		WHERE p.personnel_id = $1 AND p.deleted_at IS NULL
+		ORDER BY COALESCE(lm.created_at, c.created_at) DESC`
+	args := []interface{}{personnelID}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.ConversationSummary])
+	return result, err
+}
+
+// Create implements domain.ConversationRepository.
+func (r *pgxConversationRepository) Create(ctx context.Context, entity *domain.Conversation) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `INSERT INTO conversations (type, name, direct_key, created_by) VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at`
+	args := []interface{}{entity.Type, entity.Name, entity.DirectKey, entity.CreatedBy}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt, &entity.UpdatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt, &entity.UpdatedAt)
+	}
+	return err
+}
+
+// FindParticipants implements domain.ConversationRepository.
+func (r *pgxConversationRepository) FindParticipants(ctx context.Context, conversationID uuid.UUID) (result []domain.ConversationParticipant, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `SELECT * FROM conversation_participants WHERE conversation_id = $1 AND deleted_at IS NULL ORDER BY created_at`
+	args := []interface{}{conversationID}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.ConversationParticipant])
+	return result, err
+}
+
+// FindContactIDs implements domain.ConversationRepository.
+func (r *pgxConversationRepository) FindContactIDs(ctx context.Context, personnelID uuid.UUID) (result []uuid.UUID, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `SELECT DISTINCT other.personnel_id FROM conversation_participants me
+		JOIN conversation_participants other ON other.conversation_id = me.conversation_id AND other.personnel_id <> me.personnel_id AND other.deleted_at IS NULL
+		WHERE me.personnel_id = $1 AND me.deleted_at IS NULL`
+	args := []interface{}{personnelID}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	return result, err
+}
+
+// FindParticipant implements domain.ConversationRepository.
+func (r *pgxConversationRepository) FindParticipant(ctx context.Context, conversationID, personnelID uuid.UUID) (result domain.ConversationParticipant, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `SELECT * FROM conversation_participants WHERE conversation_id = $1 AND personnel_id = $2 AND deleted_at IS NULL`
+	args := []interface{}{conversationID, personnelID}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.ConversationParticipant])
+	return result, err
+}
+
+// AddParticipants implements domain.ConversationRepository.
+func (r *pgxConversationRepository) AddParticipants(ctx context.Context, entities []*domain.ConversationParticipant) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	b := &pgx.Batch{}
+	for _, entity := range entities {
+		if entity.Role == "" {
+			entity.Role = domain.ParticipantRoleMEMBER
+		}
+		// participants that left come back with a fresh role and without their mute, active ones are returned unchanged
+		q := `INSERT INTO conversation_participants AS p (conversation_id, personnel_id, role) VALUES ($1, $2, $3)
+			ON CONFLICT (conversation_id, personnel_id) DO UPDATE SET
+				role = CASE WHEN p.deleted_at IS NULL THEN p.role ELSE EXCLUDED.role END,
+				muted_until = CASE WHEN p.deleted_at IS NULL THEN p.muted_until END,
+				updated_at = CASE WHEN p.deleted_at IS NULL THEN p.updated_at ELSE NOW() END,
+				deleted_at = NULL
+			RETURNING id, role, muted_until, last_read_at, created_at, updated_at`
+		args := []interface{}{entity.ConversationID, entity.PersonnelID, entity.Role}
+		b.Queue(q, args...)
+	}
+	var br pgx.BatchResults
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		br = tx.SendBatch(ctx, b)
+	} else {
+		br = r.db.SendBatch(ctx, b)
+	}
+	defer func(br pgx.BatchResults) {
+		err := br.Close()
+		if err != nil {
+			log.Println("Error closing batch results", err)
+		}
+	}(br)
+	for idx := range entities {
+		err = br.QueryRow().Scan(&entities[idx].ID, &entities[idx].Role, &entities[idx].MutedUntil, &entities[idx].LastReadAt, &entities[idx].CreatedAt, &entities[idx].UpdatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// RemoveParticipant implements domain.ConversationRepository.
+func (r *pgxConversationRepository) RemoveParticipant(ctx context.Context, conversationID, personnelID uuid.UUID) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `UPDATE conversation_participants SET deleted_at = NOW() WHERE conversation_id = $1 AND personnel_id = $2`
+	args := []interface{}{conversationID, personnelID}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
+
+// UpdateLastReadAt implements domain.ConversationRepository.
+func (r *pgxConversationRepository) UpdateLastReadAt(ctx context.Context, conversationID, personnelID uuid.UUID, readAt time.Time) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	// the marker only moves forward
+	q := `UPDATE conversation_participants SET last_read_at = $3, updated_at = NOW() WHERE conversation_id = $1 AND personnel_id = $2 AND deleted_at IS NULL AND (last_read_at IS NULL OR last_read_at < $3)`
+	args := []interface{}{conversationID, personnelID, readAt}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
+
+// UpdateParticipant implements domain.ConversationRepository.
+func (r *pgxConversationRepository) UpdateParticipant(ctx context.Context, entity *domain.ConversationParticipant) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `UPDATE conversation_participants SET role = $2, muted_until = $3, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL RETURNING updated_at`
+	args := []interface{}{entity.ID, entity.Role, entity.MutedUntil}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.UpdatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.UpdatedAt)
+	}
+	return err
+}
+
+// CreateInvite implements domain.ConversationRepository.
+func (r *pgxConversationRepository) CreateInvite(ctx context.Context, entity *domain.ConversationInvite) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `INSERT INTO conversation_invites (conversation_id, token, created_by, expires_at, max_uses) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at`
+	args := []interface{}{entity.ConversationID, entity.Token, entity.CreatedBy, entity.ExpiresAt, entity.MaxUses}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt, &entity.UpdatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt, &entity.UpdatedAt)
+	}
+	return err
+}
+
+// FindInviteByID implements domain.ConversationRepository.
+func (r *pgxConversationRepository) FindInviteByID(ctx context.Context, id uuid.UUID) (result domain.ConversationInvite, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `SELECT * FROM conversation_invites WHERE id = $1 AND deleted_at IS NULL`
+	args := []interface{}{id}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.ConversationInvite])
+	return result, err
+}
+
+// FindInviteByToken implements domain.ConversationRepository.
+func (r *pgxConversationRepository) FindInviteByToken(ctx context.Context, token string) (result domain.ConversationInvite, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `SELECT * FROM conversation_invites WHERE token = $1 AND deleted_at IS NULL`
+	args := []interface{}{token}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.ConversationInvite])
+	return result, err
+}
+
+// FindInvitesByConversationID implements domain.ConversationRepository.
+func (r *pgxConversationRepository) FindInvitesByConversationID(ctx context.Context, conversationID uuid.UUID) (result []domain.ConversationInvite, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `SELECT * FROM conversation_invites WHERE conversation_id = $1 AND deleted_at IS NULL AND expires_at > NOW() ORDER BY created_at DESC`
+	args := []interface{}{conversationID}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.ConversationInvite])
+	return result, err
+}
+
+// UseInvite implements domain.ConversationRepository.
+func (r *pgxConversationRepository) UseInvite(ctx context.Context, id uuid.UUID) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	// checking the limit in the same statement keeps concurrent joins from exceeding it
+	q := `UPDATE conversation_invites SET uses = uses + 1, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL AND expires_at > NOW() AND (max_uses IS NULL OR uses < max_uses)
+		RETURNING id`
+	args := []interface{}{id}
+	var usedID uuid.UUID
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&usedID)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&usedID)
+	}
+	return err
+}
+
+// DeleteInvite implements domain.ConversationRepository.
+func (r *pgxConversationRepository) DeleteInvite(ctx context.Context, id uuid.UUID) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `UPDATE conversation_invites SET deleted_at = NOW() WHERE id = $1`
+	args := []interface{}{id}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
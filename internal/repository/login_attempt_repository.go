@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/chatApp/internal/domain"
+)
+
+type pgxLoginAttemptRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewLoginAttemptRepository(db *pgxpool.Pool) domain.LoginAttemptRepository {
+	return &pgxLoginAttemptRepository{db: db}
+}
+
+// Find implements domain.LoginAttemptRepository.
+func (r *pgxLoginAttemptRepository) Find(ctx context.Context, scope domain.LoginAttemptScope, subject string) (result domain.LoginAttempt, err error) {
+	return r.find(ctx, `SELECT * FROM login_attempts WHERE scope = $1 AND subject = $2`, scope, subject)
+}
+
+// RecordFailure implements domain.LoginAttemptRepository.
+func (r *pgxLoginAttemptRepository) RecordFailure(ctx context.Context, scope domain.LoginAttemptScope, subject string, resetBefore time.Time) (result domain.LoginAttempt, err error) {
+	// counted in one statement, so concurrent failures are all counted
+	q := `INSERT INTO login_attempts (scope, subject, failures) VALUES ($1, $2, 1)
+		ON CONFLICT (scope, subject) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failed_at < $3 THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failed_at = NOW()
+		RETURNING *`
+	return r.find(ctx, q, scope, subject, resetBefore)
+}
+
+// find returns the failed logins selected by q
+func (r *pgxLoginAttemptRepository) find(ctx context.Context, q string, args ...interface{}) (result domain.LoginAttempt, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.LoginAttempt])
+	return result, err
+}
+
+// Lock implements domain.LoginAttemptRepository.
+func (r *pgxLoginAttemptRepository) Lock(ctx context.Context, scope domain.LoginAttemptScope, subject string, until time.Time) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	// a concurrent failure may have locked it for longer already
+	q := `UPDATE login_attempts SET locked_until = GREATEST(COALESCE(locked_until, $3), $3) WHERE scope = $1 AND subject = $2`
+	args := []interface{}{scope, subject, until}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
+
+// Delete implements domain.LoginAttemptRepository.
+func (r *pgxLoginAttemptRepository) Delete(ctx context.Context, scope domain.LoginAttemptScope, subject string) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `DELETE FROM login_attempts WHERE scope = $1 AND subject = $2`
+	args := []interface{}{scope, subject}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
+
+// DeleteExpired implements domain.LoginAttemptRepository.
+func (r *pgxLoginAttemptRepository) DeleteExpired(ctx context.Context, before time.Time) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `DELETE FROM login_attempts WHERE last_failed_at < $1 AND (locked_until IS NULL OR locked_until < NOW())`
+	args := []interface{}{before}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
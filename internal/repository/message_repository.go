@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"time"
 
 	"github.com/gofrs/uuid/v5"
 	"github.com/jackc/pgx/v5"
@@ -11,6 +13,9 @@ import (
 	"github.com/chatApp/internal/domain"
 )
 
+// messageColumns are the columns of domain.Message, the search vector is left out of the rows
+const messageColumns = `id, sender_id, conversation_id, content, edited_at, reply_to_id, thread_root_id, reply_count, last_reply_at, created_at, updated_at, deleted_at`
+
 type pgxMessageRepository struct {
 	db *pgxpool.Pool
 }
@@ -25,7 +30,7 @@ func (r *pgxMessageRepository) FindAll(ctx context.Context) (result []domain.Mes
 		ctx = context.Background()
 	}
 	txVal := ctx.Value(TxKey)
-	q := `SELECT *  FROM messages WHERE  deleted_at IS NULL`
+	q := `SELECT ` + messageColumns + ` FROM messages WHERE  deleted_at IS NULL`
 	var rows pgx.Rows
 	if txVal != nil {
 		tx := txVal.(pgx.Tx)
@@ -51,7 +56,7 @@ func (r *pgxMessageRepository) FindByID(ctx context.Context, id uuid.UUID) (resu
 		ctx = context.Background()
 	}
 	txVal := ctx.Value(TxKey)
-	q := `SELECT * FROM messages WHERE id = $1 AND deleted_at IS NULL`
+	q := `SELECT ` + messageColumns + ` FROM messages WHERE id = $1 AND deleted_at IS NULL`
 	args := []interface{}{id}
 	var row pgx.Rows
 	if txVal != nil {
@@ -72,14 +77,13 @@ func (r *pgxMessageRepository) FindByID(ctx context.Context, id uuid.UUID) (resu
 
 }
 
-// FindByReceiverID implements domain.MessageRepository.
-func (r *pgxMessageRepository) FindByReceiverID(ctx context.Context, receiver_id uuid.UUID) (result []domain.Message, err error) {
+// FindByConversationID implements domain.MessageRepository.
+func (r *pgxMessageRepository) FindByConversationID(ctx context.Context, conversationID uuid.UUID, page domain.CursorQuery) (result []domain.Message, err error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	txVal := ctx.Value(TxKey)
-	q := `SELECT * FROM messages WHERE receiver_id = $1 AND deleted_at IS NULL`
-	args := []interface{}{receiver_id}
+	q, args := keysetQuery(`SELECT `+messageColumns+` FROM messages WHERE conversation_id = $1 AND deleted_at IS NULL`, []interface{}{conversationID}, page)
 	var rows pgx.Rows
 	if txVal != nil {
 		tx := txVal.(pgx.Tx)
@@ -99,13 +103,12 @@ func (r *pgxMessageRepository) FindByReceiverID(ctx context.Context, receiver_id
 }
 
 // FindBySenderID implements domain.MessageRepository.
-func (r *pgxMessageRepository) FindBySenderID(ctx context.Context, sender_id uuid.UUID) (result []domain.Message, err error) {
+func (r *pgxMessageRepository) FindBySenderID(ctx context.Context, sender_id uuid.UUID, page domain.CursorQuery) (result []domain.Message, err error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	txVal := ctx.Value(TxKey)
-	q := `SELECT * FROM messages WHERE receiver_id = $1 AND deleted_at IS NULL`
-	args := []interface{}{sender_id}
+	q, args := keysetQuery(`SELECT `+messageColumns+` FROM messages WHERE sender_id = $1 AND deleted_at IS NULL`, []interface{}{sender_id}, page)
 	var rows pgx.Rows
 	if txVal != nil {
 		tx := txVal.(pgx.Tx)
@@ -130,8 +133,8 @@ func (r *pgxMessageRepository) Create(ctx context.Context, entity *domain.Messag
 		ctx = context.Background()
 	}
 	txVal := ctx.Value(TxKey)
-	q := `INSERT INTO messages (sender_id, receiver_id, message) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at`
-	args := []interface{}{entity.SenderID, entity.ReceiverID, entity.Content}
+	q := `INSERT INTO messages (sender_id, conversation_id, content, reply_to_id, thread_root_id) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at`
+	args := []interface{}{entity.SenderID, entity.ConversationID, entity.Content, entity.ReplyToID, entity.ThreadRootID}
 
 	if txVal != nil {
 		tx := txVal.(pgx.Tx)
@@ -149,13 +152,13 @@ func (r *pgxMessageRepository) CreateMessageStatus(ctx context.Context, entity *
 		ctx = context.Background()
 	}
 	txVal := ctx.Value(TxKey)
-	q := `INSERT INTO message_statuses (message_id, status) VALUES ($1, $2) RETURNING UpdatedAt`
-	args := []interface{}{entity.MessageID, entity.Mstatus}
+	q := `INSERT INTO message_status (message_id, recipient_id, status) VALUES ($1, $2, $3) RETURNING id, updated_at`
+	args := []interface{}{entity.MessageID, entity.RecipientID, entity.Mstatus}
 	if txVal != nil {
 		tx := txVal.(pgx.Tx)
-		err = tx.QueryRow(ctx, q, args...).Scan(&entity.UpdatedAt)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.UpdatedAt)
 	} else {
-		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.UpdatedAt)
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.UpdatedAt)
 	}
 	return err
 
@@ -169,8 +172,8 @@ func (r *pgxMessageRepository) CreateMultiple(ctx context.Context, entities []*d
 	txVal := ctx.Value(TxKey)
 	b := &pgx.Batch{}
 	for _, entity := range entities {
-		q := `INSERT INTO messages (sender_id, receiver_id, message) VALUES ($1,, $2, $3) RETURNING  id, created_at, updated_at`
-		args := []interface{}{entity.SenderID, entity.ReceiverID, entity.Content}
+		q := `INSERT INTO messages (sender_id, conversation_id, content) VALUES ($1, $2, $3) RETURNING  id, created_at, updated_at`
+		args := []interface{}{entity.SenderID, entity.ConversationID, entity.Content}
 		b.Queue(q, args...)
 	}
 	var br pgx.BatchResults
@@ -201,9 +204,9 @@ func (r *pgxMessageRepository) Update(ctx context.Context, entity *domain.Messag
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	txVal := ctx.Value(ctx)
-	q := `UPDATE messages SET sender_id = $1, receiver_id = $2, message = $3 WHERE id = $4  RETURNING updated_at`
-	args := []interface{}{entity.SenderID, entity.ReceiverID, entity.Content, entity.ID}
+	txVal := ctx.Value(TxKey)
+	q := `UPDATE messages SET sender_id = $1, conversation_id = $2, content = $3, edited_at = $4, updated_at = NOW() WHERE id = $5  RETURNING updated_at`
+	args := []interface{}{entity.SenderID, entity.ConversationID, entity.Content, entity.EditedAt, entity.ID}
 	if txVal != nil {
 		tx := txVal.(pgx.Tx)
 		err = tx.QueryRow(ctx, q, args...).Scan(&entity.UpdatedAt)
@@ -219,13 +222,14 @@ func (r *pgxMessageRepository) UpdateMessageStatus(ctx context.Context, entity *
 		ctx = context.Background()
 	}
 	txVal := ctx.Value(TxKey)
-	q := `UPDATE message_statuses SET status = $1 WHERE message_id = $2 RETURNING  updated_at`
-	args := []interface{}{entity.Mstatus, entity.MessageID}
+	// statuses only move forward, the status enum is ordered Sent < Delivered < Read
+	q := `UPDATE message_status SET status = $1, updated_at = NOW() WHERE message_id = $2 AND recipient_id = $3 AND status < $1 RETURNING id, updated_at`
+	args := []interface{}{entity.Mstatus, entity.MessageID, entity.RecipientID}
 	if txVal != nil {
 		tx := txVal.(pgx.Tx)
-		err = tx.QueryRow(ctx, q, args...).Scan(&entity.UpdatedAt)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.UpdatedAt)
 	} else {
-		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.UpdatedAt)
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.UpdatedAt)
 	}
 	return err
 }
@@ -239,8 +243,8 @@ func (r *pgxMessageRepository) UpdateMultiple(ctx context.Context, entities []*d
 
 	b := &pgx.Batch{}
 	for _, entity := range entities {
-		q := `UPDATE messages SET sender_id = $1, receiver_id = $2, message = $3  WHERE id = $4 `
-		args := []interface{}{entity.SenderID, entity.ReceiverID, entity.Content, entity.ID}
+		q := `UPDATE messages SET sender_id = $1, conversation_id = $2, content = $3, updated_at = NOW()  WHERE id = $4 `
+		args := []interface{}{entity.SenderID, entity.ConversationID, entity.Content, entity.ID}
 		b.Queue(q, args...)
 	}
 	// Send the batch
@@ -271,3 +275,296 @@ func (r *pgxMessageRepository) Delete(ctx context.Context, id uuid.UUID) (err er
 	}
 	return err
 }
+
+// CreateMessageStatusForParticipants implements domain.MessageRepository.
+func (r *pgxMessageRepository) CreateMessageStatusForParticipants(ctx context.Context, message *domain.Message) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `INSERT INTO message_status (message_id, recipient_id, status)
+		SELECT $1, personnel_id, 'Sent' FROM conversation_participants
+		WHERE conversation_id = $2 AND personnel_id <> $3 AND deleted_at IS NULL`
+	args := []interface{}{message.ID, message.ConversationID, message.SenderID}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
+
+// FindMessageStatusByMessageID implements domain.MessageRepository.
+func (r *pgxMessageRepository) FindMessageStatusByMessageID(ctx context.Context, messageID uuid.UUID) (result []domain.MessageStatus, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `SELECT * FROM message_status WHERE message_id = $1 ORDER BY updated_at`
+	args := []interface{}{messageID}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.MessageStatus])
+	return result, err
+}
+
+// MarkConversationRead implements domain.MessageRepository.
+func (r *pgxMessageRepository) MarkConversationRead(ctx context.Context, conversationID, recipientID uuid.UUID, upTo time.Time) (result []domain.MessageStatus, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `UPDATE message_status s SET status = 'Read', updated_at = NOW()
+		FROM messages m
+		WHERE m.id = s.message_id AND m.conversation_id = $1 AND m.created_at <= $3 AND s.recipient_id = $2 AND s.status < 'Read'
+		RETURNING s.id, s.message_id, s.recipient_id, s.status, s.updated_at, m.sender_id`
+	args := []interface{}{conversationID, recipientID, upTo}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.MessageStatus])
+	return result, err
+}
+
+// FindByThreadRootID implements domain.MessageRepository.
+func (r *pgxMessageRepository) FindByThreadRootID(ctx context.Context, threadRootID uuid.UUID, page domain.CursorQuery) (result []domain.Message, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q, args := keysetQuery(`SELECT `+messageColumns+` FROM messages WHERE thread_root_id = $1 AND deleted_at IS NULL`, []interface{}{threadRootID}, page)
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.Message])
+	return result, err
+}
+
+// RefreshThreadSummary implements domain.MessageRepository.
+func (r *pgxMessageRepository) RefreshThreadSummary(ctx context.Context, threadRootID uuid.UUID) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `UPDATE messages root SET reply_count = t.reply_count, last_reply_at = t.last_reply_at
+		FROM (SELECT COUNT(*) AS reply_count, MAX(created_at) AS last_reply_at FROM messages WHERE thread_root_id = $1 AND deleted_at IS NULL) t
+		WHERE root.id = $1`
+	args := []interface{}{threadRootID}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
+
+// AddReaction implements domain.MessageRepository.
+func (r *pgxMessageRepository) AddReaction(ctx context.Context, entity *domain.MessageReaction) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `INSERT INTO message_reactions (message_id, personnel_id, emoji) VALUES ($1, $2, $3)
+		ON CONFLICT (message_id, personnel_id, emoji) DO NOTHING RETURNING id, created_at`
+	args := []interface{}{entity.MessageID, entity.PersonnelID, entity.Emoji}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt)
+	}
+	return err
+}
+
+// RemoveReaction implements domain.MessageRepository.
+func (r *pgxMessageRepository) RemoveReaction(ctx context.Context, messageID, personnelID uuid.UUID, emoji string) (result domain.MessageReaction, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `DELETE FROM message_reactions WHERE message_id = $1 AND personnel_id = $2 AND emoji = $3 RETURNING *`
+	args := []interface{}{messageID, personnelID, emoji}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.MessageReaction])
+	return result, err
+}
+
+// FindReactionSummaries implements domain.MessageRepository.
+func (r *pgxMessageRepository) FindReactionSummaries(ctx context.Context, messageIDs []uuid.UUID, personnelID uuid.UUID) (result []domain.ReactionSummary, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `SELECT message_id, emoji, COUNT(*) AS count, BOOL_OR(personnel_id = $2) AS reacted_by_me, ARRAY_AGG(personnel_id ORDER BY created_at) AS personnel_ids
+		FROM message_reactions WHERE message_id = ANY($1)
+		GROUP BY message_id, emoji
+		ORDER BY message_id, MIN(created_at)`
+	args := []interface{}{messageIDs, personnelID}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.ReactionSummary])
+	return result, err
+}
+
+// CreateMessageEdit implements domain.MessageRepository.
+func (r *pgxMessageRepository) CreateMessageEdit(ctx context.Context, entity *domain.MessageEdit) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `INSERT INTO message_edits (message_id, content, edited_by) VALUES ($1, $2, $3) RETURNING id, created_at`
+	args := []interface{}{entity.MessageID, entity.Content, entity.EditedBy}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt)
+	}
+	return err
+}
+
+// FindEditsByMessageID implements domain.MessageRepository.
+func (r *pgxMessageRepository) FindEditsByMessageID(ctx context.Context, messageID uuid.UUID) (result []domain.MessageEdit, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `SELECT * FROM message_edits WHERE message_id = $1 ORDER BY created_at, id`
+	args := []interface{}{messageID}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.MessageEdit])
+	return result, err
+}
+
+// Search implements domain.MessageRepository.
+func (r *pgxMessageRepository) Search(ctx context.Context, personnelID uuid.UUID, in domain.SearchMessagesInput) (result []domain.MessageSearchResult, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	args := []interface{}{personnelID, in.Query}
+	where := ""
+	if in.SenderID != nil {
+		args = append(args, *in.SenderID)
+		where += fmt.Sprintf(" AND m.sender_id = $%d", len(args))
+	}
+	if in.ConversationID != nil {
+		args = append(args, *in.ConversationID)
+		where += fmt.Sprintf(" AND m.conversation_id = $%d", len(args))
+	}
+	if in.From != nil {
+		args = append(args, *in.From)
+		where += fmt.Sprintf(" AND m.created_at >= $%d", len(args))
+	}
+	if in.To != nil {
+		args = append(args, *in.To)
+		where += fmt.Sprintf(" AND m.created_at <= $%d", len(args))
+	}
+	if in.After != nil {
+		args = append(args, in.After.Rank, in.After.ID)
+		where += fmt.Sprintf(" AND (ts_rank(m.search_vector, q.query), m.id) < ($%d::real, $%d)", len(args)-1, len(args))
+	}
+	args = append(args, in.Limit)
+	// the page is picked before the snippets are made, ts_headline is costly and only needed for the rows returned.
+	// The content is HTML escaped first so the <mark> tags are the only markup in a snippet
+	q := fmt.Sprintf(`SELECT %s, rank,
+		ts_headline('english', replace(replace(replace(content, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), query,
+			'StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "') AS snippet
+		FROM (
+			SELECT m.*, ts_rank(m.search_vector, q.query) AS rank, q.query
+			FROM messages m
+			JOIN conversation_participants p ON p.conversation_id = m.conversation_id AND p.personnel_id = $1 AND p.deleted_at IS NULL
+			CROSS JOIN websearch_to_tsquery('english', $2) q(query)
+			WHERE m.deleted_at IS NULL AND m.search_vector @@ q.query%s
+			ORDER BY rank DESC, m.id DESC
+			LIMIT $%d
+		) r
+		ORDER BY rank DESC, id DESC`, messageColumns, where, len(args))
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.MessageSearchResult])
+	return result, err
+}
+
+// keysetQuery pages a query on (created_at, id), taking the rows closest to the cursor and returning them oldest first.
+// Without a cursor the latest rows are taken.
+func keysetQuery(base string, args []interface{}, page domain.CursorQuery) (string, []interface{}) {
+	order := "DESC"
+	switch {
+	case page.Before != nil:
+		args = append(args, page.Before.CreatedAt, page.Before.ID)
+		base += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", len(args)-1, len(args))
+	case page.After != nil:
+		args = append(args, page.After.CreatedAt, page.After.ID)
+		base += fmt.Sprintf(" AND (created_at, id) > ($%d, $%d)", len(args)-1, len(args))
+		order = "ASC"
+	}
+	args = append(args, page.Limit)
+	q := fmt.Sprintf("SELECT * FROM (%s ORDER BY created_at %s, id %s LIMIT $%d) page ORDER BY created_at, id", base, order, order, len(args))
+	return q, args
+}
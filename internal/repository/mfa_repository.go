@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/chatApp/internal/domain"
+)
+
+type pgxMFARepository struct {
+	db *pgxpool.Pool
+}
+
+func NewMFARepository(db *pgxpool.Pool) domain.MFARepository {
+	return &pgxMFARepository{db: db}
+}
+
+// SaveTOTP implements domain.MFARepository.
+func (r *pgxMFARepository) SaveTOTP(ctx context.Context, entity *domain.TOTPAuthenticator) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	// a confirmed authenticator is left alone, so the query returns no row for it
+	q := `INSERT INTO totp_authenticators (user_id, secret) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_used_step = 0, created_at = NOW()
+		WHERE totp_authenticators.confirmed_at IS NULL
+		RETURNING created_at`
+	args := []interface{}{entity.UserID, entity.Secret}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.CreatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.CreatedAt)
+	}
+	return err
+}
+
+// FindTOTP implements domain.MFARepository.
+func (r *pgxMFARepository) FindTOTP(ctx context.Context, userID uuid.UUID) (result domain.TOTPAuthenticator, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `SELECT * FROM totp_authenticators WHERE user_id = $1`
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, userID)
+	} else {
+		rows, err = r.db.Query(ctx, q, userID)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.TOTPAuthenticator])
+	return result, err
+}
+
+// ConfirmTOTP implements domain.MFARepository.
+func (r *pgxMFARepository) ConfirmTOTP(ctx context.Context, userID uuid.UUID, step int64) (err error) {
+	return r.exec(ctx, `UPDATE totp_authenticators SET confirmed_at = NOW(), last_used_step = $2 WHERE user_id = $1 AND confirmed_at IS NULL`, userID, step)
+}
+
+// UseTOTPStep implements domain.MFARepository.
+func (r *pgxMFARepository) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (err error) {
+	// checked and recorded in one statement, so a code cannot be replayed by concurrent requests
+	return r.execOne(ctx, `UPDATE totp_authenticators SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2`, userID, step)
+}
+
+// DeleteTOTP implements domain.MFARepository.
+func (r *pgxMFARepository) DeleteTOTP(ctx context.Context, userID uuid.UUID) (err error) {
+	q := `WITH codes AS (DELETE FROM recovery_codes WHERE user_id = $1)
+		DELETE FROM totp_authenticators WHERE user_id = $1`
+	return r.exec(ctx, q, userID)
+}
+
+// ReplaceRecoveryCodes implements domain.MFARepository.
+func (r *pgxMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes []string) (err error) {
+	q := `WITH previous AS (DELETE FROM recovery_codes WHERE user_id = $1)
+		INSERT INTO recovery_codes (user_id, code_hash) SELECT $1, UNNEST($2::VARCHAR[])`
+	return r.exec(ctx, q, userID, hashes)
+}
+
+// UseRecoveryCode implements domain.MFARepository.
+func (r *pgxMFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash string) (err error) {
+	return r.execOne(ctx, `UPDATE recovery_codes SET used_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`, userID, hash)
+}
+
+// CreateChallenge implements domain.MFARepository.
+func (r *pgxMFARepository) CreateChallenge(ctx context.Context, entity *domain.MFAChallenge) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `INSERT INTO mfa_challenges (user_id, token_hash, device_name, user_agent, ip_address, expires_at) VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+	args := []interface{}{entity.UserID, entity.TokenHash, entity.DeviceName, entity.UserAgent, entity.IPAddress, entity.ExpiresAt}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt)
+	}
+	return err
+}
+
+// CountChallengeAttempt implements domain.MFARepository.
+func (r *pgxMFARepository) CountChallengeAttempt(ctx context.Context, hash string, maxAttempts int) (result domain.MFAChallenge, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	// counted before the code is checked, so concurrent guesses cannot exceed the limit
+	q := `UPDATE mfa_challenges SET attempts = attempts + 1 WHERE token_hash = $1 AND attempts < $2 AND expires_at > NOW() RETURNING *`
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, hash, maxAttempts)
+	} else {
+		rows, err = r.db.Query(ctx, q, hash, maxAttempts)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.MFAChallenge])
+	return result, err
+}
+
+// DeleteChallenge implements domain.MFARepository.
+func (r *pgxMFARepository) DeleteChallenge(ctx context.Context, id uuid.UUID) (err error) {
+	return r.exec(ctx, `DELETE FROM mfa_challenges WHERE id = $1`, id)
+}
+
+// DeleteExpiredChallenges implements domain.MFARepository.
+func (r *pgxMFARepository) DeleteExpiredChallenges(ctx context.Context, before time.Time) (err error) {
+	return r.exec(ctx, `DELETE FROM mfa_challenges WHERE expires_at <= $1`, before)
+}
+
+// exec runs a statement
+func (r *pgxMFARepository) exec(ctx context.Context, q string, args ...interface{}) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
+
+// execOne runs a statement that must change a row, it fails with pgx.ErrNoRows when it changes none
+func (r *pgxMFARepository) execOne(ctx context.Context, q string, args ...interface{}) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	var tag pgconn.CommandTag
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		tag, err = tx.Exec(ctx, q, args...)
+	} else {
+		tag, err = r.db.Exec(ctx, q, args...)
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/chatApp/internal/domain"
+)
+
+type pgxOTPRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewOTPRepository(db *pgxpool.Pool) domain.OTPRepository {
+	return &pgxOTPRepository{db: db}
+}
+
+// Save implements domain.OTPRepository.
+func (r *pgxOTPRepository) Save(ctx context.Context, entity *domain.OTP) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `INSERT INTO otp_codes (user_name, code_hash, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (user_name) DO UPDATE SET code_hash = EXCLUDED.code_hash, attempts = 0, expires_at = EXCLUDED.expires_at, sent_at = NOW()
+		RETURNING id, attempts, sent_at`
+	args := []interface{}{entity.UserName, entity.CodeHash, entity.ExpiresAt}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.Attempts, &entity.SentAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.Attempts, &entity.SentAt)
+	}
+	return err
+}
+
+// FindByUserName implements domain.OTPRepository.
+func (r *pgxOTPRepository) FindByUserName(ctx context.Context, userName string) (result domain.OTP, err error) {
+	return r.find(ctx, `SELECT * FROM otp_codes WHERE user_name = $1`, userName)
+}
+
+// CountAttempt implements domain.OTPRepository.
+func (r *pgxOTPRepository) CountAttempt(ctx context.Context, userName string, maxAttempts int) (result domain.OTP, err error) {
+	// counted before the code is compared, so concurrent guesses cannot exceed the limit
+	return r.find(ctx, `UPDATE otp_codes SET attempts = attempts + 1 WHERE user_name = $1 AND attempts < $2 RETURNING *`, userName, maxAttempts)
+}
+
+// find returns the one time password selected by q
+func (r *pgxOTPRepository) find(ctx context.Context, q string, args ...interface{}) (result domain.OTP, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.OTP])
+	return result, err
+}
+
+// Delete implements domain.OTPRepository.
+func (r *pgxOTPRepository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `DELETE FROM otp_codes WHERE id = $1`
+	args := []interface{}{id}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
+
+// DeleteExpired implements domain.OTPRepository.
+func (r *pgxOTPRepository) DeleteExpired(ctx context.Context, before time.Time) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `DELETE FROM otp_codes WHERE expires_at <= $1`
+	args := []interface{}{before}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/chatApp/internal/domain"
+)
+
+type pgxPasswordHistoryRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPasswordHistoryRepository(db *pgxpool.Pool) domain.PasswordHistoryRepository {
+	return &pgxPasswordHistoryRepository{db: db}
+}
+
+// Create implements domain.PasswordHistoryRepository.
+func (r *pgxPasswordHistoryRepository) Create(ctx context.Context, entity *domain.PasswordHistory, keep int) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `WITH created AS (
+			INSERT INTO password_history (user_id, password_hash) VALUES ($1, $2) RETURNING id, created_at
+		), pruned AS (
+			DELETE FROM password_history WHERE user_id = $1 AND id NOT IN (
+				SELECT id FROM password_history WHERE user_id = $1 ORDER BY created_at DESC LIMIT $3
+			)
+		)
+		SELECT id, created_at FROM created`
+	// the new row is invisible to the prune, which keeps one fewer of the older rows
+	args := []interface{}{entity.UserID, entity.PasswordHash, max(keep-1, 0)}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt)
+	}
+	return err
+}
+
+// FindLatest implements domain.PasswordHistoryRepository.
+func (r *pgxPasswordHistoryRepository) FindLatest(ctx context.Context, userID uuid.UUID, limit int) (result []domain.PasswordHistory, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `SELECT * FROM password_history WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2`
+	args := []interface{}{userID, limit}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.PasswordHistory])
+	return result, err
+}
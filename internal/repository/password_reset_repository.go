@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/chatApp/internal/domain"
+)
+
+type pgxPasswordResetRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPasswordResetRepository(db *pgxpool.Pool) domain.PasswordResetRepository {
+	return &pgxPasswordResetRepository{db: db}
+}
+
+// Save implements domain.PasswordResetRepository.
+func (r *pgxPasswordResetRepository) Save(ctx context.Context, entity *domain.PasswordResetToken) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `INSERT INTO password_reset_tokens (user_id, token_hash, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET token_hash = EXCLUDED.token_hash, expires_at = EXCLUDED.expires_at, used_at = NULL, created_at = NOW()
+		RETURNING id, created_at`
+	args := []interface{}{entity.UserID, entity.TokenHash, entity.ExpiresAt}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt)
+	}
+	return err
+}
+
+// Consume implements domain.PasswordResetRepository.
+func (r *pgxPasswordResetRepository) Consume(ctx context.Context, hash string) (result domain.PasswordResetToken, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	// checked and marked in one statement, so a token cannot be used twice by concurrent requests
+	q := `UPDATE password_reset_tokens SET used_at = NOW() WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW() RETURNING *`
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, hash)
+	} else {
+		rows, err = r.db.Query(ctx, q, hash)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.PasswordResetToken])
+	return result, err
+}
+
+// DeleteExpired implements domain.PasswordResetRepository.
+func (r *pgxPasswordResetRepository) DeleteExpired(ctx context.Context, before time.Time) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `DELETE FROM password_reset_tokens WHERE expires_at <= $1`
+	args := []interface{}{before}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
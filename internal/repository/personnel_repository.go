@@ -175,3 +175,21 @@ func (r *pgxPersonnelRepository) Update(ctx context.Context, entity *domain.Pers
 	}
 	return err
 }
+
+// UpdateAvatar implements domain.PersonnelRepository.
+func (r *pgxPersonnelRepository) UpdateAvatar(ctx context.Context, entity *domain.Personnel) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	q := `UPDATE personnel SET avatar = $1, avatar_key = $2, updated_at = NOW() WHERE id = $3 AND deleted_at IS NULL RETURNING updated_at`
+	args := []interface{}{entity.Avatar, entity.AvatarKey, entity.ID}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.UpdatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.UpdatedAt)
+	}
+	return err
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/chatApp/internal/domain"
+)
+
+// presenceQuery aggregates the fresh connections of personnel, a personnel is ONLINE when one of them is, AWAY when
+// they all are and OFFLINE without any
+const presenceQuery = `SELECT pe.id AS personnel_id, pe.user_id, pe.last_seen_at,
+		CASE WHEN BOOL_OR(c.status = 'ONLINE') THEN 'ONLINE' WHEN COUNT(c.id) > 0 THEN 'AWAY' ELSE 'OFFLINE' END AS status
+	FROM personnel pe
+	LEFT JOIN presence_connections c ON c.personnel_id = pe.id AND c.heartbeat_at > $2
+	WHERE %s AND pe.deleted_at IS NULL
+	GROUP BY pe.id`
+
+type pgxPresenceRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPresenceRepository(db *pgxpool.Pool) domain.PresenceRepository {
+	return &pgxPresenceRepository{db: db}
+}
+
+// FindByPersonnelIDs implements domain.PresenceRepository.
+func (r *pgxPresenceRepository) FindByPersonnelIDs(ctx context.Context, personnelIDs []uuid.UUID, freshAfter time.Time) (result []domain.Presence, err error) {
+	return r.find(ctx, "pe.id = ANY($1)", personnelIDs, freshAfter)
+}
+
+// FindByUserIDs implements domain.PresenceRepository.
+func (r *pgxPresenceRepository) FindByUserIDs(ctx context.Context, userIDs []uuid.UUID, freshAfter time.Time) (result []domain.Presence, err error) {
+	return r.find(ctx, "pe.user_id = ANY($1)", userIDs, freshAfter)
+}
+
+// find runs presenceQuery for the personnel matching the condition on $1
+func (r *pgxPresenceRepository) find(ctx context.Context, cond string, ids []uuid.UUID, freshAfter time.Time) (result []domain.Presence, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := fmt.Sprintf(presenceQuery, cond)
+	args := []interface{}{ids, freshAfter}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.Presence])
+	return result, err
+}
+
+// SaveConnection implements domain.PresenceRepository.
+func (r *pgxPresenceRepository) SaveConnection(ctx context.Context, entity *domain.PresenceConnection) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `INSERT INTO presence_connections (id, personnel_id, status) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET status = EXCLUDED.status, heartbeat_at = NOW()
+		RETURNING connected_at, heartbeat_at`
+	args := []interface{}{entity.ID, entity.PersonnelID, entity.Status}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ConnectedAt, &entity.HeartbeatAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ConnectedAt, &entity.HeartbeatAt)
+	}
+	return err
+}
+
+// TouchConnection implements domain.PresenceRepository.
+func (r *pgxPresenceRepository) TouchConnection(ctx context.Context, id uuid.UUID) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `UPDATE presence_connections SET heartbeat_at = NOW() WHERE id = $1`
+	args := []interface{}{id}
+	var tag pgconn.CommandTag
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		tag, err = tx.Exec(ctx, q, args...)
+	} else {
+		tag, err = r.db.Exec(ctx, q, args...)
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteConnection implements domain.PresenceRepository.
+func (r *pgxPresenceRepository) DeleteConnection(ctx context.Context, id uuid.UUID) (result domain.PresenceConnection, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `DELETE FROM presence_connections WHERE id = $1 RETURNING *`
+	args := []interface{}{id}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.PresenceConnection])
+	return result, err
+}
+
+// DeleteStaleConnections implements domain.PresenceRepository.
+func (r *pgxPresenceRepository) DeleteStaleConnections(ctx context.Context, before time.Time) (result []domain.PresenceConnection, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `DELETE FROM presence_connections WHERE heartbeat_at <= $1 RETURNING *`
+	args := []interface{}{before}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.PresenceConnection])
+	return result, err
+}
+
+// UpdateLastSeen implements domain.PresenceRepository.
+func (r *pgxPresenceRepository) UpdateLastSeen(ctx context.Context, personnelID uuid.UUID, at time.Time) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `UPDATE personnel SET last_seen_at = GREATEST(last_seen_at, $2) WHERE id = $1`
+	args := []interface{}{personnelID, at}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
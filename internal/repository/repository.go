@@ -115,9 +115,7 @@ func loadAssociations[T any](ctx context.Context, db *pgxpool.Pool, filterColumn
 	return result, err
 }
 
-
 const (
-	TblUser = "user"
+	TblUser      = "user"
 	TblPersonnel = "personnel"
-	
-)
\ No newline at end of file
+)
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/chatApp/internal/domain"
+)
+
+type pgxSessionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSessionRepository(db *pgxpool.Pool) domain.SessionRepository {
+	return &pgxSessionRepository{db: db}
+}
+
+// Create implements domain.SessionRepository.
+func (r *pgxSessionRepository) Create(ctx context.Context, entity *domain.Session) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `INSERT INTO sessions (user_id, user_agent, ip_address, device_name, mfa, expires_at) VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, last_used_at`
+	args := []interface{}{entity.UserID, entity.UserAgent, entity.IPAddress, entity.DeviceName, entity.MFA, entity.ExpiresAt}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt, &entity.LastUsedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt, &entity.LastUsedAt)
+	}
+	return err
+}
+
+// FindByID implements domain.SessionRepository.
+func (r *pgxSessionRepository) FindByID(ctx context.Context, id uuid.UUID) (result domain.Session, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `SELECT * FROM sessions WHERE id = $1`
+	args := []interface{}{id}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.Session])
+	return result, err
+}
+
+// FindActiveByUserID implements domain.SessionRepository.
+func (r *pgxSessionRepository) FindActiveByUserID(ctx context.Context, userID uuid.UUID) (result []domain.Session, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `SELECT * FROM sessions WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW() ORDER BY last_used_at DESC`
+	args := []interface{}{userID}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.Session])
+	return result, err
+}
+
+// Touch implements domain.SessionRepository.
+func (r *pgxSessionRepository) Touch(ctx context.Context, id uuid.UUID, ipAddress, userAgent string, expiresAt time.Time) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `UPDATE sessions SET last_used_at = NOW(), ip_address = $2, user_agent = $3, expires_at = $4 WHERE id = $1`
+	args := []interface{}{id, ipAddress, userAgent, expiresAt}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
+
+// Revoke implements domain.SessionRepository.
+func (r *pgxSessionRepository) Revoke(ctx context.Context, id uuid.UUID) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	args := []interface{}{id}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
+
+// DeleteExpired implements domain.SessionRepository.
+func (r *pgxSessionRepository) DeleteExpired(ctx context.Context, before time.Time) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `DELETE FROM sessions WHERE expires_at <= $1`
+	args := []interface{}{before}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
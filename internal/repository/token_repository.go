@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/chatApp/internal/domain"
+)
+
+type pgxTokenRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTokenRepository(db *pgxpool.Pool) domain.TokenRepository {
+	return &pgxTokenRepository{db: db}
+}
+
+// CreateRefreshToken implements domain.TokenRepository.
+func (r *pgxTokenRepository) CreateRefreshToken(ctx context.Context, entity *domain.RefreshToken) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `INSERT INTO refresh_tokens (user_id, session_id, token_hash, access_token_id, access_expires_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`
+	args := []interface{}{entity.UserID, entity.SessionID, entity.TokenHash, entity.AccessTokenID, entity.AccessExpiresAt, entity.ExpiresAt}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt)
+	}
+	return err
+}
+
+// FindRefreshTokenByHash implements domain.TokenRepository.
+func (r *pgxTokenRepository) FindRefreshTokenByHash(ctx context.Context, hash string) (result domain.RefreshToken, err error) {
+	return r.findRefreshToken(ctx, `SELECT * FROM refresh_tokens WHERE token_hash = $1`, hash)
+}
+
+// FindRefreshTokenByAccessTokenID implements domain.TokenRepository.
+func (r *pgxTokenRepository) FindRefreshTokenByAccessTokenID(ctx context.Context, accessTokenID uuid.UUID) (result domain.RefreshToken, err error) {
+	return r.findRefreshToken(ctx, `SELECT * FROM refresh_tokens WHERE access_token_id = $1`, accessTokenID)
+}
+
+// findRefreshToken returns the refresh token selected by q
+func (r *pgxTokenRepository) findRefreshToken(ctx context.Context, q string, args ...interface{}) (result domain.RefreshToken, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.RefreshToken])
+	return result, err
+}
+
+// MarkRefreshTokenUsed implements domain.TokenRepository.
+func (r *pgxTokenRepository) MarkRefreshTokenUsed(ctx context.Context, id uuid.UUID) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`
+	args := []interface{}{id}
+	var tag pgconn.CommandTag
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		tag, err = tx.Exec(ctx, q, args...)
+	} else {
+		tag, err = r.db.Exec(ctx, q, args...)
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// RevokeSessionTokens implements domain.TokenRepository.
+func (r *pgxTokenRepository) RevokeSessionTokens(ctx context.Context, sessionID uuid.UUID) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	// a single statement, so the access tokens are denied exactly when their refresh tokens are revoked
+	q := `WITH revoked AS (
+			UPDATE refresh_tokens SET revoked_at = NOW() WHERE session_id = $1 AND revoked_at IS NULL
+			RETURNING access_token_id, access_expires_at
+		)
+		INSERT INTO revoked_tokens (id, expires_at)
+		SELECT access_token_id, access_expires_at FROM revoked WHERE access_expires_at > NOW()
+		ON CONFLICT (id) DO NOTHING`
+	args := []interface{}{sessionID}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
+
+// RevokeAccessToken implements domain.TokenRepository.
+func (r *pgxTokenRepository) RevokeAccessToken(ctx context.Context, id uuid.UUID, expiresAt time.Time) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `INSERT INTO revoked_tokens (id, expires_at) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING`
+	args := []interface{}{id, expiresAt}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
+
+// IsAccessTokenRevoked implements domain.TokenRepository.
+func (r *pgxTokenRepository) IsAccessTokenRevoked(ctx context.Context, id uuid.UUID) (result bool, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE id = $1)`
+	args := []interface{}{id}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&result)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&result)
+	}
+	return result, err
+}
+
+// DeleteExpired implements domain.TokenRepository.
+func (r *pgxTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `WITH refresh AS (DELETE FROM refresh_tokens WHERE expires_at <= $1)
+		DELETE FROM revoked_tokens WHERE expires_at <= $1`
+	args := []interface{}{before}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
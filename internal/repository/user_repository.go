@@ -179,7 +179,7 @@ func (r *pgxUserRepository) UpdateUser(ctx context.Context, entity *domain.User)
 	txVal := ctx.Value(TxKey)
 
 	// Update the data
-	q := `UPDATE users SET username = $1, password = $2, role = $3,  updated_at = NOW() WHERE id = $4 RETURNING updated_at`
+	q := `UPDATE users SET user_name = $1, password = $2, role = $3,  updated_at = NOW() WHERE id = $4 RETURNING updated_at`
 	args := []interface{}{entity.UserName, entity.Password, entity.Role, entity.ID}
 	if txVal != nil {
 		tx := txVal.(pgx.Tx)
@@ -190,3 +190,37 @@ func (r *pgxUserRepository) UpdateUser(ctx context.Context, entity *domain.User)
 
 	return err
 }
+
+// UpdatePassword implements domain.UserRepository.
+func (r *pgxUserRepository) UpdatePassword(ctx context.Context, id uuid.UUID, hash string) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `UPDATE users SET password = $1, updated_at = NOW() WHERE id = $2`
+	args := []interface{}{hash, id}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
+
+// MarkMobileVerified implements domain.UserRepository.
+func (r *pgxUserRepository) MarkMobileVerified(ctx context.Context, id uuid.UUID) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+	q := `UPDATE users SET mobile_verified_at = COALESCE(mobile_verified_at, NOW()), updated_at = NOW() WHERE id = $1`
+	args := []interface{}{id}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+	return err
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/chatApp/internal/domain"
+	"github.com/chatApp/internal/pkg/blobstore"
+	"github.com/chatApp/internal/pkg/config"
+	"github.com/chatApp/internal/pkg/imaging"
+	"github.com/chatApp/internal/pkg/worker"
+)
+
+// defaultAttachmentMaxSize is the largest attachment accepted in megabytes when none is configured
+const defaultAttachmentMaxSize = 25
+
+// defaultAttachmentAllowedTypes are the MIME types accepted when none are configured
+var defaultAttachmentAllowedTypes = []string{"image/*", "video/*", "audio/*", "text/plain", "application/pdf", "application/zip"}
+
+// defaultAttachmentThumbnailSizes are the boxes, in pixels, image thumbnails are scaled to fit when none are configured
+var defaultAttachmentThumbnailSizes = []int{160, 480, 1080}
+
+// imageProcessingTimeout bounds the time spent processing a single image in the background
+const imageProcessingTimeout = time.Minute
+
+type attachmentServiceImpl struct {
+	ar  domain.AttachmentRepository
+	bs  blobstore.BlobStore
+	cfg config.ChatApiConfig
+	cr  domain.ConversationRepository
+	wp  *worker.Pool
+}
+
+func NewAttachmentService(ar domain.AttachmentRepository, bs blobstore.BlobStore, cfg config.ChatApiConfig, cr domain.ConversationRepository, wp *worker.Pool) domain.AttachmentService {
+	return &attachmentServiceImpl{
+		ar:  ar,
+		bs:  bs,
+		cfg: cfg,
+		cr:  cr,
+		wp:  wp,
+	}
+}
+
+// Upload implements domain.AttachmentService.
+func (s *attachmentServiceImpl) Upload(personnelID uuid.UUID, in domain.UploadAttachmentInput) (result domain.Attachment, err error) {
+	_, err = findConversationForParticipant(context.Background(), s.cr, personnelID, in.ConversationID)
+	if err != nil {
+		return result, err
+	}
+	if in.Size > AttachmentMaxSize(s.cfg) {
+		return result, domain.UserError{Code: domain.ErrorCodeINVALID_ATTACHMENT, Message: domain.MessageATTACHMENTTOOLARGE}
+	}
+	// the type is sniffed from the content rather than trusted from the client
+	head := make([]byte, 512)
+	n, err := io.ReadFull(in.Content, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return result, err
+	}
+	head = head[:n]
+	contentType := detectContentType(head, in.FileName)
+	if !mimeAllowed(contentType, s.allowedTypes()) {
+		return result, domain.UserError{Code: domain.ErrorCodeINVALID_ATTACHMENT, Message: domain.MessageATTACHMENTTYPE}
+	}
+
+	key := "conversations/" + in.ConversationID.String() + "/" + uuid.Must(uuid.NewV4()).String()
+	var content io.Reader = io.LimitReader(io.MultiReader(bytes.NewReader(head), in.Content), in.Size)
+	size := in.Size
+	if imaging.Supported(contentType) {
+		// the location is removed before the file is stored, so it can never be downloaded
+		data, err := io.ReadAll(content)
+		if err != nil {
+			return result, err
+		}
+		data, _, err = imaging.StripLocation(data)
+		if err != nil {
+			// images that cannot be read cannot be cleaned either
+			return result, domain.UserError{Code: domain.ErrorCodeINVALID_ATTACHMENT, Message: domain.MessageATTACHMENTTYPE}
+		}
+		content, size = bytes.NewReader(data), int64(len(data))
+	}
+	err = s.bs.Put(context.Background(), key, content, size, contentType)
+	if err != nil {
+		return result, err
+	}
+	result = domain.Attachment{
+		ConversationID: in.ConversationID,
+		UploaderID:     personnelID,
+		FileName:       filepath.Base(in.FileName),
+		ContentType:    contentType,
+		Size:           size,
+		StorageKey:     key,
+		Thumbnails:     []domain.AttachmentThumbnail{},
+	}
+	err = s.ar.Create(context.Background(), &result)
+	if err != nil {
+		// do not leave the content behind without a row pointing at it
+		if delErr := s.bs.Delete(context.Background(), key); delErr != nil {
+			slog.Error("failed to remove orphaned attachment", "key", key, "err", delErr)
+		}
+		return result, err
+	}
+	if imaging.Supported(contentType) {
+		// thumbnails are made in the background so the upload returns as soon as the file is stored
+		a := result
+		submitErr := s.wp.Submit(func(ctx context.Context) {
+			if err := s.processImage(ctx, a); err != nil {
+				slog.Error("failed to process image attachment", "attachment_id", a.ID, "err", err)
+			}
+		})
+		if submitErr != nil {
+			slog.Warn("image attachment left unprocessed", "attachment_id", a.ID, "err", submitErr)
+		}
+	}
+	return result, nil
+}
+
+// Download implements domain.AttachmentService.
+func (s *attachmentServiceImpl) Download(personnelID, id uuid.UUID) (result domain.Attachment, content io.ReadCloser, err error) {
+	result, err = s.ar.FindByID(context.Background(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, nil, domain.DataNotFoundError{}
+		}
+		return result, nil, err
+	}
+	_, err = findConversationForParticipant(context.Background(), s.cr, personnelID, result.ConversationID)
+	if err != nil {
+		return domain.Attachment{}, nil, err
+	}
+	content, err = s.bs.Get(context.Background(), result.StorageKey)
+	if err != nil {
+		if errors.Is(err, blobstore.ErrNotFound) {
+			return result, nil, domain.DataNotFoundError{}
+		}
+		return result, nil, err
+	}
+	return result, content, nil
+}
+
+// DownloadThumbnail implements domain.AttachmentService.
+func (s *attachmentServiceImpl) DownloadThumbnail(personnelID, id uuid.UUID, size int) (result domain.AttachmentThumbnail, content io.ReadCloser, err error) {
+	a, err := s.ar.FindByID(context.Background(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, nil, domain.DataNotFoundError{}
+		}
+		return result, nil, err
+	}
+	_, err = findConversationForParticipant(context.Background(), s.cr, personnelID, a.ConversationID)
+	if err != nil {
+		return result, nil, err
+	}
+	i := slices.IndexFunc(a.Thumbnails, func(t domain.AttachmentThumbnail) bool { return t.Size == size })
+	if i < 0 {
+		return result, nil, domain.DataNotFoundError{}
+	}
+	result = a.Thumbnails[i]
+	content, err = s.bs.Get(context.Background(), thumbnailKey(a.StorageKey, size))
+	if err != nil {
+		if errors.Is(err, blobstore.ErrNotFound) {
+			return domain.AttachmentThumbnail{}, nil, domain.DataNotFoundError{}
+		}
+		return domain.AttachmentThumbnail{}, nil, err
+	}
+	return result, content, nil
+}
+
+// processImage records the dimensions and placeholder of an image attachment and stores a thumbnail for every
+// configured size smaller than the image
+func (s *attachmentServiceImpl) processImage(ctx context.Context, a domain.Attachment) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, imageProcessingTimeout)
+	defer cancel()
+	rc, err := s.bs.Get(ctx, a.StorageKey)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(io.LimitReader(rc, AttachmentMaxSize(s.cfg)))
+	rc.Close()
+	if err != nil {
+		return err
+	}
+	pic, err := imaging.Decode(data)
+	if err != nil {
+		if errors.Is(err, imaging.ErrUnsupportedFormat) || errors.Is(err, imaging.ErrImageTooLarge) {
+			// the file stays downloadable, it just gets no preview
+			return nil
+		}
+		return err
+	}
+	width, height := pic.Width(), pic.Height()
+	placeholder := pic.Placeholder()
+	a.Width, a.Height, a.Placeholder = &width, &height, &placeholder
+	a.Thumbnails = []domain.AttachmentThumbnail{}
+	for _, size := range s.thumbnailSizes() {
+		if width <= size && height <= size {
+			// the image itself is small enough
+			break
+		}
+		img := pic.Thumbnail(size)
+		content, contentType, err := imaging.Encode(img)
+		if err != nil {
+			return err
+		}
+		err = s.bs.Put(ctx, thumbnailKey(a.StorageKey, size), bytes.NewReader(content), int64(len(content)), contentType)
+		if err != nil {
+			return err
+		}
+		a.Thumbnails = append(a.Thumbnails, domain.AttachmentThumbnail{
+			Size:        size,
+			Width:       img.Bounds().Dx(),
+			Height:      img.Bounds().Dy(),
+			ContentType: contentType,
+		})
+	}
+	return s.ar.UpdateImage(ctx, &a)
+}
+
+// thumbnailSizes returns the configured thumbnail sizes, smallest first
+func (s *attachmentServiceImpl) thumbnailSizes() []int {
+	sizes := slices.Clone(defaultAttachmentThumbnailSizes)
+	if len(s.cfg.AttachmentThumbnailSizes) > 0 {
+		sizes = slices.Clone(s.cfg.AttachmentThumbnailSizes)
+	}
+	sizes = slices.DeleteFunc(sizes, func(size int) bool { return size <= 0 })
+	slices.Sort(sizes)
+	return slices.Compact(sizes)
+}
+
+// thumbnailKey returns the key a thumbnail of an attachment is stored under
+func thumbnailKey(storageKey string, size int) string {
+	return storageKey + "_" + strconv.Itoa(size)
+}
+
+// allowedTypes returns the configured MIME allow-list
+func (s *attachmentServiceImpl) allowedTypes() []string {
+	if len(s.cfg.AttachmentAllowedTypes) == 0 {
+		return defaultAttachmentAllowedTypes
+	}
+	return s.cfg.AttachmentAllowedTypes
+}
+
+// AttachmentMaxSize returns the largest attachment accepted in bytes
+func AttachmentMaxSize(cfg config.ChatApiConfig) int64 {
+	size := cfg.AttachmentMaxSize
+	if size <= 0 {
+		size = defaultAttachmentMaxSize
+	}
+	return int64(size) << 20
+}
+
+// detectContentType sniffs the MIME type of the content, falling back on the file extension for the types that
+// cannot be told apart from their first bytes
+func detectContentType(head []byte, fileName string) string {
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if contentType == "application/octet-stream" {
+		byExt, _, _ := mime.ParseMediaType(mime.TypeByExtension(filepath.Ext(fileName)))
+		if byExt != "" {
+			contentType = byExt
+		}
+	}
+	return contentType
+}
+
+// mimeAllowed reports whether the MIME type is on the allow-list, an entry ending in /* allows every subtype
+func mimeAllowed(contentType string, allowed []string) bool {
+	for _, a := range allowed {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == contentType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok && strings.HasPrefix(contentType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,454 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/chatApp/internal/domain"
+	"github.com/chatApp/internal/pkg/config"
+	"github.com/chatApp/internal/pkg/util"
+)
+
+// pgUniqueViolation is the Postgres error code raised when a unique constraint is violated
+const pgUniqueViolation = "23505"
+
+// defaultInviteExpiryPeriod is the validity of an invite in hours when none is configured
+const defaultInviteExpiryPeriod = 24
+
+type conversationServiceImpl struct {
+	apu util.AppUtil
+	cfg config.ChatApiConfig
+	cr  domain.ConversationRepository
+	ep  domain.EventPublisher
+	pr  domain.PersonnelRepository
+	tr  domain.Transactioner
+}
+
+func NewConversationService(apu util.AppUtil, cfg config.ChatApiConfig, cr domain.ConversationRepository, ep domain.EventPublisher, pr domain.PersonnelRepository, tr domain.Transactioner) domain.ConversationService {
+	return &conversationServiceImpl{
+		apu: apu,
+		cfg: cfg,
+		cr:  cr,
+		ep:  ep,
+		pr:  pr,
+		tr:  tr,
+	}
+}
+
+// FindByID implements domain.ConversationService.
+func (s *conversationServiceImpl) FindByID(personnelID, id uuid.UUID) (result domain.Conversation, err error) {
+	result, err = findConversationForParticipant(context.Background(), s.cr, personnelID, id)
+	return result, err
+}
+
+// FindByParticipant implements domain.ConversationService.
+func (s *conversationServiceImpl) FindByParticipant(personnelID uuid.UUID) (result []domain.ConversationSummary, err error) {
+	return s.cr.FindByParticipant(context.Background(), personnelID)
+}
+
+// CreateDirect implements domain.ConversationService.
+func (s *conversationServiceImpl) CreateDirect(personnelID uuid.UUID, in domain.CreateDirectConversationInput) (result domain.Conversation, err error) {
+	if in.PersonnelID == personnelID {
+		return result, domain.UserError{Code: domain.ErrorCodeINVALID_CONVERSATION, Message: domain.MessageCONVERSATIONSELF}
+	}
+	err = s.ensurePersonnelExist(context.Background(), []uuid.UUID{in.PersonnelID})
+	if err != nil {
+		return result, err
+	}
+	// a pair of personnel shares a single direct conversation
+	directKey := directKeyForPair(personnelID, in.PersonnelID)
+	result, err = s.cr.FindByDirectKey(context.Background(), directKey)
+	if err == nil {
+		return result, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return result, err
+	}
+
+	result = domain.Conversation{
+		Type:      domain.ConversationTypeDIRECT,
+		DirectKey: &directKey,
+		CreatedBy: &personnelID,
+	}
+	participants, err := s.create(&result, []uuid.UUID{personnelID, in.PersonnelID})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			// created concurrently by the other participant
+			return s.cr.FindByDirectKey(context.Background(), directKey)
+		}
+		return result, err
+	}
+	s.publishCreated(result, participants)
+	return result, nil
+}
+
+// CreateGroup implements domain.ConversationService.
+func (s *conversationServiceImpl) CreateGroup(personnelID uuid.UUID, in domain.CreateGroupConversationInput) (result domain.Conversation, err error) {
+	participantIDs := uniqueIDs(append([]uuid.UUID{personnelID}, in.ParticipantIDs...))
+	err = s.ensurePersonnelExist(context.Background(), participantIDs)
+	if err != nil {
+		return result, err
+	}
+	result = domain.Conversation{
+		Type:      domain.ConversationTypeGROUP,
+		Name:      &in.Name,
+		CreatedBy: &personnelID,
+	}
+	participants, err := s.create(&result, participantIDs)
+	if err != nil {
+		return result, err
+	}
+	s.publishCreated(result, participants)
+	return result, nil
+}
+
+// FindParticipants implements domain.ConversationService.
+func (s *conversationServiceImpl) FindParticipants(personnelID, id uuid.UUID) (result []domain.ConversationParticipant, err error) {
+	_, err = findConversationForParticipant(context.Background(), s.cr, personnelID, id)
+	if err != nil {
+		return result, err
+	}
+	return s.cr.FindParticipants(context.Background(), id)
+}
+
+// AddParticipants implements domain.ConversationService.
+func (s *conversationServiceImpl) AddParticipants(personnelID, id uuid.UUID, in domain.AddParticipantsInput) (result []domain.ConversationParticipant, err error) {
+	conversation, _, err := s.findGroupForModerator(context.Background(), personnelID, id)
+	if err != nil {
+		return result, err
+	}
+	participantIDs := uniqueIDs(in.ParticipantIDs)
+	err = s.ensurePersonnelExist(context.Background(), participantIDs)
+	if err != nil {
+		return result, err
+	}
+	participants := make([]*domain.ConversationParticipant, 0, len(participantIDs))
+	for _, pid := range participantIDs {
+		participants = append(participants, &domain.ConversationParticipant{ConversationID: id, PersonnelID: pid, Role: domain.ParticipantRoleMEMBER})
+	}
+	err = s.cr.AddParticipants(context.Background(), participants)
+	if err != nil {
+		return result, err
+	}
+	s.publishCreated(conversation, participants)
+	for _, p := range participants {
+		result = append(result, *p)
+	}
+	return result, nil
+}
+
+// RemoveParticipant implements domain.ConversationService.
+func (s *conversationServiceImpl) RemoveParticipant(personnelID, id, participantID uuid.UUID) (err error) {
+	if participantID == personnelID {
+		// participants may leave, except the owner who would leave the group without one
+		conversation, participant, err := findParticipantForConversation(context.Background(), s.cr, personnelID, id)
+		if err != nil {
+			return err
+		}
+		if conversation.Type == domain.ConversationTypeDIRECT {
+			return domain.UserError{Code: domain.ErrorCodeINVALID_CONVERSATION, Message: domain.MessageCONVERSATIONDIRECT}
+		}
+		if participant.Role == domain.ParticipantRoleOWNER {
+			return domain.UserError{Code: domain.ErrorCodeINVALID_CONVERSATION, Message: domain.MessageCONVERSATIONOWNER}
+		}
+		return s.cr.RemoveParticipant(context.Background(), id, participantID)
+	}
+	_, _, err = s.findTargetForModerator(context.Background(), personnelID, id, participantID)
+	if err != nil {
+		return err
+	}
+	return s.cr.RemoveParticipant(context.Background(), id, participantID)
+}
+
+// UpdateParticipantRole implements domain.ConversationService.
+func (s *conversationServiceImpl) UpdateParticipantRole(personnelID, id, participantID uuid.UUID, in domain.UpdateParticipantRoleInput) (result domain.ConversationParticipant, err error) {
+	moderator, result, err := s.findTargetForModerator(context.Background(), personnelID, id, participantID)
+	if err != nil {
+		return result, err
+	}
+	// nobody may hand out a role above their own, ownership is never transferred this way
+	if in.Role == domain.ParticipantRoleOWNER || in.Role.Rank() > moderator.Role.Rank() {
+		return domain.ConversationParticipant{}, domain.ForbiddenAccessError{}
+	}
+	result.Role = in.Role
+	err = s.cr.UpdateParticipant(context.Background(), &result)
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// MuteParticipant implements domain.ConversationService.
+func (s *conversationServiceImpl) MuteParticipant(personnelID, id, participantID uuid.UUID, in domain.MuteParticipantInput) (result domain.ConversationParticipant, err error) {
+	_, result, err = s.findTargetForModerator(context.Background(), personnelID, id, participantID)
+	if err != nil {
+		return result, err
+	}
+	result.MutedUntil = nil
+	if in.Duration > 0 {
+		mutedUntil := s.apu.GetExpiryTimeForDuration(in.Duration)
+		result.MutedUntil = &mutedUntil
+	}
+	err = s.cr.UpdateParticipant(context.Background(), &result)
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// CreateInvite implements domain.ConversationService.
+func (s *conversationServiceImpl) CreateInvite(personnelID, id uuid.UUID, in domain.CreateInviteInput) (result domain.ConversationInvite, err error) {
+	_, _, err = s.findGroupForModerator(context.Background(), personnelID, id)
+	if err != nil {
+		return result, err
+	}
+	expiresIn := in.ExpiresIn
+	if expiresIn == 0 {
+		expiresIn = s.cfg.ConversationInviteExpiryPeriod
+	}
+	if expiresIn <= 0 {
+		expiresIn = defaultInviteExpiryPeriod
+	}
+	result = domain.ConversationInvite{
+		ConversationID: id,
+		Token:          s.apu.GenerateUniqueToken(),
+		CreatedBy:      personnelID,
+		ExpiresAt:      s.apu.GetExpiryTimeForDuration(expiresIn),
+		MaxUses:        in.MaxUses,
+	}
+	err = s.cr.CreateInvite(context.Background(), &result)
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// FindInvites implements domain.ConversationService.
+func (s *conversationServiceImpl) FindInvites(personnelID, id uuid.UUID) (result []domain.ConversationInvite, err error) {
+	_, _, err = s.findGroupForModerator(context.Background(), personnelID, id)
+	if err != nil {
+		return result, err
+	}
+	return s.cr.FindInvitesByConversationID(context.Background(), id)
+}
+
+// RevokeInvite implements domain.ConversationService.
+func (s *conversationServiceImpl) RevokeInvite(personnelID, id, inviteID uuid.UUID) (err error) {
+	_, _, err = s.findGroupForModerator(context.Background(), personnelID, id)
+	if err != nil {
+		return err
+	}
+	invite, err := s.cr.FindInviteByID(context.Background(), inviteID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.DataNotFoundError{}
+		}
+		return err
+	}
+	if invite.ConversationID != id {
+		return domain.DataNotFoundError{}
+	}
+	return s.cr.DeleteInvite(context.Background(), inviteID)
+}
+
+// Join implements domain.ConversationService.
+func (s *conversationServiceImpl) Join(personnelID uuid.UUID, in domain.JoinConversationInput) (result domain.Conversation, err error) {
+	invalidInvite := domain.UserError{Code: domain.ErrorCodeINVALID_INVITE, Message: domain.MessageINVITEINVALID}
+	invite, err := s.cr.FindInviteByToken(context.Background(), in.Token)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, invalidInvite
+		}
+		return result, err
+	}
+	if s.apu.IsTimeExpired(invite.ExpiresAt) {
+		return result, invalidInvite
+	}
+	result, err = s.cr.FindByID(context.Background(), invite.ConversationID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, invalidInvite
+		}
+		return result, err
+	}
+	// joining twice does not use the invite up
+	_, err = s.cr.FindParticipant(context.Background(), invite.ConversationID, personnelID)
+	if err == nil {
+		return result, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return result, err
+	}
+
+	ctx := context.Background()
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+	err = s.cr.UseInvite(ctx, invite.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = invalidInvite
+		}
+		return domain.Conversation{}, err
+	}
+	participants := []*domain.ConversationParticipant{{ConversationID: invite.ConversationID, PersonnelID: personnelID, Role: domain.ParticipantRoleMEMBER}}
+	err = s.cr.AddParticipants(ctx, participants)
+	if err != nil {
+		return domain.Conversation{}, err
+	}
+	err = s.tr.Commit(ctx)
+	if err != nil {
+		return domain.Conversation{}, err
+	}
+	s.publishCreated(result, participants)
+	return result, nil
+}
+
+// create stores the conversation along with its participants
+func (s *conversationServiceImpl) create(conversation *domain.Conversation, participantIDs []uuid.UUID) (participants []*domain.ConversationParticipant, err error) {
+	ctx := context.Background()
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return participants, err
+	}
+
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+	err = s.cr.Create(ctx, conversation)
+	if err != nil {
+		return participants, err
+	}
+	for _, pid := range participantIDs {
+		role := domain.ParticipantRoleMEMBER
+		if conversation.CreatedBy != nil && *conversation.CreatedBy == pid && conversation.Type == domain.ConversationTypeGROUP {
+			role = domain.ParticipantRoleOWNER
+		}
+		participants = append(participants, &domain.ConversationParticipant{ConversationID: conversation.ID, PersonnelID: pid, Role: role})
+	}
+	err = s.cr.AddParticipants(ctx, participants)
+	if err != nil {
+		return participants, err
+	}
+	err = s.tr.Commit(ctx)
+	if err != nil {
+		return participants, err
+	}
+	return participants, nil
+}
+
+// findGroupForModerator returns the group conversation and the participant when the personnel may moderate it
+func (s *conversationServiceImpl) findGroupForModerator(ctx context.Context, personnelID, id uuid.UUID) (conversation domain.Conversation, participant domain.ConversationParticipant, err error) {
+	conversation, participant, err = findParticipantForConversation(ctx, s.cr, personnelID, id)
+	if err != nil {
+		return conversation, participant, err
+	}
+	if conversation.Type == domain.ConversationTypeDIRECT {
+		return domain.Conversation{}, domain.ConversationParticipant{}, domain.UserError{Code: domain.ErrorCodeINVALID_CONVERSATION, Message: domain.MessageCONVERSATIONDIRECT}
+	}
+	if !participant.Role.CanModerate() {
+		return domain.Conversation{}, domain.ConversationParticipant{}, domain.ForbiddenAccessError{}
+	}
+	return conversation, participant, nil
+}
+
+// findTargetForModerator returns the moderating participant and the participant they act on, which must be of a lower rank
+func (s *conversationServiceImpl) findTargetForModerator(ctx context.Context, personnelID, id, participantID uuid.UUID) (moderator, target domain.ConversationParticipant, err error) {
+	_, moderator, err = s.findGroupForModerator(ctx, personnelID, id)
+	if err != nil {
+		return moderator, target, err
+	}
+	target, err = s.cr.FindParticipant(ctx, id, participantID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ConversationParticipant{}, target, domain.DataNotFoundError{}
+		}
+		return domain.ConversationParticipant{}, target, err
+	}
+	if target.Role.Rank() >= moderator.Role.Rank() {
+		return domain.ConversationParticipant{}, domain.ConversationParticipant{}, domain.ForbiddenAccessError{}
+	}
+	return moderator, target, nil
+}
+
+// ensurePersonnelExist returns domain.DataNotFoundError when any of the personnel does not exist
+func (s *conversationServiceImpl) ensurePersonnelExist(ctx context.Context, ids []uuid.UUID) (err error) {
+	for _, id := range ids {
+		_, err = s.pr.FindByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.DataNotFoundError{}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// publishCreated lets the participants know they joined the conversation
+func (s *conversationServiceImpl) publishCreated(conversation domain.Conversation, participants []*domain.ConversationParticipant) {
+	event := domain.Event{Type: domain.EventTypeConversationCreated, Payload: conversation}
+	for _, p := range participants {
+		err := s.ep.Publish(context.Background(), p.PersonnelID, event)
+		if err != nil {
+			slog.Error("failed to publish realtime event", "type", event.Type, "recipient_id", p.PersonnelID, "err", err)
+		}
+	}
+}
+
+// findConversationForParticipant returns the conversation when the personnel participates in it
+func findConversationForParticipant(ctx context.Context, cr domain.ConversationRepository, personnelID, id uuid.UUID) (result domain.Conversation, err error) {
+	result, _, err = findParticipantForConversation(ctx, cr, personnelID, id)
+	return result, err
+}
+
+// findParticipantForConversation returns the conversation and the participant when the personnel participates in it
+func findParticipantForConversation(ctx context.Context, cr domain.ConversationRepository, personnelID, id uuid.UUID) (conversation domain.Conversation, participant domain.ConversationParticipant, err error) {
+	conversation, err = cr.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return conversation, participant, domain.DataNotFoundError{}
+		}
+		return conversation, participant, err
+	}
+	participant, err = cr.FindParticipant(ctx, id, personnelID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Conversation{}, participant, domain.ForbiddenAccessError{}
+		}
+		return domain.Conversation{}, participant, err
+	}
+	return conversation, participant, nil
+}
+
+// directKeyForPair returns the key identifying the direct conversation of two personnel regardless of order
+func directKeyForPair(a, b uuid.UUID) string {
+	if a.String() > b.String() {
+		a, b = b, a
+	}
+	return a.String() + ":" + b.String()
+}
+
+// uniqueIDs removes duplicated and nil ids keeping the original order
+func uniqueIDs(ids []uuid.UUID) (result []uuid.UUID) {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		if id.IsNil() || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
@@ -2,21 +2,39 @@ package service
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
 
 	"github.com/chatApp/internal/domain"
+	"github.com/chatApp/internal/pkg/config"
 )
 
+// searchQueryMaxLength is the longest search query accepted, in characters
+const searchQueryMaxLength = 256
+
 type MessageServiceImpl struct {
-	mr domain.MessageRepository
-	tr domain.Transactioner
+	ar  domain.AttachmentRepository
+	cfg config.ChatApiConfig
+	cr  domain.ConversationRepository
+	ep  domain.EventPublisher
+	mr  domain.MessageRepository
+	tr  domain.Transactioner
 }
 
-func NewMessageService(mr domain.MessageRepository, tr domain.Transactioner) domain.MessageService {
+func NewMessageService(ar domain.AttachmentRepository, cfg config.ChatApiConfig, cr domain.ConversationRepository, ep domain.EventPublisher, mr domain.MessageRepository, tr domain.Transactioner) domain.MessageService {
 	return &MessageServiceImpl{
-		mr: mr,
-		tr: tr,
+		ar:  ar,
+		cfg: cfg,
+		cr:  cr,
+		ep:  ep,
+		mr:  mr,
+		tr:  tr,
 	}
 
 }
@@ -27,26 +45,82 @@ func (s *MessageServiceImpl) FindAll() (result []domain.Message, err error) {
 }
 
 // FindByID implements domain.MessageService.
-func (s *MessageServiceImpl) FindByID(id uuid.UUID) (result domain.Message, err error) {
-	return s.mr.FindByID(context.Background(), id)
+func (s *MessageServiceImpl) FindByID(personnelID, id uuid.UUID) (result domain.Message, err error) {
+	result, err = s.findMessageForParticipant(personnelID, id)
+	if err != nil {
+		return result, err
+	}
+	messages := []domain.Message{result}
+	err = s.decorate(personnelID, messages)
+	if err != nil {
+		return result, err
+	}
+	return messages[0], nil
 }
 
-// FindByReceiverID implements domain.MessageService.
-func (s *MessageServiceImpl) FindByReceiverID(receiverID uuid.UUID) (result []domain.Message, err error) {
-	return s.mr.FindByReceiverID(context.Background(), receiverID)
+// FindByConversationID implements domain.MessageService.
+func (s *MessageServiceImpl) FindByConversationID(personnelID, conversationID uuid.UUID, q domain.CursorQuery) (result []domain.Message, page domain.CursorPage, err error) {
+	_, err = findConversationForParticipant(context.Background(), s.cr, personnelID, conversationID)
+	if err != nil {
+		return result, page, err
+	}
+	// one more row than asked tells whether the listing goes on
+	result, err = s.mr.FindByConversationID(context.Background(), conversationID, withLookahead(q))
+	if err != nil {
+		return result, page, err
+	}
+	result, page = messagePage(result, q)
+	err = s.decorate(personnelID, result)
+	if err != nil {
+		return result, page, err
+	}
+	return result, page, nil
 }
 
 // FindBySenderID implements domain.MessageService.
-func (s *MessageServiceImpl) FindBySenderID(senderID uuid.UUID) (result []domain.Message, err error) {
-	return s.mr.FindBySenderID(context.Background(), senderID)
+func (s *MessageServiceImpl) FindBySenderID(senderID uuid.UUID, q domain.CursorQuery) (result []domain.Message, page domain.CursorPage, err error) {
+	result, err = s.mr.FindBySenderID(context.Background(), senderID, withLookahead(q))
+	if err != nil {
+		return result, page, err
+	}
+	result, page = messagePage(result, q)
+	err = s.decorate(senderID, result)
+	if err != nil {
+		return result, page, err
+	}
+	return result, page, nil
 }
 
 // Create implements domain.MessageService.
 func (s *MessageServiceImpl) Create(in domain.CreateMessageInput) (result domain.Message, err error) {
+	// only participants may post to the conversation, as long as they are not muted
+	_, participant, err := findParticipantForConversation(context.Background(), s.cr, in.SenderID, in.ConversationID)
+	if err != nil {
+		return result, err
+	}
+	if participant.MutedUntil != nil && participant.MutedUntil.After(time.Now()) {
+		return result, domain.ForbiddenAccessError{}
+	}
 	result = domain.Message{
-		SenderID:   in.SenderID,
-		ReceiverID: in.ReceiverID,
-		Content:    in.Content,
+		SenderID:       in.SenderID,
+		ConversationID: in.ConversationID,
+		Content:        in.Content,
+	}
+	if in.ReplyToID != nil {
+		parent, err := s.findMessage(context.Background(), *in.ReplyToID)
+		if err != nil {
+			return result, err
+		}
+		if parent.ConversationID != in.ConversationID {
+			return result, domain.UserError{Code: domain.ErrorCodeINVALID_REPLY, Message: domain.MessageREPLYCONVERSATION}
+		}
+		// replies to a reply stay in the thread of its root
+		threadRootID := parent.ID
+		if parent.ThreadRootID != nil {
+			threadRootID = *parent.ThreadRootID
+		}
+		result.ReplyToID = &parent.ID
+		result.ThreadRootID = &threadRootID
 	}
 	ctx := context.Background()
 	ctx, err = s.tr.Begin(ctx)
@@ -61,59 +135,176 @@ func (s *MessageServiceImpl) Create(in domain.CreateMessageInput) (result domain
 	if err != nil {
 		return result, err
 	}
-	mStatus := domain.MessageStatus{
-		MessageID: result.ID,
-		Mstatus:   domain.MstatusSent,
-	}
-	err = s.mr.CreateMessageStatus(ctx, &mStatus)
+	// every other participant gets a receipt of their own
+	err = s.mr.CreateMessageStatusForParticipants(ctx, &result)
 	if err != nil {
 		return result, err
 	}
+	if result.ThreadRootID != nil {
+		err = s.mr.RefreshThreadSummary(ctx, *result.ThreadRootID)
+		if err != nil {
+			return result, err
+		}
+	}
+	if len(in.AttachmentIDs) > 0 {
+		attachmentIDs := uniqueIDs(in.AttachmentIDs)
+		var linked int64
+		linked, err = s.ar.LinkToMessage(ctx, &result, attachmentIDs)
+		if err != nil {
+			return result, err
+		}
+		if linked != int64(len(attachmentIDs)) {
+			err = domain.UserError{Code: domain.ErrorCodeINVALID_ATTACHMENT, Message: domain.MessageATTACHMENTUNSENT}
+			return domain.Message{}, err
+		}
+	}
 	err = s.tr.Commit(ctx)
 	if err != nil {
 		return result, err
 	}
+	if len(in.AttachmentIDs) > 0 {
+		messages := []domain.Message{result}
+		err = s.attachAttachments(messages)
+		if err != nil {
+			return result, err
+		}
+		result = messages[0]
+	}
+	// push the message to every participant, including the other devices of the sender
+	s.publish(domain.EventTypeMessageCreated, result, result.ConversationID)
+	s.publishThreadRoot(result.ThreadRootID)
 	return result, nil
 
 }
 
 // Delete implements domain.MessageService.
-func (s *MessageServiceImpl) Delete(id uuid.UUID) (err error) {
-	return s.mr.Delete(context.Background(), id)
+func (s *MessageServiceImpl) Delete(personnelID, id uuid.UUID) (err error) {
+	msg, err := s.findMessage(context.Background(), id)
+	if err != nil {
+		return err
+	}
+	if msg.SenderID != personnelID {
+		return domain.ForbiddenAccessError{}
+	}
+	if msg.ThreadRootID == nil {
+		return s.mr.Delete(context.Background(), id)
+	}
+	// the thread root no longer counts the deleted reply
+	ctx := context.Background()
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+	err = s.mr.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = s.mr.RefreshThreadSummary(ctx, *msg.ThreadRootID)
+	if err != nil {
+		return err
+	}
+	err = s.tr.Commit(ctx)
+	if err != nil {
+		return err
+	}
+	s.publishThreadRoot(msg.ThreadRootID)
+	return nil
 }
 
 // UpdateMessageStatus implements domain.MessageService.
-func (s *MessageServiceImpl) UpdateMessageStatus(in domain.UpdateMessageStatusInput) (err error) {
+func (s *MessageServiceImpl) UpdateMessageStatus(personnelID uuid.UUID, in domain.UpdateMessageStatusInput) (err error) {
+	msg, err := s.findMessageForParticipant(personnelID, in.MessageID)
+	if err != nil {
+		return err
+	}
+	if msg.SenderID == personnelID {
+		return domain.ForbiddenAccessError{}
+	}
+	if in.Mstatus == domain.MstatusRead {
+		// reading a message reads everything before it
+		return s.markRead(personnelID, msg)
+	}
 	mStatus := domain.MessageStatus{
-		Mstatus:   in.Mstatus,
-		MessageID: in.MessageID,
+		Mstatus:     in.Mstatus,
+		MessageID:   in.MessageID,
+		RecipientID: personnelID,
 	}
 
 	err = s.mr.UpdateMessageStatus(context.Background(), &mStatus)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// the status is already there or further along
+			return nil
+		}
 		return err
 	}
+	mStatus.SenderID = msg.SenderID
+	s.publishStatus([]domain.MessageStatus{mStatus})
 	return nil
 }
 
+// MarkConversationRead implements domain.MessageService.
+func (s *MessageServiceImpl) MarkConversationRead(personnelID uuid.UUID, in domain.MarkConversationReadInput) (err error) {
+	msg, err := s.findMessageForParticipant(personnelID, in.MessageID)
+	if err != nil {
+		return err
+	}
+	if msg.ConversationID != in.ConversationID {
+		return domain.DataNotFoundError{}
+	}
+	return s.markRead(personnelID, msg)
+}
+
+// FindReceipts implements domain.MessageService.
+func (s *MessageServiceImpl) FindReceipts(personnelID, id uuid.UUID) (result []domain.MessageStatus, err error) {
+	_, err = s.findMessageForParticipant(personnelID, id)
+	if err != nil {
+		return result, err
+	}
+	return s.mr.FindMessageStatusByMessageID(context.Background(), id)
+}
+
 // Update implements domain.MessageService.
-func (s *MessageServiceImpl) Update(id uuid.UUID, in domain.UpdateMessageInput) (result domain.Message, err error) {
+func (s *MessageServiceImpl) Update(personnelID, id uuid.UUID, in domain.UpdateMessageInput) (result domain.Message, err error) {
 	ctx := context.Background()
-	ctx, err = s.tr.Begin(ctx)
+	result, err = s.findMessage(ctx, id)
 	if err != nil {
 		return result, err
 	}
+	if result.SenderID != personnelID {
+		return domain.Message{}, domain.ForbiddenAccessError{}
+	}
+	if s.cfg.MessageEditWindow > 0 && time.Since(result.CreatedAt) > time.Duration(s.cfg.MessageEditWindow)*time.Minute {
+		return domain.Message{}, domain.UserError{Code: domain.ErrorCodeEDIT_WINDOW_EXPIRED, Message: domain.MessageEDITWINDOWEXPIRED}
+	}
+	// nothing to change, so no transaction is opened
+	if in.Content == "" || in.Content == result.Content {
+		return result, nil
+	}
 
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
 	defer func() {
 		s.tr.Rollback(ctx, err)
 	}()
-	result, err = s.mr.FindByID(ctx, id)
+	// keep the version being replaced
+	edit := domain.MessageEdit{
+		MessageID: result.ID,
+		Content:   result.Content,
+		EditedBy:  personnelID,
+	}
+	err = s.mr.CreateMessageEdit(ctx, &edit)
 	if err != nil {
 		return result, err
 	}
-	if in.Content != "" {
-		result.Content = in.Content
-	}
+	result.Content = in.Content
+	result.EditedAt = &edit.CreatedAt
 	err = s.mr.Update(ctx, &result)
 	if err != nil {
 		return result, err
@@ -122,6 +313,301 @@ func (s *MessageServiceImpl) Update(id uuid.UUID, in domain.UpdateMessageInput)
 	if err != nil {
 		return result, err
 	}
+	s.publish(domain.EventTypeMessageUpdated, result, result.ConversationID)
+	return result, nil
+
+}
+
+// FindReplies implements domain.MessageService.
+func (s *MessageServiceImpl) FindReplies(personnelID, id uuid.UUID, q domain.CursorQuery) (result []domain.Message, page domain.CursorPage, err error) {
+	msg, err := s.findMessageForParticipant(personnelID, id)
+	if err != nil {
+		return result, page, err
+	}
+	threadRootID := msg.ID
+	if msg.ThreadRootID != nil {
+		threadRootID = *msg.ThreadRootID
+	}
+	result, err = s.mr.FindByThreadRootID(context.Background(), threadRootID, withLookahead(q))
+	if err != nil {
+		return result, page, err
+	}
+	result, page = messagePage(result, q)
+	err = s.decorate(personnelID, result)
+	if err != nil {
+		return result, page, err
+	}
+	return result, page, nil
+}
+
+// AddReaction implements domain.MessageService.
+func (s *MessageServiceImpl) AddReaction(personnelID, id uuid.UUID, in domain.AddReactionInput) (result []domain.ReactionSummary, err error) {
+	msg, err := s.findMessageForParticipant(personnelID, id)
+	if err != nil {
+		return result, err
+	}
+	reaction := domain.MessageReaction{
+		MessageID:   id,
+		PersonnelID: personnelID,
+		Emoji:       in.Emoji,
+	}
+	err = s.mr.AddReaction(context.Background(), &reaction)
+	if err == nil {
+		s.publish(domain.EventTypeReactionAdded, reaction, msg.ConversationID)
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return result, err
+	}
+	// reacting twice with the same emoji leaves the reactions as they are
+	return s.findReactions(personnelID, id)
+}
+
+// RemoveReaction implements domain.MessageService.
+func (s *MessageServiceImpl) RemoveReaction(personnelID, id uuid.UUID, emoji string) (result []domain.ReactionSummary, err error) {
+	msg, err := s.findMessageForParticipant(personnelID, id)
+	if err != nil {
+		return result, err
+	}
+	reaction, err := s.mr.RemoveReaction(context.Background(), id, personnelID, emoji)
+	if err == nil {
+		s.publish(domain.EventTypeReactionRemoved, reaction, msg.ConversationID)
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return result, err
+	}
+	return s.findReactions(personnelID, id)
+}
+
+// FindReactions implements domain.MessageService.
+func (s *MessageServiceImpl) FindReactions(personnelID, id uuid.UUID) (result []domain.ReactionSummary, err error) {
+	_, err = s.findMessageForParticipant(personnelID, id)
+	if err != nil {
+		return result, err
+	}
+	return s.findReactions(personnelID, id)
+}
+
+// FindEdits implements domain.MessageService.
+func (s *MessageServiceImpl) FindEdits(personnelID, id uuid.UUID) (result []domain.MessageEdit, err error) {
+	_, err = s.findMessageForParticipant(personnelID, id)
+	if err != nil {
+		return result, err
+	}
+	return s.mr.FindEditsByMessageID(context.Background(), id)
+}
+
+// Search implements domain.MessageService.
+func (s *MessageServiceImpl) Search(personnelID uuid.UUID, in domain.SearchMessagesInput) (result []domain.MessageSearchResult, next *domain.SearchCursor, err error) {
+	in.Query = strings.TrimSpace(in.Query)
+	if in.Query == "" || utf8.RuneCountInString(in.Query) > searchQueryMaxLength {
+		return result, nil, domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: domain.MessageSEARCHQUERY}
+	}
+	limit := in.Limit
+	// one more row tells whether there is a next page
+	in.Limit++
+	result, err = s.mr.Search(context.Background(), personnelID, in)
+	if err != nil {
+		return result, nil, err
+	}
+	if int64(len(result)) > limit {
+		result = result[:limit]
+		last := result[len(result)-1]
+		next = &domain.SearchCursor{Rank: last.Rank, ID: last.ID}
+	}
+	return result, next, nil
+}
+
+// findMessageForParticipant returns the message when the personnel participates in its conversation
+func (s *MessageServiceImpl) findMessageForParticipant(personnelID, id uuid.UUID) (result domain.Message, err error) {
+	result, err = s.findMessage(context.Background(), id)
+	if err != nil {
+		return result, err
+	}
+	_, err = findConversationForParticipant(context.Background(), s.cr, personnelID, result.ConversationID)
+	if err != nil {
+		return domain.Message{}, err
+	}
+	return result, nil
+}
+
+// findReactions returns the reactions to a message as seen by the personnel
+func (s *MessageServiceImpl) findReactions(personnelID, id uuid.UUID) (result []domain.ReactionSummary, err error) {
+	result, err = s.mr.FindReactionSummaries(context.Background(), []uuid.UUID{id}, personnelID)
+	if err != nil {
+		return result, err
+	}
+	if result == nil {
+		result = []domain.ReactionSummary{}
+	}
+	return result, nil
+}
+
+// decorate fills in the reactions and attachments of the listed messages
+func (s *MessageServiceImpl) decorate(personnelID uuid.UUID, messages []domain.Message) (err error) {
+	err = s.attachReactions(personnelID, messages)
+	if err != nil {
+		return err
+	}
+	return s.attachAttachments(messages)
+}
+
+// attachAttachments fills in the attachments of the messages with a single query
+func (s *MessageServiceImpl) attachAttachments(messages []domain.Message) (err error) {
+	if len(messages) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(messages))
+	for _, m := range messages {
+		ids = append(ids, m.ID)
+	}
+	attachments, err := s.ar.FindByMessageIDs(context.Background(), ids)
+	if err != nil {
+		return err
+	}
+	byMessage := make(map[uuid.UUID][]domain.Attachment, len(messages))
+	for _, a := range attachments {
+		byMessage[*a.MessageID] = append(byMessage[*a.MessageID], a)
+	}
+	for idx := range messages {
+		messages[idx].Attachments = byMessage[messages[idx].ID]
+	}
+	return nil
+}
+
+// attachReactions fills in the reactions of the messages as seen by the personnel with a single query
+func (s *MessageServiceImpl) attachReactions(personnelID uuid.UUID, messages []domain.Message) (err error) {
+	if len(messages) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(messages))
+	for _, m := range messages {
+		ids = append(ids, m.ID)
+	}
+	summaries, err := s.mr.FindReactionSummaries(context.Background(), ids, personnelID)
+	if err != nil {
+		return err
+	}
+	byMessage := make(map[uuid.UUID][]domain.ReactionSummary, len(messages))
+	for _, rs := range summaries {
+		byMessage[rs.MessageID] = append(byMessage[rs.MessageID], rs)
+	}
+	for idx := range messages {
+		messages[idx].Reactions = byMessage[messages[idx].ID]
+	}
+	return nil
+}
+
+// findMessage returns the message by id, translating a missing row into domain.DataNotFoundError
+func (s *MessageServiceImpl) findMessage(ctx context.Context, id uuid.UUID) (result domain.Message, err error) {
+	result, err = s.mr.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, domain.DataNotFoundError{}
+		}
+		return result, err
+	}
 	return result, nil
+}
 
+// markRead marks the messages of the conversation up to msg as read by the personnel and lets their senders know
+func (s *MessageServiceImpl) markRead(personnelID uuid.UUID, msg domain.Message) (err error) {
+	ctx := context.Background()
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+	statuses, err := s.mr.MarkConversationRead(ctx, msg.ConversationID, personnelID, msg.CreatedAt)
+	if err != nil {
+		return err
+	}
+	err = s.cr.UpdateLastReadAt(ctx, msg.ConversationID, personnelID, msg.CreatedAt)
+	if err != nil {
+		return err
+	}
+	err = s.tr.Commit(ctx)
+	if err != nil {
+		return err
+	}
+	s.publishStatus(statuses)
+	return nil
+}
+
+// publishStatus pushes the changed statuses to the senders of the messages, grouped per sender
+func (s *MessageServiceImpl) publishStatus(statuses []domain.MessageStatus) {
+	bySender := make(map[uuid.UUID][]domain.MessageStatus)
+	for _, st := range statuses {
+		bySender[st.SenderID] = append(bySender[st.SenderID], st)
+	}
+	for senderID, senderStatuses := range bySender {
+		event := domain.Event{Type: domain.EventTypeMessageStatusUpdated, Payload: senderStatuses}
+		err := s.ep.Publish(context.Background(), senderID, event)
+		if err != nil {
+			slog.Error("failed to publish realtime event", "type", event.Type, "recipient_id", senderID, "err", err)
+		}
+	}
+}
+
+// publishThreadRoot pushes the refreshed reply count of a thread root to the participants
+func (s *MessageServiceImpl) publishThreadRoot(threadRootID *uuid.UUID) {
+	if threadRootID == nil {
+		return
+	}
+	root, err := s.mr.FindByID(context.Background(), *threadRootID)
+	if err != nil {
+		slog.Error("failed to load thread root for realtime event", "thread_root_id", *threadRootID, "err", err)
+		return
+	}
+	s.publish(domain.EventTypeMessageUpdated, root, root.ConversationID)
+}
+
+// publish pushes the event to the participants of the conversation, the write it reports on is already committed so failures are only logged
+func (s *MessageServiceImpl) publish(eventType domain.EventType, payload interface{}, conversationID uuid.UUID) {
+	participants, err := s.cr.FindParticipants(context.Background(), conversationID)
+	if err != nil {
+		slog.Error("failed to load participants for realtime event", "type", eventType, "conversation_id", conversationID, "err", err)
+		return
+	}
+	event := domain.Event{Type: eventType, Payload: payload}
+	for _, p := range participants {
+		err = s.ep.Publish(context.Background(), p.PersonnelID, event)
+		if err != nil {
+			slog.Error("failed to publish realtime event", "type", eventType, "recipient_id", p.PersonnelID, "err", err)
+		}
+	}
+}
+
+// withLookahead asks for one more row than the page holds
+func withLookahead(q domain.CursorQuery) domain.CursorQuery {
+	q.Limit++
+	return q
+}
+
+// messagePage trims the lookahead row off a page fetched with withLookahead and works out its cursors
+func messagePage(rows []domain.Message, q domain.CursorQuery) (result []domain.Message, page domain.CursorPage) {
+	more := int64(len(rows)) > q.Limit
+	result = rows
+	if more {
+		// the lookahead row is the one furthest from the cursor
+		if q.After != nil {
+			result = rows[:q.Limit]
+		} else {
+			result = rows[1:]
+		}
+	}
+	if len(result) == 0 {
+		return result, page
+	}
+	first := domain.Cursor{CreatedAt: result[0].CreatedAt, ID: result[0].ID}
+	last := domain.Cursor{CreatedAt: result[len(result)-1].CreatedAt, ID: result[len(result)-1].ID}
+	// older rows exist when paging forward from a cursor or when the lookahead found one
+	if q.After != nil || more {
+		page.Prev = &first
+	}
+	// newer rows exist when paging back from a cursor or when the lookahead found one
+	if q.Before != nil || (q.After != nil && more) {
+		page.Next = &last
+	}
+	return result, page
 }
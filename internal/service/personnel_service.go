@@ -1,19 +1,44 @@
 package service
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
 
 	"github.com/chatApp/internal/domain"
+	"github.com/chatApp/internal/pkg/blobstore"
+	"github.com/chatApp/internal/pkg/config"
+	"github.com/chatApp/internal/pkg/imaging"
 )
 
+// defaultAvatarMaxSize is the largest avatar image accepted in megabytes when none is configured
+const defaultAvatarMaxSize = 5
+
+// defaultAvatarSizes are the square sizes, in pixels, avatars are stored in when none are configured
+var defaultAvatarSizes = []int{64, 128, 256, 512}
+
 type personnelServiceImpl struct {
-	pr domain.PersonnelRepository
+	bs  blobstore.BlobStore
+	cfg config.ChatApiConfig
+	pr  domain.PersonnelRepository
 }
 
-func NewPersonnelService(pr domain.PersonnelRepository) domain.PersonnelService {
-	return &personnelServiceImpl{pr: pr}
+func NewPersonnelService(bs blobstore.BlobStore, cfg config.ChatApiConfig, pr domain.PersonnelRepository) domain.PersonnelService {
+	return &personnelServiceImpl{
+		bs:  bs,
+		cfg: cfg,
+		pr:  pr,
+	}
 }
 
 // Filter implements domain.PersonnelService.
@@ -39,7 +64,6 @@ func (s *personnelServiceImpl) Create(in domain.CreatePersonnelInput) (result do
 		Email:            &in.Email,
 		Mobile:           in.Mobile,
 		Role:             in.Role,
-		Avatar:           &in.Avatar,
 		Address:          in.Address,
 		ActivationStatus: domain.ActivationStatusACTIVE,
 	}
@@ -72,9 +96,6 @@ func (s *personnelServiceImpl) Update(id uuid.UUID, in domain.UpdatePersonnelInp
 	if in.Role != "" {
 		result.Role = in.Role
 	}
-	if in.Avatar != "" {
-		result.Avatar = &in.Avatar
-	}
 	if in.Address.City != "" {
 		result.Address.City = in.Address.City
 	}
@@ -105,3 +126,158 @@ func (s *personnelServiceImpl) Update(id uuid.UUID, in domain.UpdatePersonnelInp
 func (s *personnelServiceImpl) Delete(id uuid.UUID) (err error) {
 	return s.pr.Delete(context.Background(), id)
 }
+
+// UpdateAvatar implements domain.PersonnelService.
+func (s *personnelServiceImpl) UpdateAvatar(id uuid.UUID, in domain.UploadAvatarInput) (result domain.Personnel, err error) {
+	result, err = s.findForAvatarChange(id)
+	if err != nil {
+		return result, err
+	}
+	maxSize := int64(s.cfg.AvatarMaxSize) << 20
+	if maxSize <= 0 {
+		maxSize = defaultAvatarMaxSize << 20
+	}
+	if in.Size > maxSize {
+		return result, domain.UserError{Code: domain.ErrorCodeINVALID_AVATAR, Message: domain.MessageAVATARTOOLARGE}
+	}
+	data, err := io.ReadAll(io.LimitReader(in.Content, maxSize+1))
+	if err != nil {
+		return result, err
+	}
+	if int64(len(data)) > maxSize {
+		return result, domain.UserError{Code: domain.ErrorCodeINVALID_AVATAR, Message: domain.MessageAVATARTOOLARGE}
+	}
+	if !imaging.Supported(detectContentType(data, "")) {
+		return result, domain.UserError{Code: domain.ErrorCodeINVALID_AVATAR, Message: domain.MessageAVATARTYPE}
+	}
+	pic, err := imaging.Decode(data)
+	if err != nil {
+		// corrupt and oversized images alike
+		return result, domain.UserError{Code: domain.ErrorCodeINVALID_AVATAR, Message: domain.MessageAVATARTYPE}
+	}
+
+	// every upload gets a new version so the URL changes with the image and can be cached for good
+	version := uuid.Must(uuid.NewV4()).String()
+	key := avatarKey(id, version)
+	for _, size := range s.avatarSizes() {
+		content, contentType, err := imaging.Encode(pic.Square(size))
+		if err == nil {
+			err = s.bs.Put(context.Background(), key+"_"+strconv.Itoa(size), bytes.NewReader(content), int64(len(content)), contentType)
+		}
+		if err != nil {
+			s.removeAvatar(key)
+			return result, err
+		}
+	}
+
+	previousKey := result.AvatarKey
+	url := strings.TrimSuffix(s.cfg.PublicBaseUrl, "/") + "/api/v1/avatars/" + id.String() + "/" + version
+	result.Avatar = &url
+	result.AvatarKey = &key
+	err = s.pr.UpdateAvatar(context.Background(), &result)
+	if err != nil {
+		s.removeAvatar(key)
+		return result, err
+	}
+	if previousKey != nil {
+		s.removeAvatar(*previousKey)
+	}
+	return result, nil
+}
+
+// DeleteAvatar implements domain.PersonnelService.
+func (s *personnelServiceImpl) DeleteAvatar(id uuid.UUID) (result domain.Personnel, err error) {
+	result, err = s.findForAvatarChange(id)
+	if err != nil {
+		return result, err
+	}
+	previousKey := result.AvatarKey
+	result.Avatar = nil
+	result.AvatarKey = nil
+	err = s.pr.UpdateAvatar(context.Background(), &result)
+	if err != nil {
+		return result, err
+	}
+	if previousKey != nil {
+		s.removeAvatar(*previousKey)
+	}
+	return result, nil
+}
+
+// FindAvatar implements domain.PersonnelService.
+func (s *personnelServiceImpl) FindAvatar(id uuid.UUID, version string, size int) (content io.ReadCloser, contentType string, err error) {
+	personnel, err := s.pr.FindByID(context.Background(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, "", domain.DataNotFoundError{}
+		}
+		return nil, "", err
+	}
+	// only the current version is served
+	key := avatarKey(id, version)
+	if personnel.AvatarKey == nil || *personnel.AvatarKey != key {
+		return nil, "", domain.DataNotFoundError{}
+	}
+	sizes := s.avatarSizes()
+	i := len(sizes) - 1
+	if size > 0 {
+		if j := slices.IndexFunc(sizes, func(s int) bool { return s >= size }); j >= 0 {
+			i = j
+		}
+	}
+	rc, err := s.bs.Get(context.Background(), key+"_"+strconv.Itoa(sizes[i]))
+	if err != nil {
+		if errors.Is(err, blobstore.ErrNotFound) {
+			return nil, "", domain.DataNotFoundError{}
+		}
+		return nil, "", err
+	}
+	// avatars are stored as JPEG or PNG, tell them apart from their first bytes
+	br := bufio.NewReader(rc)
+	head, _ := br.Peek(512)
+	return struct {
+		io.Reader
+		io.Closer
+	}{br, rc}, http.DetectContentType(head), nil
+}
+
+// findForAvatarChange returns the personnel whose avatar is changed
+func (s *personnelServiceImpl) findForAvatarChange(id uuid.UUID) (result domain.Personnel, err error) {
+	result, err = s.pr.FindByID(context.Background(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, domain.DataNotFoundError{}
+		}
+		return result, err
+	}
+	return result, nil
+}
+
+// avatarSizes returns the configured avatar sizes, smallest first
+func (s *personnelServiceImpl) avatarSizes() []int {
+	sizes := slices.Clone(defaultAvatarSizes)
+	if len(s.cfg.AvatarSizes) > 0 {
+		sizes = slices.Clone(s.cfg.AvatarSizes)
+	}
+	sizes = slices.DeleteFunc(sizes, func(size int) bool { return size <= 0 })
+	if len(sizes) == 0 {
+		return slices.Clone(defaultAvatarSizes)
+	}
+	slices.Sort(sizes)
+	return slices.Compact(sizes)
+}
+
+// removeAvatar removes every size of an avatar from the blob store, failures only leave unreachable blobs behind
+func (s *personnelServiceImpl) removeAvatar(key string) {
+	for _, size := range s.avatarSizes() {
+		err := s.bs.Delete(context.Background(), key+"_"+strconv.Itoa(size))
+		if err != nil {
+			slog.Error("failed to remove avatar", "key", key, "size", size, "err", err)
+		}
+	}
+}
+
+// avatarKey returns the prefix of the keys the sizes of a version of an avatar are stored under
+func avatarKey(id uuid.UUID, version string) string {
+	return "avatars/" + id.String() + "/" + version
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/chatApp/internal/domain"
+)
+
+const (
+	// presenceTimeout is how long a connection counts without a heartbeat, it outlasts the websocket ping period so
+	// connections kept alive by pongs alone stay fresh
+	presenceTimeout = 2 * time.Minute
+	// presenceSweepInterval is how often the connections of instances that went away are removed
+	presenceSweepInterval = time.Minute
+)
+
+type presenceServiceImpl struct {
+	cr  domain.ConversationRepository
+	ep  domain.EventPublisher
+	prr domain.PresenceRepository
+}
+
+// NewPresenceService creates the presence service and starts sweeping stale connections in the background
+func NewPresenceService(cr domain.ConversationRepository, ep domain.EventPublisher, prr domain.PresenceRepository) domain.PresenceService {
+	s := &presenceServiceImpl{
+		cr:  cr,
+		ep:  ep,
+		prr: prr,
+	}
+	go s.sweep()
+	return s
+}
+
+// Connect implements domain.PresenceService.
+func (s *presenceServiceImpl) Connect(personnelID, connectionID uuid.UUID) (err error) {
+	return s.change(personnelID, func(ctx context.Context) error {
+		return s.saveConnection(ctx, personnelID, connectionID, domain.PresenceStatusONLINE)
+	})
+}
+
+// Heartbeat implements domain.PresenceService.
+func (s *presenceServiceImpl) Heartbeat(personnelID, connectionID uuid.UUID, status domain.PresenceStatus) (err error) {
+	err = s.prr.TouchConnection(context.Background(), connectionID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// the connection was swept while the database was out of reach, bring it back
+		return s.change(personnelID, func(ctx context.Context) error {
+			return s.saveConnection(ctx, personnelID, connectionID, status)
+		})
+	}
+	return err
+}
+
+// SetStatus implements domain.PresenceService.
+func (s *presenceServiceImpl) SetStatus(personnelID, connectionID uuid.UUID, status domain.PresenceStatus) (err error) {
+	if status != domain.PresenceStatusONLINE && status != domain.PresenceStatusAWAY {
+		return domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: domain.MessagePRESENCESTATUS}
+	}
+	return s.change(personnelID, func(ctx context.Context) error {
+		return s.saveConnection(ctx, personnelID, connectionID, status)
+	})
+}
+
+// Disconnect implements domain.PresenceService.
+func (s *presenceServiceImpl) Disconnect(personnelID, connectionID uuid.UUID) (err error) {
+	return s.change(personnelID, func(ctx context.Context) error {
+		_, err := s.prr.DeleteConnection(ctx, connectionID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		return s.prr.UpdateLastSeen(ctx, personnelID, time.Now())
+	})
+}
+
+// FindByUserID implements domain.PresenceService.
+func (s *presenceServiceImpl) FindByUserID(userID uuid.UUID) (result domain.Presence, err error) {
+	presences, err := s.prr.FindByUserIDs(context.Background(), []uuid.UUID{userID}, time.Now().Add(-presenceTimeout))
+	if err != nil {
+		return result, err
+	}
+	if len(presences) == 0 {
+		return result, domain.DataNotFoundError{}
+	}
+	return presences[0], nil
+}
+
+// FindByUserIDs implements domain.PresenceService.
+func (s *presenceServiceImpl) FindByUserIDs(in domain.FindPresenceInput) (result []domain.Presence, err error) {
+	return s.prr.FindByUserIDs(context.Background(), in.UserIDs, time.Now().Add(-presenceTimeout))
+}
+
+// saveConnection creates or refreshes a connection with the status
+func (s *presenceServiceImpl) saveConnection(ctx context.Context, personnelID, connectionID uuid.UUID, status domain.PresenceStatus) error {
+	return s.prr.SaveConnection(ctx, &domain.PresenceConnection{
+		Base:        domain.Base{ID: connectionID},
+		PersonnelID: personnelID,
+		Status:      status,
+	})
+}
+
+// change applies fn and tells the contacts of the personnel when it changed their presence
+func (s *presenceServiceImpl) change(personnelID uuid.UUID, fn func(ctx context.Context) error) (err error) {
+	ctx := context.Background()
+	before, err := s.find(ctx, personnelID)
+	if err != nil {
+		return err
+	}
+	err = fn(ctx)
+	if err != nil {
+		return err
+	}
+	after, err := s.find(ctx, personnelID)
+	if err != nil {
+		return err
+	}
+	if after.Status != before.Status {
+		s.publish(ctx, after)
+	}
+	return nil
+}
+
+// find returns the presence of a personnel
+func (s *presenceServiceImpl) find(ctx context.Context, personnelID uuid.UUID) (result domain.Presence, err error) {
+	presences, err := s.prr.FindByPersonnelIDs(ctx, []uuid.UUID{personnelID}, time.Now().Add(-presenceTimeout))
+	if err != nil {
+		return result, err
+	}
+	if len(presences) == 0 {
+		return result, domain.DataNotFoundError{}
+	}
+	return presences[0], nil
+}
+
+// publish sends the presence of a personnel to everyone sharing a conversation with them
+func (s *presenceServiceImpl) publish(ctx context.Context, presence domain.Presence) {
+	contactIDs, err := s.cr.FindContactIDs(ctx, presence.PersonnelID)
+	if err != nil {
+		slog.Error("failed to find contacts for presence", "personnel_id", presence.PersonnelID, "err", err)
+		return
+	}
+	event := domain.Event{Type: domain.EventTypePresenceUpdated, Payload: presence}
+	for _, id := range contactIDs {
+		err := s.ep.Publish(ctx, id, event)
+		if err != nil {
+			slog.Error("failed to publish presence", "personnel_id", presence.PersonnelID, "recipient_id", id, "err", err)
+		}
+	}
+}
+
+// sweep periodically removes the connections left behind by instances that went away without closing them.
+// Their personnel went offline silently when the heartbeats stopped, so the contacts are told now.
+func (s *presenceServiceImpl) sweep() {
+	ticker := time.NewTicker(presenceSweepInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		ctx := context.Background()
+		stale, err := s.prr.DeleteStaleConnections(ctx, time.Now().Add(-presenceTimeout))
+		if err != nil {
+			slog.Error("failed to sweep stale presence connections", "err", err)
+			continue
+		}
+		lastSeen := make(map[uuid.UUID]time.Time)
+		for _, c := range stale {
+			if c.HeartbeatAt.After(lastSeen[c.PersonnelID]) {
+				lastSeen[c.PersonnelID] = c.HeartbeatAt
+			}
+		}
+		for personnelID, at := range lastSeen {
+			err := s.prr.UpdateLastSeen(ctx, personnelID, at)
+			if err != nil {
+				slog.Error("failed to update last seen", "personnel_id", personnelID, "err", err)
+				continue
+			}
+			presence, err := s.find(ctx, personnelID)
+			if err != nil {
+				continue
+			}
+			s.publish(ctx, presence)
+		}
+	}
+}
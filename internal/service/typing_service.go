@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/chatApp/internal/domain"
+)
+
+const (
+	// typingThrottle is the least time between two typing.started events of a personnel in a conversation,
+	// refreshes in between only push the expiry back
+	typingThrottle = 3 * time.Second
+	// typingTimeout is how long typing lasts without a refresh
+	typingTimeout = 6 * time.Second
+)
+
+// typingKey identifies a personnel typing in a conversation
+type typingKey struct {
+	personnelID    uuid.UUID
+	conversationID uuid.UUID
+}
+
+// typingState is the typing of a personnel in a conversation
+type typingState struct {
+	timer    *time.Timer
+	lastSent time.Time
+}
+
+type typingServiceImpl struct {
+	cr domain.ConversationRepository
+	ep domain.EventPublisher
+
+	mu     sync.Mutex
+	typing map[typingKey]*typingState
+}
+
+func NewTypingService(cr domain.ConversationRepository, ep domain.EventPublisher) domain.TypingService {
+	return &typingServiceImpl{
+		cr:     cr,
+		ep:     ep,
+		typing: make(map[typingKey]*typingState),
+	}
+}
+
+// Start implements domain.TypingService.
+func (s *typingServiceImpl) Start(personnelID, conversationID uuid.UUID) (err error) {
+	key := typingKey{personnelID: personnelID, conversationID: conversationID}
+	s.mu.Lock()
+	if st, ok := s.typing[key]; ok {
+		st.timer.Reset(typingTimeout)
+		if time.Since(st.lastSent) < typingThrottle {
+			s.mu.Unlock()
+			return nil
+		}
+		st.lastSent = time.Now()
+		s.mu.Unlock()
+		return s.publish(key, domain.EventTypeTypingStarted)
+	}
+	s.mu.Unlock()
+
+	// membership is only checked when typing starts, a refresh relies on the state left by the first check
+	err = s.publish(key, domain.EventTypeTypingStarted)
+	if err != nil {
+		return err
+	}
+	st := &typingState{lastSent: time.Now()}
+	st.timer = time.AfterFunc(typingTimeout, func() { s.expire(key, st) })
+	s.mu.Lock()
+	if prev, ok := s.typing[key]; ok {
+		// a concurrent start from another device of the personnel won the race
+		prev.timer.Reset(typingTimeout)
+		st.timer.Stop()
+	} else {
+		s.typing[key] = st
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// Stop implements domain.TypingService.
+func (s *typingServiceImpl) Stop(personnelID, conversationID uuid.UUID) (err error) {
+	key := typingKey{personnelID: personnelID, conversationID: conversationID}
+	s.mu.Lock()
+	st, ok := s.typing[key]
+	if ok {
+		st.timer.Stop()
+		delete(s.typing, key)
+	}
+	s.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return s.publish(key, domain.EventTypeTypingStopped)
+}
+
+// expire stops typing that was not refreshed in time
+func (s *typingServiceImpl) expire(key typingKey, st *typingState) {
+	s.mu.Lock()
+	if s.typing[key] != st {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.typing, key)
+	s.mu.Unlock()
+	err := s.publish(key, domain.EventTypeTypingStopped)
+	if err != nil {
+		slog.Error("failed to publish typing expiry", "personnel_id", key.personnelID, "conversation_id", key.conversationID, "err", err)
+	}
+}
+
+// publish sends a typing event to the other participants of the conversation, it fails with
+// domain.ForbiddenAccessError when the personnel does not participate in it
+func (s *typingServiceImpl) publish(key typingKey, eventType domain.EventType) (err error) {
+	ctx := context.Background()
+	participants, err := s.cr.FindParticipants(ctx, key.conversationID)
+	if err != nil {
+		return err
+	}
+	member := false
+	for _, p := range participants {
+		if p.PersonnelID == key.personnelID {
+			member = true
+			break
+		}
+	}
+	if !member {
+		return domain.ForbiddenAccessError{}
+	}
+	event := domain.Event{Type: eventType, Payload: domain.Typing{ConversationID: key.conversationID, PersonnelID: key.personnelID}}
+	for _, p := range participants {
+		if p.PersonnelID == key.personnelID {
+			continue
+		}
+		err := s.ep.Publish(ctx, p.PersonnelID, event)
+		if err != nil {
+			slog.Error("failed to publish typing", "conversation_id", key.conversationID, "recipient_id", p.PersonnelID, "err", err)
+		}
+	}
+	return nil
+}
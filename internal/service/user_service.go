@@ -3,38 +3,123 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gofrs/uuid/v5"
 	"github.com/jackc/pgx/v5"
 
 	"github.com/chatApp/internal/domain"
 	"github.com/chatApp/internal/pkg/config"
+	"github.com/chatApp/internal/pkg/notifier"
 	"github.com/chatApp/internal/pkg/security"
+	"github.com/chatApp/internal/pkg/sms"
+	"github.com/chatApp/internal/pkg/totp"
 	"github.com/chatApp/internal/pkg/util"
 )
 
+const (
+	// tokenSweepInterval is how often expired sessions, tokens, denylist entries, one time passwords, reset tokens,
+	// failed logins and login challenges are removed
+	tokenSweepInterval = time.Hour
+
+	// defaultOTPLength is the number of digits of one time passwords when OTP_LENGTH is not set
+	defaultOTPLength = 6
+	// defaultOTPExpiryPeriod is the lifetime of one time passwords in minutes when OTP_EXPIRY_PERIOD is not set
+	defaultOTPExpiryPeriod = 5
+	// defaultOTPMaxAttempts is the number of guesses of a one time password when OTP_MAX_ATTEMPTS is not set
+	defaultOTPMaxAttempts = 5
+	// defaultOTPResendCooldown is the number of seconds between two codes when OTP_RESEND_COOLDOWN is not set
+	defaultOTPResendCooldown = 60
+	// defaultPasswordResetExpiryPeriod is the lifetime of reset tokens in minutes when PASSWORD_RESET_EXPIRY_PERIOD is not set
+	defaultPasswordResetExpiryPeriod = 30
+
+	// defaultLoginMaxAttempts is the number of failed logins of a user name that lock it out when LOGIN_MAX_ATTEMPTS is not set
+	defaultLoginMaxAttempts = 5
+	// defaultLoginIPMaxAttempts is the number of failed logins from an address that lock it out when LOGIN_IP_MAX_ATTEMPTS is not set
+	defaultLoginIPMaxAttempts = 20
+	// defaultLoginLockoutPeriod is the length of lockouts in minutes when LOGIN_LOCKOUT_PERIOD is not set
+	defaultLoginLockoutPeriod = 15
+	// loginBaseDelay is the wait after the first failed login, it doubles with every further failure
+	loginBaseDelay = time.Second
+	// loginMaxDelay caps the wait between failed logins short of a lockout
+	loginMaxDelay = 30 * time.Second
+
+	// defaultMFAChallengeExpiryPeriod is the lifetime of login challenges in minutes when MFA_CHALLENGE_EXPIRY_PERIOD is not set
+	defaultMFAChallengeExpiryPeriod = 5
+	// defaultMFAMaxAttempts is the number of codes a login challenge takes when MFA_MAX_ATTEMPTS is not set
+	defaultMFAMaxAttempts = 5
+	// recoveryCodeCount is the number of recovery codes given when an authenticator is confirmed
+	recoveryCodeCount = 10
+)
+
 type UserServiceImpl struct {
-	apu util.AppUtil
-	cfg config.ChatApiConfig
-	pr  domain.PersonnelRepository
-	tr  domain.Transactioner
-	scm security.Manager
-	ur  domain.UserRepository
+	adr  domain.AuditRepository
+	apu  util.AppUtil
+	cfg  config.ChatApiConfig
+	lar  domain.LoginAttemptRepository
+	mfr  domain.MFARepository
+	ntf  notifier.Notifier
+	otr  domain.OTPRepository
+	phr  domain.PasswordHistoryRepository
+	pp   security.PasswordPolicy
+	pr   domain.PersonnelRepository
+	prt  domain.PasswordResetRepository
+	sc   domain.SessionCloser
+	tr   domain.Transactioner
+	scm  security.Manager
+	sesr domain.SessionRepository
+	sms  sms.SMSSender
+	tkr  domain.TokenRepository
+	tot  totp.Authenticator
+	ur   domain.UserRepository
+
+	// dummyHash is checked against when the user of a login does not exist, so that it takes as long
+	dummyHash     string
+	dummyHashOnce sync.Once
 }
 
-func NewUserService(apu util.AppUtil, cfg config.ChatApiConfig, pr domain.PersonnelRepository, smc security.Manager, tr domain.Transactioner, ur domain.UserRepository) domain.UserService {
-	return &UserServiceImpl{
-		cfg: cfg,
-		apu: apu,
-		pr:  pr,
-		ur:  ur,
-		scm: smc,
-		tr:  tr,
+// NewUserService creates the user service and starts removing expired tokens in the background
+func NewUserService(adr domain.AuditRepository, apu util.AppUtil, cfg config.ChatApiConfig, lar domain.LoginAttemptRepository, mfr domain.MFARepository, ntf notifier.Notifier, otr domain.OTPRepository, phr domain.PasswordHistoryRepository, pp security.PasswordPolicy, pr domain.PersonnelRepository, prt domain.PasswordResetRepository, sc domain.SessionCloser, sesr domain.SessionRepository, smc security.Manager, sender sms.SMSSender, tkr domain.TokenRepository, tot totp.Authenticator, tr domain.Transactioner, ur domain.UserRepository) domain.UserService {
+	s := &UserServiceImpl{
+		adr:  adr,
+		cfg:  cfg,
+		apu:  apu,
+		lar:  lar,
+		mfr:  mfr,
+		ntf:  ntf,
+		otr:  otr,
+		phr:  phr,
+		pp:   pp,
+		pr:   pr,
+		prt:  prt,
+		sc:   sc,
+		ur:   ur,
+		scm:  smc,
+		sesr: sesr,
+		sms:  sender,
+		tkr:  tkr,
+		tot:  tot,
+		tr:   tr,
 	}
+	go s.sweepTokens()
+	return s
 }
 
 // CreateUser implements domain.UserService.
 func (s *UserServiceImpl) CreateUser(in domain.RegisterUserInput) (result domain.User, err error) {
+	// anyone may register, so registration cannot grant more than the USER role
+	if in.Role == "" {
+		in.Role = domain.UserRoleUser
+	}
+	if in.Role != domain.UserRoleUser {
+		return result, domain.ForbiddenAccessError{}
+	}
 	ctx := context.Background()
 	ctx, err = s.tr.Begin(ctx)
 	if err != nil {
@@ -54,6 +139,10 @@ func (s *UserServiceImpl) CreateUser(in domain.RegisterUserInput) (result domain
 	if result.UserName == in.UserName {
 		return result, errors.New("user with this username already exists: " + result.UserName + "please login or use another user name ")
 	}
+	err = s.checkPassword(ctx, "password", in.Password, domain.User{UserName: in.UserName})
+	if err != nil {
+		return result, err
+	}
 	pass, err := s.apu.EncryptPassword(in.Password)
 	if err != nil {
 		return result, err
@@ -69,6 +158,10 @@ func (s *UserServiceImpl) CreateUser(in domain.RegisterUserInput) (result domain
 	if err != nil {
 		return result, err
 	}
+	err = s.phr.Create(ctx, &domain.PasswordHistory{UserID: result.ID, PasswordHash: pass}, s.pp.HistorySize())
+	if err != nil {
+		return result, err
+	}
 	personnel, err := s.pr.FindByUserID(ctx, result.ID)
 	if err != nil {
 		if !errors.Is(err, pgx.ErrNoRows) {
@@ -96,33 +189,781 @@ func (s *UserServiceImpl) CreateUser(in domain.RegisterUserInput) (result domain
 	return result, nil
 
 }
+
+// Login implements domain.UserService. Every failure is answered with the same domain.UnauthorizedError, whether
+// the user does not exist, the password is wrong or the user name or address is locked out.
 func (s *UserServiceImpl) Login(in domain.LoginInput) (result domain.LoginOutput, err error) {
+	ctx := context.Background()
+	// locked out user names and addresses are refused before the password is looked at
+	locked, err := s.isLoginLocked(ctx, in.UserName, in.IPAddress)
+	if err != nil {
+		return result, err
+	}
+	if locked {
+		return result, domain.UnauthorizedError{}
+	}
 	// check if user exists
-	usr, err := s.ur.FindByUserName(context.Background(), in.UserName)
+	usr, err := s.ur.FindByUserName(ctx, in.UserName)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return result, err
+	}
+	match := false
+	if err == nil && usr.Password != nil {
+		match, _ = s.apu.PasswordCheck(*usr.Password, in.Password)
+	} else {
+		// take as long as a wrong password, so that the time does not tell which user names exist
+		_, _ = s.apu.PasswordCheck(s.loginDummyHash(), in.Password)
+	}
+	if !match {
+		s.recordLoginFailure(ctx, usr, in.UserName, in.IPAddress)
+		return result, domain.UnauthorizedError{}
+	}
+	// the password is at hand only now, so hashes made with an older algorithm or cost are upgraded at login
+	if s.apu.PasswordNeedsRehash(*usr.Password) {
+		hash, err := s.apu.EncryptPassword(in.Password)
+		if err == nil {
+			err = s.ur.UpdatePassword(context.Background(), usr.ID, hash)
+		}
+		if err != nil {
+			slog.Error("failed to rehash password", "user_id", usr.ID, "err", err)
+		}
+	}
+	return s.completeLogin(usr, in.DeviceName, in.UserAgent, in.IPAddress)
+}
+
+// isLoginLocked tells whether the user name or the address must wait before another login
+func (s *UserServiceImpl) isLoginLocked(ctx context.Context, userName, ipAddress string) (result bool, err error) {
+	for _, key := range []struct {
+		scope   domain.LoginAttemptScope
+		subject string
+	}{
+		{domain.LoginAttemptScopeUSER_NAME, userName},
+		{domain.LoginAttemptScopeIP_ADDRESS, ipAddress},
+	} {
+		if key.subject == "" {
+			continue
+		}
+		attempt, err := s.lar.Find(ctx, key.scope, key.subject)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			return false, err
+		}
+		if attempt.LockedUntil != nil && time.Now().Before(*attempt.LockedUntil) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// recordLoginFailure counts a failed login of the user name and from the address. Each failure delays the next
+// attempt twice as long as the one before, and reaching the maximum locks them out and records an audit event.
+// Failures to record are only logged, the login is refused anyway.
+func (s *UserServiceImpl) recordLoginFailure(ctx context.Context, usr domain.User, userName, ipAddress string) {
+	lockout := s.loginLockoutPeriod()
+	maxAttempts := s.cfg.LoginMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultLoginMaxAttempts
+	}
+	maxIPAttempts := s.cfg.LoginIPMaxAttempts
+	if maxIPAttempts <= 0 {
+		maxIPAttempts = defaultLoginIPMaxAttempts
+	}
+	for _, key := range []struct {
+		scope       domain.LoginAttemptScope
+		subject     string
+		maxAttempts int
+		eventType   domain.AuditEventType
+	}{
+		{domain.LoginAttemptScopeUSER_NAME, userName, maxAttempts, domain.AuditEventTypeAccountLocked},
+		{domain.LoginAttemptScopeIP_ADDRESS, ipAddress, maxIPAttempts, domain.AuditEventTypeIPAddressLocked},
+	} {
+		if key.subject == "" {
+			continue
+		}
+		now := time.Now()
+		attempt, err := s.lar.RecordFailure(ctx, key.scope, key.subject, now.Add(-lockout))
+		if err != nil {
+			slog.Error("failed to record failed login", "scope", key.scope, "subject", key.subject, "err", err)
+			continue
+		}
+		if attempt.Failures < key.maxAttempts {
+			delay := min(loginBaseDelay<<min(attempt.Failures-1, 16), loginMaxDelay)
+			err = s.lar.Lock(ctx, key.scope, key.subject, now.Add(delay))
+			if err != nil {
+				slog.Error("failed to delay logins", "scope", key.scope, "subject", key.subject, "err", err)
+			}
+			continue
+		}
+		lockedUntil := now.Add(lockout)
+		err = s.lar.Lock(ctx, key.scope, key.subject, lockedUntil)
+		if err != nil {
+			slog.Error("failed to lock logins", "scope", key.scope, "subject", key.subject, "err", err)
+			continue
+		}
+		// only the failure that reaches the maximum records the lockout
+		if attempt.Failures != key.maxAttempts {
+			continue
+		}
+		event := domain.AuditEvent{
+			Type:      key.eventType,
+			UserName:  userName,
+			IPAddress: ipAddress,
+			Details:   domain.JSONB{"failures": attempt.Failures, "locked_until": lockedUntil},
+		}
+		if key.scope == domain.LoginAttemptScopeUSER_NAME && !usr.ID.IsNil() {
+			event.UserID = &usr.ID
+		}
+		err = s.adr.Create(ctx, &event)
+		if err != nil {
+			slog.Error("failed to record lockout", "scope", key.scope, "subject", key.subject, "err", err)
+		}
+		slog.Warn("logins locked out", "scope", key.scope, "subject", key.subject, "failures", attempt.Failures, "locked_until", lockedUntil)
+	}
+}
+
+// resetLoginFailures forgets the failed logins of the user name once a login succeeds with every factor
+func (s *UserServiceImpl) resetLoginFailures(ctx context.Context, usr domain.User) {
+	err := s.lar.Delete(ctx, domain.LoginAttemptScopeUSER_NAME, usr.UserName)
+	if err != nil {
+		slog.Error("failed to reset failed logins", "user_id", usr.ID, "err", err)
+	}
+}
+
+// loginLockoutPeriod returns how long a lockout lasts, and how long failed logins are remembered
+func (s *UserServiceImpl) loginLockoutPeriod() time.Duration {
+	if s.cfg.LoginLockoutPeriod <= 0 {
+		return defaultLoginLockoutPeriod * time.Minute
+	}
+	return time.Duration(s.cfg.LoginLockoutPeriod) * time.Minute
+}
+
+// loginDummyHash returns a hash of a random password, made with the algorithm of new passwords
+func (s *UserServiceImpl) loginDummyHash() string {
+	s.dummyHashOnce.Do(func() {
+		hash, err := s.apu.EncryptPassword(s.apu.GenerateUniqueToken())
+		if err != nil {
+			slog.Error("failed to hash dummy password", "err", err)
+		}
+		s.dummyHash = hash
+	})
+	return s.dummyHash
+}
+
+// RequestOTP implements domain.UserService.
+func (s *UserServiceImpl) RequestOTP(in domain.RequestOTPInput) (result domain.RequestOTPOutput, err error) {
+	ctx := context.Background()
+	cooldown := time.Duration(s.cfg.OTPResendCooldown) * time.Second
+	if cooldown <= 0 {
+		cooldown = defaultOTPResendCooldown * time.Second
+	}
+	expiry := time.Duration(s.cfg.OTPExpiryPeriod) * time.Minute
+	if expiry <= 0 {
+		expiry = defaultOTPExpiryPeriod * time.Minute
+	}
+	length := s.cfg.OTPLength
+	if length <= 0 {
+		length = defaultOTPLength
+	}
+
+	previous, err := s.otr.FindByUserName(ctx, in.UserName)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return result, err
+	}
+	if err == nil && time.Since(previous.SentAt) < cooldown {
+		return result, domain.UserError{Code: domain.ErrorCodeOTP_COOLDOWN, Message: domain.MessageOTPCOOLDOWN}
+	}
+	code := s.apu.GenerateOTP(length)
+	if code == "" {
+		return result, errors.New("failed to generate a one time password")
+	}
+	hash, err := s.apu.EncryptPassword(code)
+	if err != nil {
+		return result, err
+	}
+	// the code is saved for unknown numbers as well, so the cooldown cannot tell them apart
+	otp := domain.OTP{
+		UserName:  in.UserName,
+		CodeHash:  hash,
+		ExpiresAt: time.Now().Add(expiry),
+	}
+	err = s.otr.Save(ctx, &otp)
+	if err != nil {
+		return result, err
+	}
+	_, err = s.ur.FindByUserName(ctx, in.UserName)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return result, err
+	}
+	if err == nil {
+		appName := s.cfg.AppName
+		if appName == "" {
+			appName = "ChatApp"
+		}
+		message := fmt.Sprintf("%s is your %s code. It expires in %d minutes, do not share it with anyone.", code, appName, int(expiry.Minutes()))
+		err = s.sms.Send(ctx, in.UserName, message)
+		if err != nil {
+			// let the user ask again right away
+			_ = s.otr.Delete(ctx, otp.ID)
+			return result, err
+		}
+	}
+	result = domain.RequestOTPOutput{
+		ExpiresIn: int64(expiry.Seconds()),
+		ResendIn:  int64(cooldown.Seconds()),
+	}
+	return result, nil
+}
+
+// VerifyOTP implements domain.UserService.
+func (s *UserServiceImpl) VerifyOTP(in domain.VerifyOTPInput) (result domain.LoginOutput, err error) {
+	ctx := context.Background()
+	maxAttempts := s.cfg.OTPMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultOTPMaxAttempts
+	}
+	otp, err := s.otr.CountAttempt(ctx, in.UserName, maxAttempts)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, domain.UserError{Code: domain.ErrorCodeINVALID_OTP, Message: domain.MessageOTPINVALID}
+		}
+		return result, err
+	}
+	if time.Now().After(otp.ExpiresAt) {
+		return result, domain.UserError{Code: domain.ErrorCodeINVALID_OTP, Message: domain.MessageOTPINVALID}
+	}
+	match, _ := s.apu.PasswordCheck(otp.CodeHash, in.Code)
+	if !match {
+		if otp.Attempts >= maxAttempts {
+			return result, domain.UserError{Code: domain.ErrorCodeINVALID_OTP, Message: domain.MessageOTPATTEMPTS}
+		}
+		return result, domain.UserError{Code: domain.ErrorCodeINVALID_OTP, Message: domain.MessageOTPINVALID}
+	}
+	// a code works once
+	err = s.otr.Delete(ctx, otp.ID)
+	if err != nil {
+		return result, err
+	}
+	usr, err := s.ur.FindByUserName(ctx, in.UserName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, domain.UserError{Code: domain.ErrorCodeINVALID_OTP, Message: domain.MessageOTPINVALID}
+		}
+		return result, err
+	}
+	err = s.ur.MarkMobileVerified(ctx, usr.ID)
+	if err != nil {
+		return result, err
+	}
+	return s.completeLogin(usr, in.DeviceName, in.UserAgent, in.IPAddress)
+}
+
+// completeLogin logs in a user who proved the first factor. Users with a confirmed authenticator get a login
+// challenge instead, it is exchanged for the tokens with VerifyMFA.
+func (s *UserServiceImpl) completeLogin(usr domain.User, name, userAgent, ipAddress string) (result domain.LoginOutput, err error) {
+	ctx := context.Background()
+	authenticator, err := s.mfr.FindTOTP(ctx, usr.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return result, err
+	}
+	if err != nil || authenticator.ConfirmedAt == nil {
+		s.resetLoginFailures(ctx, usr)
+		return s.startSession(usr, name, userAgent, ipAddress, false)
+	}
+	expiry := time.Duration(s.cfg.MFAChallengeExpiryPeriod) * time.Minute
+	if expiry <= 0 {
+		expiry = defaultMFAChallengeExpiryPeriod * time.Minute
+	}
+	// challenge tokens are opaque like refresh tokens, so they cannot be mistaken for an access token
+	token, hash, err := s.scm.GenerateRefreshToken()
+	if err != nil {
+		return result, err
+	}
+	challenge := domain.MFAChallenge{
+		UserID:     usr.ID,
+		TokenHash:  hash,
+		DeviceName: name,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		ExpiresAt:  time.Now().Add(expiry),
+	}
+	err = s.mfr.CreateChallenge(ctx, &challenge)
+	if err != nil {
+		return result, err
+	}
+	result = domain.LoginOutput{
+		MFARequired:        true,
+		ChallengeToken:     token,
+		ChallengeExpiresIn: int64(expiry.Seconds()),
+	}
+	return result, nil
+}
+
+// VerifyMFA implements domain.UserService. Every failure is answered with the same domain.UnauthorizedError, and a
+// challenge stops working once it took MFA_MAX_ATTEMPTS codes. Wrong codes count as failed logins, so that logging in
+// again for a new challenge does not give more guesses than the lockout allows.
+func (s *UserServiceImpl) VerifyMFA(in domain.VerifyMFAInput) (result domain.LoginOutput, err error) {
+	ctx := context.Background()
+	maxAttempts := s.cfg.MFAMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMFAMaxAttempts
+	}
+	challenge, err := s.mfr.CountChallengeAttempt(ctx, s.scm.HashRefreshToken(in.ChallengeToken), maxAttempts)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, domain.UnauthorizedError{}
+		}
+		return result, err
+	}
+	usr, err := s.ur.FindByID(ctx, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, domain.UnauthorizedError{}
+		}
+		return result, err
+	}
+	locked, err := s.isLoginLocked(ctx, usr.UserName, in.IPAddress)
+	if err != nil {
+		return result, err
+	}
+	if locked {
+		return result, domain.UnauthorizedError{}
+	}
+	ok, err := s.checkSecondFactor(ctx, challenge.UserID, in.Code)
+	if err != nil {
+		return result, err
+	}
+	if !ok {
+		s.recordLoginFailure(ctx, usr, usr.UserName, in.IPAddress)
+		return result, domain.UnauthorizedError{}
+	}
+	// a challenge works once
+	err = s.mfr.DeleteChallenge(ctx, challenge.ID)
+	if err != nil {
+		return result, err
+	}
+	s.resetLoginFailures(ctx, usr)
+	return s.startSession(usr, challenge.DeviceName, in.UserAgent, in.IPAddress, true)
+}
+
+// checkSecondFactor tells whether the code is a current code of the confirmed authenticator of the user or one of
+// the unused recovery codes, and uses it up
+func (s *UserServiceImpl) checkSecondFactor(ctx context.Context, userID uuid.UUID, code string) (result bool, err error) {
+	authenticator, err := s.mfr.FindTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	if authenticator.ConfirmedAt == nil {
+		return false, nil
+	}
+	secret, err := s.tot.Open(authenticator.Secret)
+	if err != nil {
+		return false, err
+	}
+	if step, ok := s.tot.Validate(secret, code, time.Now()); ok {
+		err = s.mfr.UseTOTPStep(ctx, userID, step)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				// the code was replayed
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+	// recovery codes are long and random, so a plain hash is enough, as for refresh tokens
+	err = s.mfr.UseRecoveryCode(ctx, userID, s.scm.HashRefreshToken(totp.NormalizeRecoveryCode(code)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// EnrollTOTP implements domain.UserService.
+func (s *UserServiceImpl) EnrollTOTP(userID uuid.UUID) (result domain.EnrollTOTPOutput, err error) {
+	ctx := context.Background()
+	usr, err := s.ur.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, domain.UnauthorizedError{Code: domain.ErrorCodeUNAUTHORIZED, Message: domain.MessageUNAUTHORIZEDACCESS}
+		}
+		return result, err
+	}
+	previous, err := s.mfr.FindTOTP(ctx, userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return result, err
+	}
+	if err == nil && previous.ConfirmedAt != nil {
+		return result, domain.UserError{Code: domain.ErrorCodeTOTP_ENABLED, Message: domain.MessageTOTPENABLED}
+	}
+	secret, err := s.tot.GenerateSecret()
+	if err != nil {
+		return result, err
+	}
+	sealed, err := s.tot.Seal(secret)
+	if err != nil {
+		return result, err
+	}
+	err = s.mfr.SaveTOTP(ctx, &domain.TOTPAuthenticator{UserID: userID, Secret: sealed})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// a concurrent enrollment was confirmed in between
+			return result, domain.UserError{Code: domain.ErrorCodeTOTP_ENABLED, Message: domain.MessageTOTPENABLED}
+		}
+		return result, err
+	}
+	result = domain.EnrollTOTPOutput{
+		Secret:          secret,
+		ProvisioningURI: s.tot.ProvisioningURI(usr.UserName, secret),
+	}
+	return result, nil
+}
+
+// ConfirmTOTP implements domain.UserService.
+func (s *UserServiceImpl) ConfirmTOTP(userID uuid.UUID, in domain.TOTPCodeInput) (result domain.RecoveryCodesOutput, err error) {
+	ctx := context.Background()
+	authenticator, err := s.mfr.FindTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, domain.UserError{Code: domain.ErrorCodeTOTP_NOT_ENROLLED, Message: domain.MessageTOTPNOTENROLLED}
+		}
+		return result, err
+	}
+	if authenticator.ConfirmedAt != nil {
+		return result, domain.UserError{Code: domain.ErrorCodeTOTP_ENABLED, Message: domain.MessageTOTPENABLED}
+	}
+	secret, err := s.tot.Open(authenticator.Secret)
+	if err != nil {
+		return result, err
+	}
+	step, ok := s.tot.Validate(secret, in.Code, time.Now())
+	if !ok {
+		return result, domain.UserError{Code: domain.ErrorCodeINVALID_TOTP, Message: domain.MessageTOTPINVALID}
+	}
+	codes, err := s.tot.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return result, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = s.scm.HashRefreshToken(totp.NormalizeRecoveryCode(code))
+	}
+
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+	err = s.mfr.ConfirmTOTP(ctx, userID, step)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// a concurrent confirmation won
+			err = domain.UserError{Code: domain.ErrorCodeTOTP_ENABLED, Message: domain.MessageTOTPENABLED}
+		}
+		return result, err
+	}
+	err = s.mfr.ReplaceRecoveryCodes(ctx, userID, hashes)
+	if err != nil {
+		return result, err
+	}
+	err = s.tr.Commit(ctx)
+	if err != nil {
+		return result, err
+	}
+	result = domain.RecoveryCodesOutput{RecoveryCodes: codes}
+	return result, nil
+}
+
+// DisableTOTP implements domain.UserService.
+func (s *UserServiceImpl) DisableTOTP(userID uuid.UUID, in domain.TOTPCodeInput) (err error) {
+	ctx := context.Background()
+	// a stolen access token alone cannot turn the second factor off
+	ok, err := s.checkSecondFactor(ctx, userID, in.Code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domain.UserError{Code: domain.ErrorCodeINVALID_TOTP, Message: domain.MessageTOTPINVALID}
+	}
+	return s.mfr.DeleteTOTP(ctx, userID)
+}
+
+// startSession logs the user in on a device, the session lasts as long as its refresh tokens. mfa tells whether the
+// login gave the code of an authenticator.
+func (s *UserServiceImpl) startSession(usr domain.User, name, userAgent, ipAddress string, mfa bool) (result domain.LoginOutput, err error) {
+	ctx, err := s.tr.Begin(context.Background())
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+	session := domain.Session{
+		UserID:     usr.ID,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		DeviceName: strings.TrimSpace(name),
+		ExpiresAt:  time.Now().Add(security.RefreshExpiryPeriod(s.cfg)),
+		MFA:        mfa,
+	}
+	if session.DeviceName == "" {
+		session.DeviceName = deviceName(userAgent)
+	}
+	err = s.sesr.Create(ctx, &session)
+	if err != nil {
+		return result, err
+	}
+	// generate tokens
+	result, err = s.issueTokens(ctx, usr, session.ID, session.ExpiresAt, session.MFA)
+	if err != nil {
+		return result, err
+	}
+	err = s.tr.Commit(ctx)
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// RefreshToken implements domain.UserService.
+func (s *UserServiceImpl) RefreshToken(in domain.RefreshTokenInput) (result domain.LoginOutput, err error) {
+	ctx := context.Background()
+	token, err := s.tkr.FindRefreshTokenByHash(ctx, s.scm.HashRefreshToken(in.RefreshToken))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, domain.UnauthorizedError{}
+		}
+		return result, err
+	}
+	if token.RevokedAt != nil {
+		return result, domain.UnauthorizedError{}
+	}
+	if token.UsedAt != nil {
+		// a rotated token only comes back when it was copied, so sign out both the thief and the owner
+		slog.Warn("refresh token reused, revoking its session", "user_id", token.UserID, "session_id", token.SessionID)
+		err = s.revokeSession(ctx, token.SessionID)
+		if err != nil {
+			return result, err
+		}
+		return result, domain.UnauthorizedError{}
+	}
+	if time.Now().After(token.ExpiresAt) {
+		return result, domain.UnauthorizedError{}
+	}
+	// the role may have changed since the login
+	usr, err := s.ur.FindByID(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, domain.UnauthorizedError{}
+		}
+		return result, err
+	}
+	// the second factor given at login lasts as long as the session
+	session, err := s.sesr.FindByID(ctx, token.SessionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, domain.UnauthorizedError{}
+		}
+		return result, err
+	}
+
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+	err = s.tkr.MarkRefreshTokenUsed(ctx, token.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// a concurrent refresh with the same token won
+			err = domain.UnauthorizedError{}
+		}
+		return result, err
+	}
+	expiresAt := time.Now().Add(security.RefreshExpiryPeriod(s.cfg))
+	err = s.sesr.Touch(ctx, token.SessionID, in.IPAddress, in.UserAgent, expiresAt)
+	if err != nil {
+		return result, err
+	}
+	result, err = s.issueTokens(ctx, usr, token.SessionID, expiresAt, session.MFA)
+	if err != nil {
+		return result, err
+	}
+	err = s.tr.Commit(ctx)
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// Logout implements domain.UserService.
+func (s *UserServiceImpl) Logout(tokenID uuid.UUID, expiresAt time.Time) (err error) {
+	ctx := context.Background()
+	token, err := s.tkr.FindRefreshTokenByAccessTokenID(ctx, tokenID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	if err == nil {
+		err = s.revokeSession(ctx, token.SessionID)
+		if err != nil {
+			return err
+		}
+	}
+	return s.tkr.RevokeAccessToken(ctx, tokenID, expiresAt)
+}
+
+// FindSessions implements domain.UserService.
+func (s *UserServiceImpl) FindSessions(userID, tokenID uuid.UUID) (result []domain.Session, err error) {
+	ctx := context.Background()
+	result, err = s.sesr.FindActiveByUserID(ctx, userID)
 	if err != nil {
 		return result, err
 	}
-	if usr.UserName != "" && usr.ID.IsNil() {
-		return result, errors.New("user with this username:" + in.UserName + " does not exist")
+	token, err := s.tkr.FindRefreshTokenByAccessTokenID(ctx, tokenID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, nil
+		}
+		return result, err
+	}
+	for i := range result {
+		result[i].Current = result[i].ID == token.SessionID
+	}
+	return result, nil
+}
+
+// DeleteSession implements domain.UserService.
+func (s *UserServiceImpl) DeleteSession(userID, id uuid.UUID) (err error) {
+	ctx := context.Background()
+	session, err := s.sesr.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.DataNotFoundError{}
+		}
+		return err
+	}
+	// sessions of other users are as good as missing
+	if session.UserID != userID || session.RevokedAt != nil {
+		return domain.DataNotFoundError{}
+	}
+	return s.revokeSession(ctx, id)
+}
+
+// revokeSession ends a session, revokes its refresh tokens and the access tokens issued with them and closes the
+// websockets opened with them
+func (s *UserServiceImpl) revokeSession(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+	err = s.sesr.Revoke(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = s.tkr.RevokeSessionTokens(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = s.tr.Commit(ctx)
+	if err != nil {
+		return err
 	}
-	match, err := s.apu.PasswordCheck(*usr.Password, in.Password)
-	if err != nil || !match {
-		return result, errors.New("wrong password")
+	// websockets are only authenticated when they connect, so they are closed rather than left receiving events
+	err = s.sc.CloseSession(ctx, id)
+	if err != nil {
+		slog.Error("failed to close the connections of a session", "session_id", id, "err", err)
 	}
-	// generate token
+	return nil
+}
+
+// IsTokenRevoked implements domain.UserService.
+func (s *UserServiceImpl) IsTokenRevoked(tokenID uuid.UUID) (result bool, err error) {
+	return s.tkr.IsAccessTokenRevoked(context.Background(), tokenID)
+}
+
+// issueTokens generates an access token and a refresh token of the session for the user, mfa marks the access token
+// of a login that gave the code of an authenticator
+func (s *UserServiceImpl) issueTokens(ctx context.Context, usr domain.User, sessionID uuid.UUID, expiresAt time.Time, mfa bool) (result domain.LoginOutput, err error) {
 	ti := security.TokenMetadata{
-		UserID: usr.ID.String(),
-		Role:   usr.Role,
+		UserID:    usr.ID.String(),
+		Role:      usr.Role,
+		MFA:       mfa,
+		SessionID: sessionID.String(),
 	}
-	token, err := s.scm.GenerateAuthToken(ti)
+	access, err := s.scm.GenerateAuthToken(ti)
+	if err != nil {
+		return result, err
+	}
+	refresh, hash, err := s.scm.GenerateRefreshToken()
+	if err != nil {
+		return result, err
+	}
+	err = s.tkr.CreateRefreshToken(ctx, &domain.RefreshToken{
+		UserID:          usr.ID,
+		SessionID:       sessionID,
+		TokenHash:       hash,
+		AccessTokenID:   access.ID,
+		AccessExpiresAt: access.ExpiresAt,
+		ExpiresAt:       expiresAt,
+	})
 	if err != nil {
 		return result, err
 	}
 	result = domain.LoginOutput{
-		Token:     token,
-		ExpiresIn: int64(s.cfg.AuthExpiryPeriod),
+		Token:            access.Token,
+		ExpiresIn:        int64(security.AccessExpiryPeriod(s.cfg).Seconds()),
+		RefreshToken:     refresh,
+		RefreshExpiresIn: int64(time.Until(expiresAt).Round(time.Second).Seconds()),
+	}
+	return result, nil
+}
+
+// sweepTokens periodically removes the sessions, refresh tokens, denylist entries, one time passwords, reset tokens,
+// failed logins and login challenges that expired
+func (s *UserServiceImpl) sweepTokens() {
+	ticker := time.NewTicker(tokenSweepInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		ctx := context.Background()
+		err := s.tkr.DeleteExpired(ctx, time.Now())
+		if err != nil {
+			slog.Error("failed to remove expired tokens", "err", err)
+		}
+		err = s.sesr.DeleteExpired(ctx, time.Now())
+		if err != nil {
+			slog.Error("failed to remove expired sessions", "err", err)
+		}
+		err = s.otr.DeleteExpired(ctx, time.Now())
+		if err != nil {
+			slog.Error("failed to remove expired one time passwords", "err", err)
+		}
+		err = s.prt.DeleteExpired(ctx, time.Now())
+		if err != nil {
+			slog.Error("failed to remove expired password reset tokens", "err", err)
+		}
+		err = s.lar.DeleteExpired(ctx, time.Now().Add(-s.loginLockoutPeriod()))
+		if err != nil {
+			slog.Error("failed to remove expired failed logins", "err", err)
+		}
+		err = s.mfr.DeleteExpiredChallenges(ctx, time.Now())
+		if err != nil {
+			slog.Error("failed to remove expired login challenges", "err", err)
+		}
 	}
-	return result, err
 }
 
 // DeleteUser implements domain.UserService.
@@ -142,11 +983,318 @@ func (s *UserServiceImpl) FindByUserName(username string) (result domain.User, e
 
 // UpdateUser implements domain.UserService.
 func (s *UserServiceImpl) UpdateUser(id uuid.UUID, in domain.UpdateUserInput) (result domain.User, err error) {
+	ctx := context.Background()
 	// find user by id
-	result = domain.User{
-		UserName: in.UserName,
-		Password: &in.Password,
-		Role:     string(in.Role),
+	result, err = s.ur.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, domain.DataNotFoundError{}
+		}
+		return result, err
+	}
+	if in.UserName != "" {
+		result.UserName = in.UserName
+	}
+	if in.Role != "" {
+		result.Role = string(in.Role)
+	}
+	if in.Password != "" {
+		err = s.checkPassword(ctx, "password", in.Password, result)
+		if err != nil {
+			return result, err
+		}
+		pass, err := s.apu.EncryptPassword(in.Password)
+		if err != nil {
+			return result, err
+		}
+		result.Password = &pass
+	}
+	err = s.updateUser(ctx, &result, in.Password != "")
+	if err != nil {
+		return result, err
+	}
+	if in.Password != "" {
+		return result, s.revokeSessions(ctx, id, uuid.Nil)
+	}
+	return result, nil
+}
+
+// updateUser stores the user, and records a new password in the password history in the same transaction
+func (s *UserServiceImpl) updateUser(ctx context.Context, usr *domain.User, passwordChanged bool) (err error) {
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+	err = s.ur.UpdateUser(ctx, usr)
+	if err != nil {
+		return err
+	}
+	if passwordChanged {
+		err = s.phr.Create(ctx, &domain.PasswordHistory{UserID: usr.ID, PasswordHash: *usr.Password}, s.pp.HistorySize())
+		if err != nil {
+			return err
+		}
+	}
+	return s.tr.Commit(ctx)
+}
+
+// ChangePassword implements domain.UserService.
+func (s *UserServiceImpl) ChangePassword(userID, tokenID uuid.UUID, in domain.ChangePasswordInput) (err error) {
+	ctx := context.Background()
+	usr, err := s.ur.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.UnauthorizedError{Code: domain.ErrorCodeUNAUTHORIZED, Message: domain.MessageUNAUTHORIZEDACCESS}
+		}
+		return err
+	}
+	// guessing the old password with a stolen access token is locked out like guessing it at login
+	locked, err := s.isLoginLocked(ctx, usr.UserName, in.IPAddress)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return domain.UnauthorizedError{}
+	}
+	// users who only ever logged in with one time passwords have no password to check, they reset it instead
+	if usr.Password == nil {
+		return domain.UserError{Code: domain.ErrorCodeINVALID_PASSWORD, Message: domain.MessagePASSWORDMISMATCH}
+	}
+	match, _ := s.apu.PasswordCheck(*usr.Password, in.OldPassword)
+	if !match {
+		s.recordLoginFailure(ctx, usr, usr.UserName, in.IPAddress)
+		return domain.UserError{Code: domain.ErrorCodeINVALID_PASSWORD, Message: domain.MessagePASSWORDMISMATCH}
+	}
+	err = s.checkPassword(ctx, "new_password", in.NewPassword, usr)
+	if err != nil {
+		return err
+	}
+	err = s.savePassword(ctx, usr.ID, in.NewPassword)
+	if err != nil {
+		return err
+	}
+	// the device that changed the password stays logged in
+	current := uuid.Nil
+	token, err := s.tkr.FindRefreshTokenByAccessTokenID(ctx, tokenID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	if err == nil {
+		current = token.SessionID
+	}
+	return s.revokeSessions(ctx, usr.ID, current)
+}
+
+// ForgotPassword implements domain.UserService.
+func (s *UserServiceImpl) ForgotPassword(in domain.ForgotPasswordInput) (err error) {
+	ctx := context.Background()
+	usr, err := s.ur.FindByUserName(ctx, in.UserName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	expiry := time.Duration(s.cfg.PasswordResetExpiryPeriod) * time.Minute
+	if expiry <= 0 {
+		expiry = defaultPasswordResetExpiryPeriod * time.Minute
+	}
+	// reset tokens are long and random like refresh tokens, so they are made and hashed the same way
+	token, hash, err := s.scm.GenerateRefreshToken()
+	if err != nil {
+		return err
+	}
+	reset := domain.PasswordResetToken{
+		UserID:    usr.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(expiry),
+	}
+	err = s.prt.Save(ctx, &reset)
+	if err != nil {
+		return err
+	}
+	appName := s.cfg.AppName
+	if appName == "" {
+		appName = "ChatApp"
+	}
+	code := token
+	if s.cfg.PasswordResetUrl != "" {
+		code = s.cfg.PasswordResetUrl + "?token=" + url.QueryEscape(token)
+	}
+	message := fmt.Sprintf("Reset your %s password with %s, it expires in %d minutes. Ignore this message if you did not ask for it.", appName, code, int(expiry.Minutes()))
+	return s.ntf.Notify(ctx, usr.UserName, appName+" password reset", message)
+}
+
+// ResetPassword implements domain.UserService.
+func (s *UserServiceImpl) ResetPassword(in domain.ResetPasswordInput) (err error) {
+	ctx, err := s.tr.Begin(context.Background())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+	reset, err := s.prt.Consume(ctx, s.scm.HashRefreshToken(in.Token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.UserError{Code: domain.ErrorCodeINVALID_RESET_TOKEN, Message: domain.MessageRESETTOKENINVALID}
+		}
+		return err
+	}
+	usr, err := s.ur.FindByID(ctx, reset.UserID)
+	if err != nil {
+		return err
+	}
+	// a refused password rolls the use of the token back, so it can be tried again
+	err = s.checkPassword(ctx, "password", in.Password, usr)
+	if err != nil {
+		return err
+	}
+	err = s.setPassword(ctx, reset.UserID, in.Password)
+	if err != nil {
+		return err
+	}
+	err = s.tr.Commit(ctx)
+	if err != nil {
+		return err
+	}
+	// whoever knew the old password is signed out everywhere
+	return s.revokeSessions(context.Background(), reset.UserID, uuid.Nil)
+}
+
+// savePassword sets the new password of a user in a transaction of its own
+func (s *UserServiceImpl) savePassword(ctx context.Context, id uuid.UUID, password string) (err error) {
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+	err = s.setPassword(ctx, id, password)
+	if err != nil {
+		return err
+	}
+	return s.tr.Commit(ctx)
+}
+
+// setPassword hashes and stores the new password of a user, and records it in the password history. It is called in
+// a transaction, so the password is never changed without being recorded.
+func (s *UserServiceImpl) setPassword(ctx context.Context, id uuid.UUID, password string) (err error) {
+	hash, err := s.apu.EncryptPassword(password)
+	if err != nil {
+		return err
+	}
+	err = s.ur.UpdatePassword(ctx, id, hash)
+	if err != nil {
+		return err
+	}
+	return s.phr.Create(ctx, &domain.PasswordHistory{UserID: id, PasswordHash: hash}, s.pp.HistorySize())
+}
+
+// checkPassword holds a new password of the user to the password policy, it fails with a domain.ValidationError
+// listing what the field breaks
+func (s *UserServiceImpl) checkPassword(ctx context.Context, field, password string, usr domain.User) (err error) {
+	problems := s.pp.Check(password, usr.UserName)
+	if n := s.pp.HistorySize(); n > 0 && !usr.ID.IsNil() {
+		previous, err := s.phr.FindLatest(ctx, usr.ID, n)
+		if err != nil {
+			return err
+		}
+		hashes := make([]string, 0, len(previous)+1)
+		// the current password counts even when it predates the history
+		if usr.Password != nil {
+			hashes = append(hashes, *usr.Password)
+		}
+		for _, p := range previous {
+			if !slices.Contains(hashes, p.PasswordHash) {
+				hashes = append(hashes, p.PasswordHash)
+			}
+		}
+		for _, hash := range hashes {
+			if match, _ := s.apu.PasswordCheck(hash, password); match {
+				problems = append(problems, fmt.Sprintf("must differ from your last %d passwords", n))
+				break
+			}
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	fields := make([]string, len(problems))
+	for i, p := range problems {
+		fields[i] = field + " " + p
+	}
+	return domain.ValidationError{
+		Code:    domain.ErrorCodeVALIDATION_ERROR,
+		Message: domain.MessageVALIDATIONFAILED,
+		Fields:  fields,
+	}
+}
+
+// revokeSessions ends every active session of a user but keep, pass uuid.Nil to end them all
+func (s *UserServiceImpl) revokeSessions(ctx context.Context, userID, keep uuid.UUID) (err error) {
+	sessions, err := s.sesr.FindActiveByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.ID == keep {
+			continue
+		}
+		err = s.revokeSession(ctx, session.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deviceName describes the browser and operating system of a user agent, such as Chrome on Windows
+func deviceName(userAgent string) string {
+	browser := ""
+	for _, b := range []struct{ token, name string }{
+		// the order matters, most browsers mention the ones they derive from
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"CriOS/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"okhttp", "Android app"},
+		{"CFNetwork", "iOS app"},
+	} {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+	platform := ""
+	for _, o := range []struct{ token, name string }{
+		{"iPhone", "iPhone"},
+		{"iPad", "iPad"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(userAgent, o.token) {
+			platform = o.name
+			break
+		}
+	}
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform
+	default:
+		return "Unknown device"
 	}
-	return result, s.ur.UpdateUser(context.Background(), &result)
 }